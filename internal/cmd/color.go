@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"os"
+
+	"db-copy/internal/db"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var noColor bool
+
+// colorEnabled reports whether console output may be colored: only on a
+// terminal, and never with --no-color or a non-empty NO_COLOR variable
+// (https://no-color.org)
+func colorEnabled(f *os.File) bool {
+	if noColor || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// setupOutput configures colored output and validates --output before any command runs
+func setupOutput(cmd *cobra.Command, args []string) error {
+	// The SQL log is the colored console output, and it goes to stderr
+	db.SetColor(colorEnabled(os.Stderr))
+	return validateOutput(cmd, args)
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"db-copy/internal/db"
+)
+
+// fileConfig is the layout of the --config file
+type fileConfig struct {
+	Tables map[string]tableConfig `json:"tables"`
+}
+
+// tableConfig holds the settings for a single source table
+type tableConfig struct {
+	BatchSize int `json:"batch_size"`
+}
+
+// loadConfig reads a JSON config file
+func loadConfig(path string) (*fileConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var cfg fileConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	for table, tc := range cfg.Tables {
+		if tc.BatchSize < 0 {
+			return nil, fmt.Errorf("invalid batch_size %d for table %s in config file", tc.BatchSize, table)
+		}
+	}
+	return &cfg, nil
+}
+
+// applyConfig sets the per-table settings of the --config file, if any, on
+// copier
+func applyConfig(copier *db.Copier) error {
+	if configPath == "" {
+		return nil
+	}
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+	copier.TableBatchSizes = cfg.tableBatchSizes()
+	return nil
+}
+
+// tableBatchSizes returns the per-table batch size overrides
+func (cfg *fileConfig) tableBatchSizes() map[string]int {
+	sizes := make(map[string]int)
+	for table, tc := range cfg.Tables {
+		if tc.BatchSize > 0 {
+			sizes[table] = tc.BatchSize
+		}
+	}
+	return sizes
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"db-copy/internal/db"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	sourceParams    db.ConnParams
+	destParams      db.ConnParams
+	sourceDSNParams []string
+	destDSNParams   []string
+)
+
+// addConnFlags adds --source and --dest together with the discrete connection
+// detail flags that can replace them for PostgreSQL
+func addConnFlags(cmd *cobra.Command, sourceUsage, destUsage string) {
+	flags := cmd.Flags()
+	flags.StringVarP(&sourceDB, "source", "s", "", sourceUsage)
+	flags.StringVarP(&destDB, "dest", "d", "", destUsage)
+	for _, side := range []struct {
+		name   string
+		params *db.ConnParams
+	}{{"source", &sourceParams}, {"dest", &destParams}} {
+		flags.StringVar(&side.params.Host, side.name+"-host", "", "PostgreSQL host, used instead of a --"+side.name+" URL")
+		flags.IntVar(&side.params.Port, side.name+"-port", 0, "PostgreSQL port (default 5432)")
+		flags.StringVar(&side.params.User, side.name+"-user", "", "PostgreSQL user")
+		flags.StringVar(&side.params.DBName, side.name+"-dbname", "", "PostgreSQL database name (default: the --"+side.name+" value)")
+		flags.StringVar(&side.params.Password, side.name+"-password", "", "PostgreSQL password; may contain any character")
+	}
+	flags.StringArrayVar(&sourceDSNParams, "source-param", nil, "Driver parameter added to the source connection string as key=value, e.g. application_name=dbcopy (repeatable)")
+	flags.StringArrayVar(&destDSNParams, "dest-param", nil, "Driver parameter added to the destination connection string as key=value (repeatable)")
+}
+
+// setConnFlags passes the connection detail flags to a copier
+func setConnFlags(copier *db.Copier) error {
+	copier.SourceParams = sourceParams
+	copier.DestParams = destParams
+
+	var err error
+	if copier.SourceDSNParams, err = parseDSNParams(sourceDSNParams, "--source-param"); err != nil {
+		return err
+	}
+	copier.DestDSNParams, err = parseDSNParams(destDSNParams, "--dest-param")
+	return err
+}
+
+// parseDSNParams parses the key=value pairs of a --source-param or --dest-param flag
+func parseDSNParams(values []string, flag string) (map[string]string, error) {
+	params := make(map[string]string)
+	for _, value := range values {
+		key, param, ok := strings.Cut(value, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid %s %q: expected key=value", flag, value)
+		}
+		params[key] = param
+	}
+	return params, nil
+}
+
+// checkConnFlags verifies that each side has a connection string or connection details
+func checkConnFlags() error {
+	if sourceDB == "" && !sourceParams.IsSet() {
+		return fmt.Errorf("--source or --source-host/--source-dbname is required")
+	}
+	if destDB == "" && !destParams.IsSet() {
+		return fmt.Errorf("--dest or --dest-host/--dest-dbname is required")
+	}
+	return nil
+}
+
+// needsPassword reports whether a database will be connected to without a password
+func needsPassword(dsn string, params db.ConnParams) bool {
+	if params.IsSet() && !strings.Contains(dsn, "://") {
+		return params.Password == ""
+	}
+	return db.NeedsPassword(dsn)
+}
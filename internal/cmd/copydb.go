@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"db-copy/internal/db"
+
+	"github.com/spf13/cobra"
+)
+
+// copyDBCmd represents the copy-db command
+var copyDBCmd = &cobra.Command{
+	Use:   "copy-db",
+	Short: "Clone a whole database: schema, data and sequences",
+	Long: `Copies every table of the source database in foreign key dependency order,
+creating tables with their indexes and constraints, copying the rows and syncing
+sequences (PostgreSQL to PostgreSQL), then verifies the row count of every table.`,
+	RunE: runCopyDB,
+}
+
+// copyDBResult is the JSON result of the copy-db command
+type copyDBResult struct {
+	commandResult
+	db.Stats
+	Verification []db.TableCount `json:"verification"`
+}
+
+func runCopyDB(cmd *cobra.Command, args []string) (err error) {
+	if err := reverseFlags(cmd); err != nil {
+		return err
+	}
+	if err := checkConnFlags(); err != nil {
+		return err
+	}
+	if batchSize < 1 {
+		return fmt.Errorf("--batch-size must be at least 1")
+	}
+	if err := checkUniqueViolation(); err != nil {
+		return err
+	}
+	if err := checkInsertMethod(); err != nil {
+		return err
+	}
+	if err := checkAdaptiveBatch(); err != nil {
+		return err
+	}
+	if err := checkFetchSize(); err != nil {
+		return err
+	}
+	if err := checkReadConsistency(); err != nil {
+		return err
+	}
+	schemas, err := parseSchemaMap(sourceSchemas, schemaMap)
+	if err != nil {
+		return err
+	}
+
+	copier := db.NewCopier(sourceDB, destDB, "", batchSize)
+	defer startReport("copy-db", copier)(&err)
+	defer startManifest(copier)(&err)
+	copier.SourceSchemas = sourceSchemas
+	copier.SchemaMap = schemas
+	if err := setConnFlags(copier); err != nil {
+		return err
+	}
+	if err := applyConfig(copier); err != nil {
+		return err
+	}
+	copier.CopySequences = true
+	copier.Workers = workers
+	copier.NoTransaction = noTx
+	copier.NoTriggers = noTriggers
+	copier.Paginate = paginate
+	copier.FetchSize = fetchSize
+	copier.WireCompress = wireCompress
+	copier.DestReadonlyCheck = readonlyCheck
+	copier.ProgressInterval = progressEvery
+	copier.DDLOut = ddlOut
+	if copier.TypeRules, err = readTypeMap(); err != nil {
+		return err
+	}
+	copier.AdaptiveBatch = adaptiveBatch
+	copier.MinBatch = minBatch
+	copier.MaxBatch = maxBatch
+	copier.BatchLatency = batchLatency
+	copier.OnUniqueViolation = onViolation
+	copier.InsertMethod = insertMethod
+	copier.CopyNull = copyNull
+	copier.ReadConsistency = consistency
+
+	var counts []db.TableCount
+	if jsonOutput() {
+		copier.Output = io.Discard
+		start := time.Now()
+		defer func() {
+			result := copyDBResult{
+				commandResult: newCommandResult("copy-db", start, err),
+				Stats:         copier.Stats(),
+				Verification:  counts,
+			}
+			if writeErr := writeJSON(result); err == nil {
+				err = writeErr
+			}
+		}()
+	}
+
+	if err := readPasswords(copier); err != nil {
+		return err
+	}
+	if err := copier.Connect(); err != nil {
+		return err
+	}
+	defer copier.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ctx, cancel := withMaxDuration(ctx, copier)
+	defer cancel()
+
+	tables, err := copier.ListTables()
+	if err != nil {
+		return err
+	}
+	if err := copier.CopyTablesContext(ctx, tables); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return timeLimited(copier, err)
+		}
+		if ctx.Err() != nil {
+			return fmt.Errorf("interrupted by user: %w", err)
+		}
+		return err
+	}
+
+	counts, err = copier.CountRows(tables)
+	if err != nil {
+		return err
+	}
+
+	mismatches := 0
+	for _, tc := range counts {
+		if !tc.Match() {
+			mismatches++
+		}
+	}
+
+	if !jsonOutput() {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TABLE\tSOURCE ROWS\tDEST ROWS\tSTATUS\t")
+		for _, tc := range counts {
+			status := "ok"
+			if !tc.Match() {
+				status = "MISMATCH"
+			}
+			fmt.Fprintf(w, "%s\t%d\t%d\t%s\t\n", tc.DestTable, tc.SourceRows, tc.DestRows, status)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if mismatches > 0 {
+		return fmt.Errorf("row counts differ for %d of %d tables", mismatches, len(counts))
+	}
+	return nil
+}
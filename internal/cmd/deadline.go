@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"db-copy/internal/db"
+
+	"go.uber.org/zap"
+)
+
+// ExitTimeLimit is the exit status of a copy stopped by --max-duration, so a
+// scheduler can tell a copy that ran out of time from one that failed
+const ExitTimeLimit = 3
+
+var maxDuration time.Duration
+
+// ExitError is an error that sets the exit status of the process
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the exit status for an error returned by RootCmd
+func ExitCode(err error) int {
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	return 1
+}
+
+// withMaxDuration bounds ctx by --max-duration
+func withMaxDuration(ctx context.Context, copier *db.Copier) (context.Context, context.CancelFunc) {
+	if maxDuration <= 0 {
+		return ctx, func() {}
+	}
+	if !copier.NoTransaction && copier.Workers <= 1 {
+		zap.L().Warn("Rows of a table still being copied at --max-duration are rolled back; use --no-transaction to keep the batches committed so far")
+	}
+	return context.WithTimeout(ctx, maxDuration)
+}
+
+// timeLimited reports how far a copy stopped by --max-duration got and
+// returns an error that exits with ExitTimeLimit. Running the same command
+// again skips the rows already in the destination and carries on.
+func timeLimited(copier *db.Copier, err error) error {
+	stats := copier.Stats()
+	// A --watch loop completes the same tables in every cycle
+	var tables []string
+	seen := make(map[string]bool)
+	for _, table := range stats.Tables {
+		if !seen[table] {
+			seen[table] = true
+			tables = append(tables, table)
+		}
+	}
+	done := "none"
+	if len(tables) > 0 {
+		done = strings.Join(tables, ", ")
+	}
+	return &ExitError{
+		Code: ExitTimeLimit,
+		Err: fmt.Errorf("stopped at --max-duration %s after inserting %d rows; tables completed: %s: %w",
+			maxDuration, stats.RowsCopied, done, err),
+	}
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"db-copy/internal/db"
+
+	"github.com/spf13/cobra"
+)
+
+// doctorCmd represents the doctor command
+var doctorCmd = &cobra.Command{
+	Use:     "doctor",
+	Aliases: []string{"diagnose"},
+	Short:   "Check connectivity to the source and destination without copying",
+	Long: `Checks that the source and destination connection strings parse, that both
+databases answer, reports their dialect and server version and, with --table,
+checks that the table exists and is readable. Failures come with a hint for
+common causes such as a wrong sslmode, a missing file or missing privileges.
+Nothing is created or copied.`,
+	RunE: runDoctor,
+}
+
+// doctorResult is the JSON result of the doctor command
+type doctorResult struct {
+	commandResult
+	Checks []db.Check `json:"checks"`
+}
+
+func runDoctor(cmd *cobra.Command, args []string) (err error) {
+	if err := checkConnFlags(); err != nil {
+		return err
+	}
+	// Failed checks are reported in the table; the usage text would only bury them
+	cmd.SilenceUsage = true
+
+	copier := db.NewCopier(sourceDB, destDB, tableName, 1)
+	if err := setConnFlags(copier); err != nil {
+		return err
+	}
+	copier.DestTable = destTable
+	if err := readPasswords(copier); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	checks := copier.Diagnose()
+	failed := 0
+	for _, check := range checks {
+		if !check.OK {
+			failed++
+		}
+	}
+	if failed > 0 {
+		err = fmt.Errorf("%d of %d checks failed", failed, len(checks))
+	}
+
+	if jsonOutput() {
+		result := doctorResult{commandResult: newCommandResult("doctor", start, err), Checks: checks}
+		if writeErr := writeJSON(result); err == nil {
+			err = writeErr
+		}
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DATABASE\tCHECK\tSTATUS\tDETAIL\t")
+	for _, check := range checks {
+		status := "ok"
+		if !check.OK {
+			status = "FAILED"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t\n", check.Database, check.Step, status, check.Detail)
+	}
+	if flushErr := w.Flush(); flushErr != nil {
+		return flushErr
+	}
+	for _, check := range checks {
+		if check.Hint != "" {
+			fmt.Printf("Hint (%s %s): %s\n", check.Database, check.Step, check.Hint)
+		}
+	}
+	return err
+}
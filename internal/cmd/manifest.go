@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"db-copy/internal/db"
+
+	"go.uber.org/zap"
+)
+
+var manifestPath string
+
+// manifest is the file written by --manifest: the source metadata that the
+// destination did not get
+type manifest struct {
+	Source      string              `json:"source"`
+	Destination string              `json:"destination"`
+	Unportable  []db.UnportableItem `json:"unportable"`
+}
+
+// startManifest sets up --manifest. The returned function writes what the
+// copier left behind, also when the copy failed part way, and is meant to be
+// deferred with a pointer to the command's error.
+func startManifest(copier *db.Copier) func(*error) {
+	if manifestPath == "" {
+		return func(*error) {}
+	}
+	copier.Manifest = manifestPath
+	return func(err *error) {
+		m := manifest{
+			Source:      db.RedactDSN(copier.SourceDB),
+			Destination: db.RedactDSN(copier.DestDB),
+			Unportable:  copier.Unportable(),
+		}
+		if len(m.Unportable) > 0 {
+			zap.L().Warn("Some source metadata was not migrated; see the manifest",
+				zap.Int("items", len(m.Unportable)), zap.String("manifest", manifestPath))
+		}
+		if writeErr := writeManifest(manifestPath, m); *err == nil {
+			*err = writeErr
+		}
+	}
+}
+
+// writeManifest writes the manifest as indented JSON. SQL is written as it is,
+// without escaping characters such as < and >.
+func writeManifest(path string, m manifest) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(m); err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
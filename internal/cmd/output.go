@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"db-copy/internal/db"
+
+	"github.com/spf13/cobra"
+)
+
+// Output formats for command results
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+var outputFormat string
+
+// commandResult is the JSON object printed by every command with --output json
+type commandResult struct {
+	Command         string  `json:"command"`
+	Success         bool    `json:"success"`
+	DurationSeconds float64 `json:"duration_seconds"`
+	Error           string  `json:"error,omitempty"`
+}
+
+// copyResult is the JSON result of the copy command
+type copyResult struct {
+	commandResult
+	db.Stats
+	Counts []db.RowCount `json:"counts,omitempty"`
+}
+
+// benchmarkResult is one row of the JSON result of the benchmark command
+type benchmarkResult struct {
+	BatchSize       int     `json:"batch_size"`
+	Workers         int     `json:"workers"`
+	Rows            int64   `json:"rows"`
+	DurationSeconds float64 `json:"duration_seconds"`
+	RowsPerSec      float64 `json:"rows_per_sec"`
+}
+
+// validateOutput checks --output and, for JSON, keeps cobra's error and usage
+// text off stdout
+func validateOutput(cmd *cobra.Command, args []string) error {
+	switch outputFormat {
+	case outputText:
+	case outputJSON:
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+	default:
+		return fmt.Errorf("invalid --output value %q: must be text or json", outputFormat)
+	}
+	return nil
+}
+
+// jsonOutput reports whether command results are printed as JSON
+func jsonOutput() bool {
+	return outputFormat == outputJSON
+}
+
+// newCommandResult fills in the fields shared by all command results
+func newCommandResult(command string, start time.Time, err error) commandResult {
+	result := commandResult{
+		Command:         command,
+		Success:         err == nil,
+		DurationSeconds: time.Since(start).Seconds(),
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// writeJSON prints a command result as a single JSON object on stdout
+func writeJSON(v interface{}) error {
+	if err := json.NewEncoder(os.Stdout).Encode(v); err != nil {
+		return fmt.Errorf("failed to write JSON output: %w", err)
+	}
+	return nil
+}
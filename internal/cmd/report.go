@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"db-copy/internal/db"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var reportPath string
+
+// runReport is the file written by --report: the command result, what the
+// copier did and every warning logged during the run
+type runReport struct {
+	commandResult
+	StartedAt time.Time `json:"started_at"`
+	db.Report
+	Warnings []map[string]interface{} `json:"warnings"`
+}
+
+// warningLog keeps a JSON line for every warning or error logged through zap
+type warningLog struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (l *warningLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.buf.Write(p)
+}
+
+func (l *warningLog) Sync() error {
+	return nil
+}
+
+// entries decodes the logged warnings
+func (l *warningLog) entries() []map[string]interface{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entries := []map[string]interface{}{}
+	for _, line := range bytes.Split(bytes.TrimSpace(l.buf.Bytes()), []byte("\n")) {
+		var entry map[string]interface{}
+		if json.Unmarshal(line, &entry) == nil {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// captureWarnings copies the warnings logged from now on into a warningLog
+// until the returned function restores the previous logger
+func captureWarnings() (*warningLog, func()) {
+	log := &warningLog{}
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.TimeKey = "timestamp"
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	encoderConfig.CallerKey = zapcore.OmitKey
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), log, zap.WarnLevel)
+
+	previous := zap.L()
+	restore := zap.ReplaceGlobals(previous.WithOptions(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+		return zapcore.NewTee(c, core)
+	})))
+	return log, restore
+}
+
+// startReport begins collecting warnings for --report. The returned function
+// writes the report of the command's result and what copier did, and is
+// meant to be deferred with a pointer to the command's error.
+func startReport(command string, copier *db.Copier) func(*error) {
+	if reportPath == "" {
+		return func(*error) {}
+	}
+	start := time.Now()
+	warnings, restore := captureWarnings()
+	return func(err *error) {
+		restore()
+		report := runReport{
+			commandResult: newCommandResult(command, start, *err),
+			StartedAt:     start,
+			Report:        copier.Report(),
+			Warnings:      warnings.entries(),
+		}
+		if writeErr := writeReport(reportPath, report); *err == nil {
+			*err = writeErr
+		}
+	}
+}
+
+// writeReport writes the report as Markdown when path ends in .md and as
+// indented JSON otherwise
+func writeReport(path string, report runReport) error {
+	var data []byte
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		data = []byte(markdownReport(report))
+	default:
+		var err error
+		if data, err = json.MarshalIndent(report, "", "  "); err != nil {
+			return fmt.Errorf("failed to encode report: %w", err)
+		}
+		data = append(data, '\n')
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// markdownReport renders the report as a Markdown document
+func markdownReport(r runReport) string {
+	var b strings.Builder
+	status := "succeeded"
+	if !r.Success {
+		status = "failed"
+	}
+	fmt.Fprintf(&b, "# db-copy %s report\n\n", r.Command)
+	fmt.Fprintf(&b, "- Started: %s\n", r.StartedAt.Format(time.RFC3339))
+	fmt.Fprintf(&b, "- Duration: %.1fs\n", r.DurationSeconds)
+	fmt.Fprintf(&b, "- Status: %s\n", status)
+	if r.Error != "" {
+		fmt.Fprintf(&b, "- Error: %s\n", r.Error)
+	}
+	fmt.Fprintf(&b, "- Source: `%s`\n", r.Source)
+	fmt.Fprintf(&b, "- Destination: `%s`\n", r.Destination)
+	fmt.Fprintf(&b, "- Rows copied: %d\n", r.RowsCopied)
+	if r.RowErrors > 0 {
+		fmt.Fprintf(&b, "- Rows skipped: %d\n", r.RowErrors)
+	}
+
+	b.WriteString("\n## Tables\n\n")
+	if len(r.TableResults) == 0 {
+		b.WriteString("No tables were copied.\n")
+	} else {
+		b.WriteString("| Table | Rows | Skipped rows | Duration | Status |\n|---|---:|---:|---:|---|\n")
+		for _, t := range r.TableResults {
+			status := "copied"
+			switch {
+			case t.Error != "":
+				status = "failed: " + t.Error
+			case t.Skipped != "":
+				status = "skipped: " + t.Skipped
+			}
+			fmt.Fprintf(&b, "| %s | %d | %d | %.1fs | %s |\n", t.Table, t.Rows, t.SkippedRows, t.DurationSeconds, strings.ReplaceAll(status, "|", `\|`))
+		}
+	}
+
+	if len(r.DDL) > 0 {
+		b.WriteString("\n## DDL\n\n```sql\n")
+		for _, statement := range r.DDL {
+			b.WriteString(strings.TrimSuffix(statement, ";") + ";\n")
+		}
+		b.WriteString("```\n")
+	}
+
+	b.WriteString("\n## Warnings\n\n")
+	if len(r.Warnings) == 0 {
+		b.WriteString("None.\n")
+	}
+	for _, w := range r.Warnings {
+		fmt.Fprintf(&b, "- %v", w["msg"])
+		var details []string
+		for key, value := range w {
+			if key != "msg" && key != "level" && key != "timestamp" {
+				details = append(details, fmt.Sprintf("%s=%v", key, value))
+			}
+		}
+		if len(details) > 0 {
+			sort.Strings(details)
+			fmt.Fprintf(&b, " (%s)", strings.Join(details, ", "))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"db-copy/internal/db"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var reverse bool
+
+// directionFlags are options that only take effect for a PostgreSQL source or
+// destination, and so may stop applying once --reverse swaps the two. Options
+// the copier already warns about when they do not apply, such as --dest-owner,
+// are left to it.
+var directionFlags = []struct {
+	name     string
+	pgSource bool // needs a PostgreSQL source, otherwise a PostgreSQL destination
+	clear    func()
+}{
+	{"read-consistency", true, func() { consistency = "" }},
+	{"source-schema", true, func() { sourceSchemas = nil }},
+	{"copy-sequences", false, func() { copySeqs = false }},
+	{"dest-dialect", false, func() { destDialect = "" }},
+	{"create-dest-db", false, func() { createDestDB = false }},
+}
+
+// reverseFlags swaps the source and destination flags for --reverse, so a copy
+// can be run back the other way with the same options. --dest-table, if set,
+// becomes the table read, and --table the table written.
+func reverseFlags(cmd *cobra.Command) error {
+	if !reverse {
+		return nil
+	}
+	if query != "" {
+		return fmt.Errorf("--reverse cannot be used with --query, which is written for the source database")
+	}
+	if destFormat != "" || !db.IsReadableDSN(destDB) {
+		return fmt.Errorf("--reverse needs a destination that can be read from; DuckDB, Parquet, CSV and Redis destinations cannot")
+	}
+	if sourcePasswordStdin && destPasswordStdin {
+		return fmt.Errorf("--reverse cannot be used with both --source-password-stdin and --dest-password-stdin")
+	}
+
+	sourceDB, destDB = destDB, sourceDB
+	sourceParams, destParams = destParams, sourceParams
+	sourceDSNParams, destDSNParams = destDSNParams, sourceDSNParams
+	sourcePasswordStdin, destPasswordStdin = destPasswordStdin, sourcePasswordStdin
+	if destTable != "" {
+		tableName, destTable = destTable, tableName
+	}
+
+	sourcePG := strings.HasPrefix(sourceDB, "postgres://") || sourceParams.IsSet()
+	destPG := strings.HasPrefix(destDB, "postgres://") || destParams.IsSet()
+	for _, flag := range directionFlags {
+		if !cmd.Flags().Changed(flag.name) {
+			continue
+		}
+		if flag.pgSource && !sourcePG {
+			zap.L().Warn("Option does not apply in reverse and is ignored: the source is no longer PostgreSQL", zap.String("flag", "--"+flag.name))
+			flag.clear()
+		} else if !flag.pgSource && !destPG {
+			zap.L().Warn("Option does not apply in reverse and is ignored: the destination is no longer PostgreSQL", zap.String("flag", "--"+flag.name))
+			flag.clear()
+		}
+	}
+	for _, name := range []string{"dest-table-prefix", "dest-table-suffix", "schema-map"} {
+		if cmd.Flags().Changed(name) {
+			zap.L().Warn("Option still renames the tables written, which are now the original source tables", zap.String("flag", "--"+name))
+		}
+	}
+	return nil
+}
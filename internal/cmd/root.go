@@ -1,19 +1,129 @@
 package cmd
 
 import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
 	"db-copy/internal/db"
+	"db-copy/internal/tui"
 
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
+	"golang.org/x/term"
 )
 
 var (
-	sourceDB     string
-	destDB       string
-	tableName    string
-	batchSize    int
-	recordCount  int
-	sampleDBPath string
+	sourceDB       string
+	destDB         string
+	tableName      string
+	batchSize      int
+	recordCount    int
+	sampleDBPath   string
+	sampleSeed     int64
+	sampleBatch    int
+	onMissing      string
+	ifExists       string
+	allTables      bool
+	schemaOnly     bool
+	noTx           bool
+	copySeqs       bool
+	workers        int
+	maxBatchBytes  int
+	adaptiveBatch  bool
+	minBatch       int
+	maxBatch       int
+	batchLatency   time.Duration
+	destTablespace string
+	destTableOpts  string
+	destOwner      string
+	analyze        bool
+	vacuum         bool
+	query          string
+	destTable      string
+	typeOverrides  []string
+	typeMapFile    string
+	destDialect    string
+	rateLimit      int
+	destFormat     string
+	noChecks       bool
+	noTriggers     bool
+	ddlOut         string
+	interactive    bool
+	preSQL         string
+	postSQL        string
+	postSQLAlways  bool
+	failOnEmpty    bool
+	geometryAsWKT  bool
+	configPath     string
+	destPrefix     string
+	destSuffix     string
+	strict         bool
+	heartbeat      time.Duration
+	progressEvery  time.Duration
+	atomicSwap     bool
+	schemaCache    string
+	createDestDB   bool
+	setNow         []string
+	defaults       []string
+	lookups        []string
+	lookupStrict   bool
+	skipUnchanged  bool
+	force          bool
+	sourceTimeout  time.Duration
+	paginate       bool
+	keyColumn      string
+	destPK         []string
+	compareSchema  bool
+	fetchSize      int
+	readonlyCheck  bool
+	wireCompress   bool
+	tempTable      bool
+	storageParams  bool
+	coerce         bool
+	redisTTL       time.Duration
+	columnsCase    string
+	verifyMode     string
+	verifySize     int
+	destCollation  string
+	keepCollation  bool
+	skipErrors     bool
+	maxErrors      int
+	onViolation    string
+	insertMethod   string
+	copyNull       string
+	consistency    string
+	timeFormat     string
+	sourceSchemas  []string
+	schemaMap      []string
+	distinct       bool
+	distinctOn     []string
+	copyColumns    []string
+	headerMap      []string
+	timeColumn     string
+	lastValue      string
+	countOnly      bool
+	approx         bool
+
+	sourcePasswordStdin bool
+	destPasswordStdin   bool
+	passwordPrompt      bool
+
+	benchSource     string
+	benchDest       string
+	benchTable      string
+	benchCount      int
+	benchBatchSizes []int
+	benchWorkers    []int
 )
 
 // RootCmd represents the base command when called without any subcommands
@@ -22,6 +132,7 @@ var RootCmd = &cobra.Command{
 	Short: "A CLI tool to copy tables between different databases",
 	Long: `db-copy allows you to copy tables between different database types.
 Currently supports copying from SQLite to PostgreSQL.`,
+	PersistentPreRunE: setupOutput,
 }
 
 // copyCmd represents the copy command
@@ -40,41 +151,837 @@ The table includes fields like ID, Name, Email, Age, Active status, and timestam
 	RunE: runSample,
 }
 
+// benchmarkCmd represents the benchmark command
+var benchmarkCmd = &cobra.Command{
+	Use:   "benchmark",
+	Short: "Measure copy throughput for different batch sizes and worker counts",
+	Long: `Runs a copy for every combination of batch size and worker count and reports rows/sec.
+Without --source a temporary sample database is generated. Without --dest each run
+writes to a fresh temporary SQLite file.`,
+	RunE: runBenchmark,
+}
+
 func init() {
+	RootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", outputText, "Result format: text or json (logs always go to stderr)")
+	RootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colored output (also set by the NO_COLOR environment variable; off when stderr is not a terminal)")
+
 	// Copy command flags
-	copyCmd.Flags().StringVarP(&sourceDB, "source", "s", "", "Source database connection string (SQLite)")
-	copyCmd.Flags().StringVarP(&destDB, "dest", "d", "", "Destination database connection string (PostgreSQL)")
+	addConnFlags(copyCmd, "Source database connection string (SQLite)", "Destination database connection string (PostgreSQL)")
 	copyCmd.Flags().StringVarP(&tableName, "table", "t", "", "Table name to copy")
+	copyCmd.Flags().StringVarP(&query, "query", "q", "", "SQL query whose result is copied instead of a table (requires --dest-table)")
+	copyCmd.Flags().BoolVar(&distinct, "distinct", false, "Copy each distinct source row once, collapsing duplicates")
+	copyCmd.Flags().StringSliceVar(&distinctOn, "distinct-on", nil, "Copy one row per distinct value of these columns, the first in their order (comma-separated)")
+	copyCmd.Flags().StringVar(&timeColumn, "time-column", "", "Copy only the rows whose value in this timestamp column is within --last of now")
+	copyCmd.Flags().StringVar(&lastValue, "last", "", "With --time-column, how far back to copy, e.g. 30d, 2w or 12h")
+	copyCmd.Flags().StringVar(&consistency, "read-consistency", "", "Read every table in one source transaction at this isolation level so they come from the same snapshot: repeatable-read or serializable (PostgreSQL sources)")
+	copyCmd.Flags().BoolVar(&reverse, "reverse", false, "Copy back from the destination to the source with the same options; --table and --dest-table swap too")
+	copyCmd.Flags().StringVar(&destTable, "dest-table", "", "Destination table name (default: same as --table)")
+	copyCmd.Flags().StringArrayVar(&sourceSchemas, "source-schema", nil, "PostgreSQL schema of the --table to copy, or with --all-tables, copy the tables of this schema, or of every schema with 'all' (repeatable)")
+	copyCmd.Flags().StringArrayVar(&schemaMap, "schema-map", nil, "Destination schema for a source schema as source=dest (repeatable)")
+	copyCmd.Flags().StringVar(&destPrefix, "dest-table-prefix", "", "Prefix added to destination table and index names")
+	copyCmd.Flags().StringVar(&destSuffix, "dest-table-suffix", "", "Suffix added to destination table and index names")
+	copyCmd.Flags().StringArrayVar(&typeOverrides, "type-override", nil, "Destination column type as column=TYPE (repeatable)")
+	copyCmd.Flags().StringVar(&typeMapFile, "type-map-file", "", "CSV or YAML file of destination column types by column name or source type pattern")
+	copyCmd.Flags().StringVar(&destCollation, "dest-collation", "", "Collation for the text columns of created PostgreSQL tables, e.g. C or en_US")
+	copyCmd.Flags().BoolVar(&keepCollation, "preserve-collation", false, "Keep the column collations of a PostgreSQL source, taking precedence over --dest-collation")
+	copyCmd.Flags().StringVar(&verifyMode, "verify", "", "Check the copy afterwards: sample compares a random sample of rows by primary key")
+	copyCmd.Flags().IntVar(&verifySize, "verify-sample-size", 100, "Number of rows checked per table by --verify=sample")
+	copyCmd.Flags().StringVar(&columnsCase, "columns-case", db.ColumnsCasePreserve, "Case of destination column names: preserve, lower or upper")
+	copyCmd.Flags().DurationVar(&redisTTL, "redis-ttl", 0, "Expiry of the keys written to a Redis destination, e.g. 1h (0 = no expiry)")
+	copyCmd.Flags().StringVar(&timeFormat, "time-format", "", "Layout of text timestamps in an SQLite source: rfc3339, iso8601, datetime, date or a Go time layout")
+	copyCmd.Flags().BoolVar(&coerce, "coerce", false, "Trim and convert text values to numeric and boolean destination columns instead of failing")
+	copyCmd.Flags().BoolVar(&storageParams, "copy-storage-params", false, "Copy table storage parameters such as fillfactor and autovacuum settings (PostgreSQL to PostgreSQL)")
+	copyCmd.Flags().DurationVar(&sourceTimeout, "source-query-timeout", 0, "Abort when reading a table or query from the source takes longer than this, e.g. 10m (0 = no limit)")
+	copyCmd.Flags().BoolVar(&paginate, "paginate", false, "Read the source table with one query per batch, keyed on its primary key, instead of through one long-running cursor")
+	copyCmd.Flags().StringVar(&keyColumn, "key-column", "", "Column that identifies source rows, used in place of the primary key to match and page rows")
+	copyCmd.Flags().StringSliceVar(&destPK, "dest-primary-key", nil, "Create the destination table with a primary key on these columns instead of the source's (comma-separated)")
+	copyCmd.Flags().IntVar(&fetchSize, "fetch-size", 0, "Read a PostgreSQL source through a server-side cursor, fetching this many rows per round trip (0 = off)")
+	copyCmd.Flags().BoolVar(&wireCompress, "wire-compress", false, "Report whether the PostgreSQL source connection is compressed")
+	copyCmd.Flags().BoolVar(&skipUnchanged, "skip-unchanged", false, "With --all-tables, skip tables whose row count and latest updated_at match the destination")
+	copyCmd.Flags().BoolVar(&force, "force", false, "Copy every table even with --skip-unchanged, and into mismatched tables with --dest-if-table-exists-compare")
+	copyCmd.Flags().BoolVar(&compareSchema, "dest-if-table-exists-compare", false, "Compare an existing destination table with the source schema and abort with the differences unless they are compatible")
+	copyCmd.Flags().BoolVar(&countOnly, "count-only", false, "Print the number of source rows of each table instead of copying")
+	copyCmd.Flags().BoolVar(&approx, "approx", false, "With --count-only, use planner statistics instead of counting every row where available")
+	copyCmd.Flags().StringArrayVar(&defaults, "default", nil, "Value inserted instead of NULL in a column as column=value (repeatable)")
+	copyCmd.Flags().StringArrayVar(&lookups, "lookup", nil, "Translate the values of a source column through a CSV file of old,new pairs as column=file.csv (repeatable)")
+	copyCmd.Flags().BoolVar(&lookupStrict, "lookup-strict", false, "Fail on a --lookup column value that is not in its CSV file instead of copying it unchanged")
+	copyCmd.Flags().StringArrayVar(&setNow, "set-now", nil, "Set this timestamp column to the copy time instead of the source value (repeatable)")
 	copyCmd.Flags().IntVarP(&batchSize, "batch-size", "b", 1000, "Batch size for copying records")
+	copyCmd.Flags().BoolVar(&adaptiveBatch, "adaptive-batch", false, "Tune the batch size while copying: start at --min-batch and double it while inserts stay under --batch-latency, halving it on slow or failed inserts")
+	copyCmd.Flags().IntVar(&minBatch, "min-batch", db.DefaultMinBatch, "With --adaptive-batch, the smallest and starting batch size")
+	copyCmd.Flags().IntVar(&maxBatch, "max-batch", db.DefaultMaxBatch, "With --adaptive-batch, the largest batch size")
+	copyCmd.Flags().DurationVar(&batchLatency, "batch-latency", db.DefaultBatchLatency, "With --adaptive-batch, the target time for one insert")
+	copyCmd.Flags().StringVar(&configPath, "config", "", "JSON config file with per-table settings")
+	copyCmd.Flags().StringVar(&reportPath, "report", "", "Write a report of the run to this file: Markdown for .md files, JSON otherwise")
+	copyCmd.Flags().BoolVar(&allTables, "all-tables", false, "Copy all tables from the source database")
+	copyCmd.Flags().BoolVar(&schemaOnly, "schema-only", false, "Create destination tables without copying any rows")
+	copyCmd.Flags().BoolVar(&noTx, "no-transaction", false, "Commit each batch on its own instead of wrapping the copy in one transaction")
+	copyCmd.Flags().BoolVar(&skipErrors, "skip-errors", false, "Retry a failed batch row by row and skip the rows the destination rejects")
+	copyCmd.Flags().IntVar(&maxErrors, "max-errors", 0, "With --skip-errors, abort the copy once this many rows have failed (0 means unlimited)")
+	copyCmd.Flags().StringVar(&onViolation, "dest-unique-violation", db.UniqueViolationError, "Action when a batch violates a unique constraint: error, or skip-table to keep the rows copied so far and move on to the next table")
+	copyCmd.Flags().StringVar(&insertMethod, "insert-method", db.InsertMultiValues, "How rows are inserted: multi-values, prepared, copy (PostgreSQL COPY) or auto")
+	copyCmd.Flags().StringVar(&copyNull, "pg-copy-null", "", "String that stands for NULL in the rows sent with --insert-method copy (default \\N)")
+	copyCmd.Flags().BoolVar(&copySeqs, "copy-sequences", false, "Recreate sequences for serial/identity columns (PostgreSQL to PostgreSQL only)")
+	copyCmd.Flags().IntVarP(&workers, "workers", "w", 1, "Number of concurrent insert workers (more than one implies --no-transaction)")
+	copyCmd.Flags().BoolVar(&sourcePasswordStdin, "source-password-stdin", false, "Read the source database password from stdin")
+	copyCmd.Flags().BoolVar(&destPasswordStdin, "dest-password-stdin", false, "Read the destination database password from stdin (after the source password, if both are read)")
+	copyCmd.Flags().BoolVar(&passwordPrompt, "password-prompt", false, "Prompt for passwords missing from PostgreSQL connection strings")
+	copyCmd.Flags().IntVar(&maxBatchBytes, "max-batch-bytes", 0, "Flush a batch early once its estimated size exceeds this many bytes (0 = no limit)")
+	copyCmd.Flags().StringVar(&destTablespace, "dest-tablespace", "", "Tablespace for created tables and indexes (PostgreSQL destinations only)")
+	copyCmd.Flags().StringVar(&destTableOpts, "dest-table-options", "", "Clause appended verbatim to the generated CREATE TABLE, e.g. \"PARTITION BY RANGE (created_at)\" (not validated)")
+	copyCmd.Flags().StringVar(&destOwner, "dest-owner", "", "Role that owns created tables, their indexes and sequences (PostgreSQL destinations only)")
+	copyCmd.Flags().BoolVar(&analyze, "analyze", false, "Run ANALYZE on the destination table after the copy (default: on for PostgreSQL destinations)")
+	copyCmd.Flags().BoolVar(&vacuum, "vacuum", false, "Run VACUUM on the destination after the copy")
+	copyCmd.Flags().StringVar(&destDialect, "dest-dialect", "", "SQL dialect of a PostgreSQL-protocol destination: postgres or crdb (default: detected from the server version)")
+	copyCmd.Flags().IntVar(&rateLimit, "rate-limit", 0, "Maximum rows per second to copy (0 = unlimited)")
+	copyCmd.Flags().StringVar(&destFormat, "format", "", "Destination format when it cannot be detected from --dest: duckdb, parquet or csv")
+	copyCmd.Flags().StringSliceVar(&copyColumns, "columns", nil, "Copy only these columns of --table, in this order for CSV files (comma-separated)")
+	copyCmd.Flags().StringArrayVar(&headerMap, "header-map", nil, "Header of a source column in a CSV file as column=Header, leaving the data unchanged (repeatable)")
+	copyCmd.Flags().BoolVar(&noChecks, "no-checks", false, "Do not copy CHECK constraints")
+	copyCmd.Flags().BoolVar(&noTriggers, "no-triggers", false, "Do not recreate the triggers of the source tables")
+	copyCmd.Flags().StringVar(&ddlOut, "ddl-out", "", "Write the definitions of triggers that could not be recreated on the destination to this file")
+	copyCmd.Flags().StringVar(&manifestPath, "manifest", "", "Write the comments, constraints, indexes and triggers of the source that the destination did not get to this JSON file")
+	copyCmd.Flags().StringVar(&preSQL, "pre-sql", "", "SQL to run on the destination before the copy (or @file)")
+	copyCmd.Flags().StringVar(&postSQL, "post-sql", "", "SQL to run on the destination after the copy commits (or @file)")
+	copyCmd.Flags().BoolVar(&postSQLAlways, "post-sql-always", false, "Run --post-sql even if the copy failed")
+	copyCmd.Flags().BoolVar(&createDestDB, "create-dest-db", false, "Create the PostgreSQL destination database if it does not exist")
+	copyCmd.Flags().BoolVar(&readonlyCheck, "dest-readonly-check", false, "Fail before reading the source when the destination does not accept writes, e.g. a read-only replica")
+	copyCmd.Flags().BoolVar(&tempTable, "dest-temp-table", false, "Create the destination table as a temporary table that is dropped when the connection closes (PostgreSQL and SQLite)")
+	copyCmd.Flags().StringVar(&schemaCache, "schema-cache", "", "File caching source table schemas between runs")
+	copyCmd.Flags().BoolVar(&atomicSwap, "atomic-swap", false, "Load into a staging table and replace the destination table with it only if the copy succeeds")
+	copyCmd.Flags().DurationVar(&progressEvery, "progress-interval", 0, "Print the rows copied into the current table at this interval, e.g. 5s, instead of a line after every batch (0 = after every batch)")
+	copyCmd.Flags().DurationVar(&maxDuration, "max-duration", 0, "Stop the copy after this long, e.g. 2h, and exit with status 3 (0 = no limit)")
+	copyCmd.Flags().DurationVar(&watchEvery, "watch", 0, "Repeat the copy at this interval, e.g. 1m, reading only rows above the highest key in the destination, until interrupted")
+	copyCmd.Flags().BoolVar(&failFast, "fail-fast", false, "With --watch, stop at the first failed cycle instead of retrying at the next interval")
+	copyCmd.Flags().DurationVar(&heartbeat, "heartbeat", 0, "Log a still-working message with the rows copied so far at this interval, e.g. 30s (0 = off)")
+	copyCmd.Flags().BoolVar(&strict, "strict", false, "Fail instead of warning when a column conversion may lose data")
+	copyCmd.Flags().BoolVar(&failOnEmpty, "fail-on-empty", false, "Exit with an error when the source table or query has no rows")
+	copyCmd.Flags().BoolVar(&geometryAsWKT, "geometry-as-wkt", false, "Copy PostGIS geometry and geography columns as WKT text")
+	copyCmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "Choose tables, columns and batch size in a terminal UI")
+	copyCmd.Flags().StringVar(&onMissing, "on-missing-table", db.OnMissingTableCreate, "Action when the destination table is missing: create, skip or error")
+	copyCmd.Flags().StringVar(&ifExists, "dest-if-exists", db.IfExistsAppend, "Action when the destination table exists: append, or append-missing-columns to first add the source columns it lacks")
 
-	copyCmd.MarkFlagRequired("source")
-	copyCmd.MarkFlagRequired("dest")
-	copyCmd.MarkFlagRequired("table")
+	// Copy-db command flags
+	addConnFlags(copyDBCmd, "Source database connection string", "Destination database connection string")
+	copyDBCmd.Flags().StringArrayVar(&sourceSchemas, "source-schema", nil, "Copy the tables of this PostgreSQL schema, or of every schema with 'all' (repeatable)")
+	copyDBCmd.Flags().BoolVar(&reverse, "reverse", false, "Copy back from the destination to the source with the same options")
+	copyDBCmd.Flags().StringArrayVar(&schemaMap, "schema-map", nil, "Destination schema for a source schema as source=dest (repeatable)")
+	copyDBCmd.Flags().IntVarP(&batchSize, "batch-size", "b", 1000, "Batch size for copying records")
+	copyDBCmd.Flags().StringVar(&configPath, "config", "", "JSON config file with per-table settings")
+	copyDBCmd.Flags().BoolVar(&adaptiveBatch, "adaptive-batch", false, "Tune the batch size while copying: start at --min-batch and double it while inserts stay under --batch-latency, halving it on slow or failed inserts")
+	copyDBCmd.Flags().IntVar(&minBatch, "min-batch", db.DefaultMinBatch, "With --adaptive-batch, the smallest and starting batch size")
+	copyDBCmd.Flags().IntVar(&maxBatch, "max-batch", db.DefaultMaxBatch, "With --adaptive-batch, the largest batch size")
+	copyDBCmd.Flags().DurationVar(&batchLatency, "batch-latency", db.DefaultBatchLatency, "With --adaptive-batch, the target time for one insert")
+	copyDBCmd.Flags().IntVarP(&workers, "workers", "w", 1, "Number of concurrent insert workers (more than one implies --no-transaction)")
+	copyDBCmd.Flags().DurationVar(&progressEvery, "progress-interval", 0, "Print the rows copied into the current table at this interval, e.g. 5s, instead of a line after every batch (0 = after every batch)")
+	copyDBCmd.Flags().DurationVar(&maxDuration, "max-duration", 0, "Stop the copy after this long, e.g. 2h, and exit with status 3 (0 = no limit)")
+	copyDBCmd.Flags().BoolVar(&readonlyCheck, "dest-readonly-check", false, "Fail before reading the source when the destination does not accept writes, e.g. a read-only replica")
+	copyDBCmd.Flags().IntVar(&fetchSize, "fetch-size", 0, "Read a PostgreSQL source through a server-side cursor, fetching this many rows per round trip (0 = off)")
+	copyDBCmd.Flags().BoolVar(&wireCompress, "wire-compress", false, "Report whether the PostgreSQL source connection is compressed")
+	copyDBCmd.Flags().BoolVar(&paginate, "paginate", false, "Read each source table with one query per batch, keyed on its primary key, instead of through one long-running cursor")
+	copyDBCmd.Flags().BoolVar(&noTriggers, "no-triggers", false, "Do not recreate the triggers of the source tables")
+	copyDBCmd.Flags().StringVar(&ddlOut, "ddl-out", "", "Write the definitions of triggers that could not be recreated on the destination to this file")
+	copyDBCmd.Flags().StringVar(&manifestPath, "manifest", "", "Write the comments, constraints, indexes and triggers of the source that the destination did not get to this JSON file")
+	copyDBCmd.Flags().StringVar(&typeMapFile, "type-map-file", "", "CSV or YAML file of destination column types by column name or source type pattern")
+	copyDBCmd.Flags().StringVar(&consistency, "read-consistency", "", "Read every table in one source transaction at this isolation level so they come from the same snapshot: repeatable-read or serializable (PostgreSQL sources)")
+	copyDBCmd.Flags().StringVar(&onViolation, "dest-unique-violation", db.UniqueViolationError, "Action when a batch violates a unique constraint: error, or skip-table to keep the rows copied so far and move on to the next table")
+	copyDBCmd.Flags().StringVar(&insertMethod, "insert-method", db.InsertMultiValues, "How rows are inserted: multi-values, prepared, copy (PostgreSQL COPY) or auto")
+	copyDBCmd.Flags().StringVar(&copyNull, "pg-copy-null", "", "String that stands for NULL in the rows sent with --insert-method copy (default \\N)")
+	copyDBCmd.Flags().BoolVar(&noTx, "no-transaction", false, "Commit each batch on its own instead of wrapping each table in one transaction")
+	copyDBCmd.Flags().BoolVar(&passwordPrompt, "password-prompt", false, "Prompt for passwords missing from PostgreSQL connection strings")
+	copyDBCmd.Flags().StringVar(&reportPath, "report", "", "Write a report of the run to this file: Markdown for .md files, JSON otherwise")
+
+	// Doctor command flags
+	addConnFlags(doctorCmd, "Source database connection string", "Destination database connection string")
+	doctorCmd.Flags().StringVarP(&tableName, "table", "t", "", "Also check that this table is readable in the source")
+	doctorCmd.Flags().StringVar(&destTable, "dest-table", "", "Destination table name to check (default: same as --table)")
+	doctorCmd.Flags().BoolVar(&passwordPrompt, "password-prompt", false, "Prompt for passwords missing from PostgreSQL connection strings")
+
+	// Schema command flags
+	schemaExportCmd.Flags().StringVarP(&sourceDB, "source", "s", "", "Source database connection string")
+	schemaExportCmd.Flags().StringVarP(&tableName, "table", "t", "", "Table whose schema to export")
+	schemaExportCmd.Flags().StringVar(&schemaOut, "out", "", "Path of the JSON schema file to write")
+	schemaExportCmd.Flags().BoolVar(&passwordPrompt, "password-prompt", false, "Prompt for a password missing from a PostgreSQL connection string")
+	schemaApplyCmd.Flags().StringVarP(&destDB, "dest", "d", "", "Destination database connection string")
+	schemaApplyCmd.Flags().StringVar(&schemaIn, "in", "", "Path of a JSON schema file written by schema export")
+	schemaApplyCmd.Flags().StringVar(&destTable, "dest-table", "", "Destination table name (default: the table named in the file)")
+	schemaApplyCmd.Flags().BoolVar(&passwordPrompt, "password-prompt", false, "Prompt for a password missing from a PostgreSQL connection string")
+	schemaCmd.AddCommand(schemaExportCmd)
+	schemaCmd.AddCommand(schemaApplyCmd)
 
 	// Sample command flags
 	sampleCmd.Flags().StringVarP(&sampleDBPath, "db", "d", "sample.db", "Path to create the sample SQLite database")
 	sampleCmd.Flags().IntVarP(&recordCount, "count", "c", 1000, "Number of sample records to create")
+	sampleCmd.Flags().Int64Var(&sampleSeed, "seed", 0, "Seed for reproducible sample data (0 = use the current time)")
+	sampleCmd.Flags().IntVarP(&sampleBatch, "batch-size", "b", db.DefaultSampleBatchSize, "Number of records inserted per statement")
+
+	// Benchmark command flags
+	benchmarkCmd.Flags().StringVarP(&benchSource, "source", "s", "", "Source database (default: a generated sample database)")
+	benchmarkCmd.Flags().StringVarP(&benchDest, "dest", "d", "", "Throwaway destination database; the table is dropped before each run (default: temporary SQLite files)")
+	benchmarkCmd.Flags().StringVarP(&benchTable, "table", "t", "sample_users", "Table name to copy")
+	benchmarkCmd.Flags().IntVarP(&benchCount, "count", "c", 10000, "Number of sample records to generate when --source is not set")
+	benchmarkCmd.Flags().Int64Var(&sampleSeed, "seed", 0, "Seed for reproducible sample data when --source is not set")
+	benchmarkCmd.Flags().IntSliceVar(&benchBatchSizes, "batch-sizes", []int{100, 500, 1000, 5000}, "Batch sizes to benchmark")
+	benchmarkCmd.Flags().IntSliceVar(&benchWorkers, "workers", []int{1, 2, 4}, "Worker counts to benchmark")
 
 	RootCmd.AddCommand(copyCmd)
+	RootCmd.AddCommand(copyDBCmd)
+	RootCmd.AddCommand(doctorCmd)
+	RootCmd.AddCommand(schemaCmd)
 	RootCmd.AddCommand(sampleCmd)
+	RootCmd.AddCommand(benchmarkCmd)
 }
 
-func runCopy(cmd *cobra.Command, args []string) error {
+func runCopy(cmd *cobra.Command, args []string) (err error) {
+	if err := reverseFlags(cmd); err != nil {
+		return err
+	}
+	if err := checkConnFlags(); err != nil {
+		return err
+	}
+
+	sources := 0
+	for _, set := range []bool{tableName != "", allTables, query != ""} {
+		if set {
+			sources++
+		}
+	}
+	if interactive {
+		if sources != 0 {
+			return fmt.Errorf("--interactive cannot be combined with --table, --all-tables or --query")
+		}
+	} else if sources != 1 {
+		return fmt.Errorf("exactly one of --table, --all-tables, --query or --interactive must be set")
+	}
+	if batchSize < 1 {
+		return fmt.Errorf("--batch-size must be at least 1")
+	}
+	if err := checkAdaptiveBatch(); err != nil {
+		return err
+	}
+	if err := checkFetchSize(); err != nil {
+		return err
+	}
+	if err := checkWatch(); err != nil {
+		return err
+	}
+	if countOnly && (query != "" || interactive) {
+		return fmt.Errorf("--count-only requires --table or --all-tables")
+	}
+	if skipUnchanged && !allTables {
+		return fmt.Errorf("--skip-unchanged can only be used with --all-tables")
+	}
+	if maxErrors < 0 {
+		return fmt.Errorf("--max-errors must not be negative")
+	}
+	if maxErrors > 0 && !skipErrors {
+		return fmt.Errorf("--max-errors can only be used with --skip-errors")
+	}
+	if err := checkUniqueViolation(); err != nil {
+		return err
+	}
+	if err := checkInsertMethod(); err != nil {
+		return err
+	}
+	if err := checkReadConsistency(); err != nil {
+		return err
+	}
+	if approx && !countOnly {
+		return fmt.Errorf("--approx can only be used with --count-only")
+	}
+	if query != "" && destTable == "" {
+		return fmt.Errorf("--dest-table is required with --query")
+	}
+	if allTables && destTable != "" {
+		return fmt.Errorf("--dest-table cannot be used with --all-tables")
+	}
+
+	switch destDialect {
+	case "", db.DialectPostgres, db.DialectCockroach:
+	default:
+		return fmt.Errorf("invalid --dest-dialect value %q: must be postgres or crdb", destDialect)
+	}
+
+	switch destFormat {
+	case "":
+	case "duckdb":
+		if !strings.HasPrefix(destDB, "duckdb://") {
+			destDB = "duckdb://" + destDB
+		}
+	case "parquet":
+		if !strings.HasPrefix(destDB, "parquet://") && !strings.HasSuffix(strings.ToLower(destDB), ".parquet") {
+			destDB = "parquet://" + destDB
+		}
+	case "csv":
+		if !strings.HasPrefix(destDB, "csv://") && !strings.HasSuffix(strings.ToLower(destDB), ".csv") {
+			destDB = "csv://" + destDB
+		}
+	default:
+		return fmt.Errorf("invalid --format value %q: must be duckdb, parquet or csv", destFormat)
+	}
+	if len(copyColumns) > 0 && tableName == "" {
+		return fmt.Errorf("--columns can only be used with --table")
+	}
+	if len(destPK) > 0 && tableName == "" && query == "" {
+		return fmt.Errorf("--dest-primary-key can only be used with --table or --query")
+	}
+
+	overrides, err := parseTypeOverrides(typeOverrides)
+	if err != nil {
+		return err
+	}
+	nullDefaults, err := parseDefaults(defaults)
+	if err != nil {
+		return err
+	}
+	lookupTables, err := parseLookups(lookups)
+	if err != nil {
+		return err
+	}
+	headers, err := parseHeaderMap(headerMap)
+	if err != nil {
+		return err
+	}
+	if (distinct || len(distinctOn) > 0) && query != "" {
+		return fmt.Errorf("--distinct and --distinct-on cannot be used with --query; use DISTINCT in the query")
+	}
+	if (timeColumn == "") != (lastValue == "") {
+		return fmt.Errorf("--time-column and --last must be used together")
+	}
+	if timeColumn != "" && query != "" {
+		return fmt.Errorf("--time-column cannot be used with --query; filter the rows in the query")
+	}
+	var last time.Duration
+	if lastValue != "" {
+		if last, err = db.ParseLast(lastValue); err != nil {
+			return err
+		}
+	}
+	if len(sourceSchemas) > 0 && !allTables {
+		if tableName == "" || len(sourceSchemas) > 1 || sourceSchemas[0] == db.AllSchemas {
+			return fmt.Errorf("--source-schema takes a single schema with --table, or any number with --all-tables")
+		}
+		if strings.Contains(tableName, ".") {
+			return fmt.Errorf("--table %s is already schema-qualified; drop --source-schema", tableName)
+		}
+		tableName = sourceSchemas[0] + "." + tableName
+	}
+	schemas, err := parseSchemaMap(sourceSchemas, schemaMap)
+	if err != nil {
+		return err
+	}
+
+	switch verifyMode {
+	case "", db.VerifySample:
+	default:
+		return fmt.Errorf("invalid --verify value %q: must be sample", verifyMode)
+	}
+
+	switch columnsCase {
+	case db.ColumnsCasePreserve, db.ColumnsCaseLower, db.ColumnsCaseUpper:
+	default:
+		return fmt.Errorf("invalid --columns-case value %q: must be preserve, lower or upper", columnsCase)
+	}
+
+	switch onMissing {
+	case db.OnMissingTableCreate, db.OnMissingTableSkip, db.OnMissingTableError:
+	default:
+		return fmt.Errorf("invalid --on-missing-table value %q: must be create, skip or error", onMissing)
+	}
+	switch ifExists {
+	case db.IfExistsAppend, db.IfExistsAppendMissingColumns:
+	default:
+		return fmt.Errorf("invalid --dest-if-exists value %q: must be %s or %s", ifExists, db.IfExistsAppend, db.IfExistsAppendMissingColumns)
+	}
+
 	copier := db.NewCopier(sourceDB, destDB, tableName, batchSize)
+	defer startReport("copy", copier)(&err)
+	defer startManifest(copier)(&err)
+	var counts []db.RowCount
+	if jsonOutput() {
+		copier.Output = io.Discard
+		start := time.Now()
+		defer func() {
+			result := copyResult{commandResult: newCommandResult("copy", start, err), Stats: copier.Stats(), Counts: counts}
+			if writeErr := writeJSON(result); err == nil {
+				err = writeErr
+			}
+		}()
+	}
+	copier.OnMissingTable = onMissing
+	copier.IfExists = ifExists
+	copier.Query = query
+	copier.DestTable = destTable
+	copier.DestPrefix = destPrefix
+	copier.DestSuffix = destSuffix
+	copier.TypeOverrides = overrides
+	if copier.TypeRules, err = readTypeMap(); err != nil {
+		return err
+	}
+	copier.DestDialect = destDialect
+	copier.RateLimit = rateLimit
+	copier.NoChecks = noChecks
+	copier.NoTriggers = noTriggers
+	copier.DDLOut = ddlOut
+	copier.SchemaOnly = schemaOnly
+	copier.NoTransaction = noTx
+	copier.CopySequences = copySeqs
+	copier.Workers = workers
+	copier.MaxBatchBytes = maxBatchBytes
+	copier.AdaptiveBatch = adaptiveBatch
+	copier.MinBatch = minBatch
+	copier.MaxBatch = maxBatch
+	copier.BatchLatency = batchLatency
+	copier.DestTablespace = destTablespace
+	copier.DestTableOptions = destTableOpts
+	copier.DestOwner = destOwner
+	copier.Vacuum = vacuum
+
+	if copier.PreSQL, err = readSQLArg("--pre-sql", preSQL); err != nil {
+		return err
+	}
+	if copier.PostSQL, err = readSQLArg("--post-sql", postSQL); err != nil {
+		return err
+	}
+	copier.PostSQLAlways = postSQLAlways
+	copier.FailOnEmpty = failOnEmpty
+	copier.Strict = strict
+	copier.Heartbeat = heartbeat
+	copier.ProgressInterval = progressEvery
+	copier.AtomicSwap = atomicSwap
+	copier.SchemaCache = schemaCache
+	copier.CreateDestDB = createDestDB
+	copier.DestReadonlyCheck = readonlyCheck
+	copier.TempTable = tempTable
+	copier.SetNow = setNow
+	copier.Defaults = nullDefaults
+	copier.Lookups = lookupTables
+	copier.LookupStrict = lookupStrict
+	copier.SkipUnchanged = skipUnchanged && !force
+	copier.SourceQueryTimeout = sourceTimeout
+	copier.Paginate = paginate
+	copier.KeyColumn = keyColumn
+	copier.DestPrimaryKey = destPK
+	copier.CompareExisting = compareSchema
+	copier.ForceIncompatible = force
+	copier.FetchSize = fetchSize
+	copier.WireCompress = wireCompress
+	copier.CopyStorageParams = storageParams
+	copier.Coerce = coerce
+	copier.RedisTTL = redisTTL
+	copier.ColumnsCase = columnsCase
+	if len(copyColumns) > 0 {
+		copier.Columns = map[string][]string{tableName: copyColumns}
+	}
+	copier.HeaderMap = headers
+	copier.Verify = verifyMode
+	copier.VerifySampleSize = verifySize
+	copier.DestCollation = destCollation
+	copier.PreserveCollation = keepCollation
+	copier.SkipErrors = skipErrors
+	copier.MaxErrors = maxErrors
+	copier.OnUniqueViolation = onViolation
+	copier.InsertMethod = insertMethod
+	copier.CopyNull = copyNull
+	copier.TimeFormat = timeFormat
+	copier.SourceSchemas = sourceSchemas
+	copier.SchemaMap = schemas
+	copier.Distinct = distinct
+	copier.DistinctOn = distinctOn
+	copier.TimeColumn = timeColumn
+	copier.ReadConsistency = consistency
+	copier.Last = last
+	if err := setConnFlags(copier); err != nil {
+		return err
+	}
+	copier.GeometryAsWKT = geometryAsWKT
+	if err := applyConfig(copier); err != nil {
+		return err
+	}
+
+	if err := readPasswords(copier); err != nil {
+		return err
+	}
 
 	if err := copier.Connect(); err != nil {
 		return err
 	}
+	defer copier.Close()
 
-	if err := copier.Copy(); err != nil {
+	// Connect settles the destination type that the default depends on
+	if cmd.Flags().Changed("analyze") {
+		copier.Analyze = analyze
+	}
+
+	if countOnly {
+		counts, err = countSourceRows(copier)
 		return err
 	}
 
-	return nil
+	// Ctrl-C cancels the copy, which rolls back the open transaction
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ctx, cancel := withMaxDuration(ctx, copier)
+	defer cancel()
+
+	copyOnce := func() error {
+		return copier.RunWithHooks(func() error {
+			if interactive {
+				return tui.Run(ctx, copier)
+			}
+			if allTables {
+				return copier.CopyAllContext(ctx)
+			}
+			return copier.CopyContext(ctx)
+		})
+	}
+	if watchEvery > 0 {
+		copier.Incremental = true
+		if err = watchCopy(ctx, copier, copyOnce); err == nil {
+			return nil
+		}
+	} else {
+		err = copyOnce()
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return timeLimited(copier, err)
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf("interrupted by user: %w", err)
+	}
+	return err
+}
+
+// countSourceRows prints the row counts of the tables selected for copying
+func countSourceRows(copier *db.Copier) ([]db.RowCount, error) {
+	tables := []string{tableName}
+	if allTables {
+		var err error
+		if tables, err = copier.ListTables(); err != nil {
+			return nil, err
+		}
+	}
+	counts, err := copier.CountSourceRows(tables, approx)
+	if err != nil || jsonOutput() {
+		return counts, err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TABLE\tROWS\t")
+	for _, rc := range counts {
+		label := ""
+		if rc.Estimated {
+			label = " (estimate)"
+		}
+		fmt.Fprintf(w, "%s\t%d%s\t\n", rc.Table, rc.Rows, label)
+	}
+	return counts, w.Flush()
 }
 
 func runSample(cmd *cobra.Command, args []string) error {
-	return db.CreateSampleData(sampleDBPath, recordCount)
+	if sampleBatch < 1 {
+		return fmt.Errorf("--batch-size must be at least 1")
+	}
+	opts := db.SampleOptions{
+		Path:      sampleDBPath,
+		Records:   recordCount,
+		Seed:      sampleSeed,
+		BatchSize: sampleBatch,
+	}
+	if !jsonOutput() {
+		opts.Output = os.Stdout
+	}
+
+	// Ctrl-C stops between batches
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	start := time.Now()
+	created, err := db.CreateSampleData(ctx, opts)
+	if !jsonOutput() {
+		return err
+	}
+	result := struct {
+		commandResult
+		Database string `json:"database"`
+		Records  int    `json:"records"`
+	}{newCommandResult("sample", start, err), sampleDBPath, created.Rows}
+	if writeErr := writeJSON(result); err == nil {
+		err = writeErr
+	}
+	return err
+}
+
+// checkFetchSize validates --fetch-size
+func checkFetchSize() error {
+	switch {
+	case fetchSize < 0:
+		return fmt.Errorf("--fetch-size must not be negative")
+	case fetchSize > 0 && paginate:
+		return fmt.Errorf("--fetch-size cannot be used with --paginate, which reads each batch with a query of its own")
+	}
+	return nil
+}
+
+// checkAdaptiveBatch validates --adaptive-batch and its bounds
+func checkAdaptiveBatch() error {
+	if !adaptiveBatch {
+		return nil
+	}
+	switch {
+	case minBatch < 1:
+		return fmt.Errorf("--min-batch must be at least 1")
+	case maxBatch < minBatch:
+		return fmt.Errorf("--max-batch must be at least --min-batch")
+	case batchLatency <= 0:
+		return fmt.Errorf("--batch-latency must be positive")
+	case workers > 1:
+		return fmt.Errorf("--adaptive-batch cannot be used with --workers; concurrent inserts keep --batch-size")
+	}
+	return nil
+}
+
+// checkUniqueViolation validates --dest-unique-violation
+func checkUniqueViolation() error {
+	switch onViolation {
+	case db.UniqueViolationError:
+	case db.UniqueViolationSkipTable:
+		if skipErrors {
+			return fmt.Errorf("--dest-unique-violation=%s cannot be combined with --skip-errors, which already skips the violating rows", db.UniqueViolationSkipTable)
+		}
+	default:
+		return fmt.Errorf("invalid --dest-unique-violation value %q: must be %s or %s", onViolation, db.UniqueViolationError, db.UniqueViolationSkipTable)
+	}
+	return nil
+}
+
+// checkInsertMethod validates --insert-method and --pg-copy-null
+func checkInsertMethod() error {
+	switch insertMethod {
+	case db.InsertMultiValues, db.InsertPrepared, db.InsertCopy, db.InsertAuto:
+	default:
+		return fmt.Errorf("invalid --insert-method value %q: must be %s, %s, %s or %s", insertMethod, db.InsertMultiValues, db.InsertPrepared, db.InsertCopy, db.InsertAuto)
+	}
+	switch {
+	case copyNull != "" && insertMethod != db.InsertCopy && insertMethod != db.InsertAuto:
+		return fmt.Errorf("--pg-copy-null can only be used with --insert-method %s or %s", db.InsertCopy, db.InsertAuto)
+	case strings.ContainsAny(copyNull, "\r\n"):
+		return fmt.Errorf("--pg-copy-null cannot contain a line break")
+	}
+	return nil
+}
+
+// checkReadConsistency validates --read-consistency
+func checkReadConsistency() error {
+	switch consistency {
+	case "", db.ReadConsistencyRepeatableRead, db.ReadConsistencySerializable:
+		return nil
+	}
+	return fmt.Errorf("invalid --read-consistency value %q: must be %s or %s", consistency, db.ReadConsistencyRepeatableRead, db.ReadConsistencySerializable)
+}
+
+// readTypeMap reads the --type-map-file rules, if one is given
+func readTypeMap() ([]db.TypeRule, error) {
+	if typeMapFile == "" {
+		return nil, nil
+	}
+	rules, err := db.ReadTypeMapFile(typeMapFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read --type-map-file: %w", err)
+	}
+	return rules, nil
+}
+
+// parseTypeOverrides parses column=TYPE pairs into a map
+func parseTypeOverrides(values []string) (map[string]string, error) {
+	overrides := make(map[string]string)
+	for _, value := range values {
+		column, colType, ok := strings.Cut(value, "=")
+		if !ok || column == "" || colType == "" {
+			return nil, fmt.Errorf("invalid --type-override %q: expected column=TYPE", value)
+		}
+		overrides[column] = colType
+	}
+	return overrides, nil
+}
+
+// parseDefaults parses column=value pairs into a map. The value may be empty.
+func parseDefaults(values []string) (map[string]string, error) {
+	defaults := make(map[string]string)
+	for _, value := range values {
+		column, def, ok := strings.Cut(value, "=")
+		if !ok || column == "" {
+			return nil, fmt.Errorf("invalid --default %q: expected column=value", value)
+		}
+		defaults[column] = def
+	}
+	return defaults, nil
+}
+
+// parseHeaderMap parses the column=Header pairs of --header-map into a map
+func parseHeaderMap(values []string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, value := range values {
+		column, header, ok := strings.Cut(value, "=")
+		if !ok || column == "" || header == "" {
+			return nil, fmt.Errorf("invalid --header-map %q: expected column=Header", value)
+		}
+		headers[column] = header
+	}
+	return headers, nil
+}
+
+// parseLookups parses column=file.csv pairs and reads each lookup file
+func parseLookups(values []string) (map[string]map[string]string, error) {
+	tables := make(map[string]map[string]string)
+	for _, value := range values {
+		column, path, ok := strings.Cut(value, "=")
+		if !ok || column == "" || path == "" {
+			return nil, fmt.Errorf("invalid --lookup %q: expected column=file.csv", value)
+		}
+		lookup, err := db.ReadLookupFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read --lookup file: %w", err)
+		}
+		tables[column] = lookup
+	}
+	return tables, nil
+}
+
+// parseSchemaMap checks the --source-schema values and parses the
+// source=dest pairs of --schema-map into a map
+func parseSchemaMap(sources, values []string) (map[string]string, error) {
+	for _, schema := range sources {
+		if schema == db.AllSchemas && len(sources) > 1 {
+			return nil, fmt.Errorf("--source-schema %s cannot be combined with other schemas", db.AllSchemas)
+		}
+	}
+	schemas := make(map[string]string)
+	for _, value := range values {
+		source, dest, ok := strings.Cut(value, "=")
+		if !ok || source == "" || dest == "" {
+			return nil, fmt.Errorf("invalid --schema-map %q: expected source=dest", value)
+		}
+		schemas[source] = dest
+	}
+	return schemas, nil
+}
+
+// readSQLArg returns a SQL flag value, reading it from a file when it starts with @
+func readSQLArg(flag, value string) (string, error) {
+	path, ok := strings.CutPrefix(value, "@")
+	if !ok {
+		return value, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s file: %w", flag, err)
+	}
+	return string(data), nil
+}
+
+// readPasswords fills in the copier's passwords from stdin or an interactive prompt
+func readPasswords(copier *db.Copier) error {
+	stdin := bufio.NewReader(os.Stdin)
+
+	var err error
+	if sourcePasswordStdin {
+		if copier.SourcePassword, err = readPasswordLine(stdin); err != nil {
+			return fmt.Errorf("failed to read source password from stdin: %w", err)
+		}
+	} else if passwordPrompt && needsPassword(sourceDB, sourceParams) {
+		if copier.SourcePassword, err = promptPassword("Source database password: "); err != nil {
+			return err
+		}
+	}
+
+	if destPasswordStdin {
+		if copier.DestPassword, err = readPasswordLine(stdin); err != nil {
+			return fmt.Errorf("failed to read destination password from stdin: %w", err)
+		}
+	} else if passwordPrompt && needsPassword(destDB, destParams) {
+		if copier.DestPassword, err = promptPassword("Destination database password: "); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readPasswordLine reads a single line from r without its line ending
+func readPasswordLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// promptPassword asks for a password on the terminal without echoing it
+func promptPassword(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("cannot prompt for password: stdin is not a terminal")
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	password, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+	return string(password), nil
+}
+
+func runBenchmark(cmd *cobra.Command, args []string) error {
+	source := benchSource
+	if source == "" {
+		tmpDir, err := os.MkdirTemp("", "dbcopy-sample-")
+		if err != nil {
+			return fmt.Errorf("failed to create temporary directory: %w", err)
+		}
+		defer os.RemoveAll(tmpDir)
+
+		source = filepath.Join(tmpDir, "sample.db")
+		opts := db.SampleOptions{Path: source, Records: benchCount, Seed: sampleSeed}
+		if !jsonOutput() {
+			opts.Output = os.Stdout
+		}
+		if _, err := db.CreateSampleData(cmd.Context(), opts); err != nil {
+			return err
+		}
+	}
+
+	start := time.Now()
+	results, err := db.Benchmark(source, benchDest, benchTable, benchBatchSizes, benchWorkers)
+	if jsonOutput() {
+		result := struct {
+			commandResult
+			Results []benchmarkResult `json:"results"`
+		}{commandResult: newCommandResult("benchmark", start, err)}
+		for _, r := range results {
+			result.Results = append(result.Results, benchmarkResult{
+				BatchSize:       r.BatchSize,
+				Workers:         r.Workers,
+				Rows:            r.Rows,
+				DurationSeconds: r.Duration.Seconds(),
+				RowsPerSec:      r.RowsPerSec,
+			})
+		}
+		if writeErr := writeJSON(result); err == nil {
+			err = writeErr
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "BATCH SIZE\tWORKERS\tROWS\tDURATION\tROWS/SEC\t")
+	for _, r := range results {
+		fmt.Fprintf(w, "%d\t%d\t%d\t%s\t%.0f\t\n", r.BatchSize, r.Workers, r.Rows, r.Duration.Round(time.Millisecond), r.RowsPerSec)
+	}
+	return w.Flush()
 }
 
 func SetLogger(logger *zap.Logger) {
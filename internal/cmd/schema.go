@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+
+	"db-copy/internal/db"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	schemaOut string
+	schemaIn  string
+)
+
+// schemaCmd groups the commands that move a table's structure without its rows
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Export a table's structure to a file or create a table from one",
+}
+
+// schemaExportCmd represents the schema export command
+var schemaExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Write the structure of a source table to a JSON file",
+	Long: `Writes the columns, indexes and unique, foreign key and check constraints of
+a SQLite or PostgreSQL table to a JSON file, with the column types the source
+database uses. The file can be reviewed or versioned and later applied with
+schema apply. No rows are read.`,
+	RunE: runSchemaExport,
+}
+
+// schemaApplyCmd represents the schema apply command
+var schemaApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Create a destination table from a JSON schema file",
+	Long: `Creates the table described by a file written by schema export, converting the
+column types for the destination database. An existing table is left as it is.`,
+	RunE: runSchemaApply,
+}
+
+func runSchemaExport(cmd *cobra.Command, args []string) error {
+	if sourceDB == "" {
+		return fmt.Errorf("--source is required")
+	}
+	if tableName == "" {
+		return fmt.Errorf("--table is required")
+	}
+	if schemaOut == "" {
+		return fmt.Errorf("--out is required")
+	}
+
+	copier := db.NewCopier(sourceDB, "", tableName, 1)
+	if err := readPasswords(copier); err != nil {
+		return err
+	}
+	if err := copier.ConnectSource(); err != nil {
+		return err
+	}
+	defer copier.Close()
+
+	schema, err := copier.ExportSchema()
+	if err != nil {
+		return err
+	}
+	if err := db.WriteSchemaFile(schemaOut, schema); err != nil {
+		return fmt.Errorf("failed to write schema file: %w", err)
+	}
+	fmt.Printf("Exported the schema of table %s to %s\n", tableName, schemaOut)
+	return nil
+}
+
+func runSchemaApply(cmd *cobra.Command, args []string) error {
+	if destDB == "" {
+		return fmt.Errorf("--dest is required")
+	}
+	if schemaIn == "" {
+		return fmt.Errorf("--in is required")
+	}
+
+	schema, err := db.ReadSchemaFile(schemaIn)
+	if err != nil {
+		return err
+	}
+
+	copier := db.NewCopier("", destDB, schema.Table, 1)
+	copier.DestTable = destTable
+	if err := readPasswords(copier); err != nil {
+		return err
+	}
+	if err := copier.ConnectDest(); err != nil {
+		return err
+	}
+	defer copier.Close()
+
+	return copier.ApplySchema(schema)
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"db-copy/internal/db"
+
+	"go.uber.org/zap"
+)
+
+var (
+	watchEvery time.Duration
+	failFast   bool
+)
+
+// checkWatch validates --watch and --fail-fast
+func checkWatch() error {
+	switch {
+	case watchEvery < 0:
+		return fmt.Errorf("--watch must not be negative")
+	case watchEvery == 0 && failFast:
+		return fmt.Errorf("--fail-fast can only be used with --watch")
+	case watchEvery == 0:
+		return nil
+	case query != "":
+		return fmt.Errorf("--watch cannot be used with --query, which has no key to keep a watermark on")
+	case interactive:
+		return fmt.Errorf("--watch cannot be used with --interactive")
+	case atomicSwap:
+		return fmt.Errorf("--watch cannot be used with --atomic-swap, which copies every row into a new table")
+	}
+	return nil
+}
+
+// watchCopy runs copyOnce every --watch interval until ctx is cancelled, for
+// a simple form of continuous replication. Each cycle is an incremental copy
+// that reads only the rows above the watermark. A failed cycle is logged and
+// the next one runs, unless --fail-fast is set.
+func watchCopy(ctx context.Context, copier *db.Copier, copyOnce func() error) error {
+	for cycle := 1; ; cycle++ {
+		before := copier.Stats().RowsCopied
+		err := copyOnce()
+		if ctx.Err() != nil {
+			return err
+		}
+		if err != nil {
+			if failFast {
+				return fmt.Errorf("watch cycle %d failed: %w", cycle, err)
+			}
+			zap.L().Error("Watch cycle failed; retrying at the next interval", zap.Int("cycle", cycle), zap.Error(err))
+		} else {
+			zap.L().Info("Watch cycle finished", zap.Int("cycle", cycle), zap.Int64("rows_copied", copier.Stats().RowsCopied-before))
+		}
+
+		select {
+		case <-ctx.Done():
+			// Stopping between cycles loses nothing
+			if ctx.Err() == context.DeadlineExceeded {
+				return ctx.Err()
+			}
+			return nil
+		case <-time.After(watchEvery):
+		}
+	}
+}
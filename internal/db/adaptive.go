@@ -0,0 +1,142 @@
+package db
+
+import (
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Defaults for AdaptiveBatch
+const (
+	DefaultMinBatch     = 100
+	DefaultMaxBatch     = 10000
+	DefaultBatchLatency = time.Second
+)
+
+// batchTuner picks the size of each insert for AdaptiveBatch. The size starts
+// at min and doubles while inserts take at most half the latency target, so
+// that the doubled insert should still meet it. It halves when an insert takes
+// longer than the target or fails, and always stays between min and max. A
+// size that was too slow is not tried again, so the size settles instead of
+// swinging between a fast size and a slow one.
+type batchTuner struct {
+	table   string
+	size    int
+	min     int
+	max     int
+	tooSlow int
+	target  time.Duration
+}
+
+// newBatchTuner returns the tuner for the current table, or nil without
+// AdaptiveBatch. The largest size is capped by the bind parameter limit for
+// rows of columnCount columns.
+func (c *Copier) newBatchTuner(columnCount int) *batchTuner {
+	if !c.AdaptiveBatch {
+		return nil
+	}
+	if c.Workers > 1 {
+		c.noticeOnce("adaptive-workers", "--adaptive-batch is ignored with several workers; batches keep --batch-size")
+		return nil
+	}
+	t := &batchTuner{
+		table:  c.destTableName(),
+		min:    c.MinBatch,
+		max:    c.MaxBatch,
+		target: c.BatchLatency,
+	}
+	if t.min < 1 {
+		t.min = DefaultMinBatch
+	}
+	if t.max < 1 {
+		t.max = DefaultMaxBatch
+	}
+	if t.target <= 0 {
+		t.target = DefaultBatchLatency
+	}
+	t.max = min(t.max, c.maxBatchRows(columnCount))
+	t.min = min(t.min, t.max)
+	t.size = t.min
+	zap.L().Info("Adaptive batch size",
+		zap.String("table", t.table),
+		zap.Int("batch_size", t.size),
+		zap.Int("max_batch", t.max),
+		zap.Duration("latency_target", t.target),
+	)
+	return t
+}
+
+// observe adjusts the size after an insert took elapsed
+func (t *batchTuner) observe(elapsed time.Duration) {
+	switch {
+	case elapsed > t.target:
+		t.tooSlow = t.size
+		t.resize(t.size/2, elapsed)
+	case 2*elapsed <= t.target && (t.tooSlow == 0 || t.size*2 < t.tooSlow):
+		t.resize(t.size*2, elapsed)
+	}
+}
+
+// backOff halves the size after a failed insert and reports whether it is
+// worth retrying, which it is not once the size is already at its minimum
+func (t *batchTuner) backOff() bool {
+	if t.size <= t.min {
+		return false
+	}
+	t.resize(t.size/2, 0)
+	return true
+}
+
+func (t *batchTuner) resize(size int, elapsed time.Duration) {
+	size = max(t.min, min(size, t.max))
+	if size == t.size {
+		return
+	}
+	t.size = size
+	fields := []zap.Field{zap.String("table", t.table), zap.Int("batch_size", size)}
+	if elapsed > 0 {
+		fields = append(fields, zap.Duration("last_batch", elapsed.Round(time.Millisecond)))
+	} else {
+		fields = append(fields, zap.String("reason", "insert failed"))
+	}
+	zap.L().Info("Adjusted batch size", fields...)
+}
+
+// writeBatchAdaptive writes a batch in inserts of the tuner's size, which is
+// adjusted after each one. An insert that fails is retried at half the size
+// until the smallest size fails too. Inside a transaction each insert is
+// wrapped in a savepoint, so that a failed one can be retried.
+func (c *Copier) writeBatchAdaptive(w *tableWriter, batch []map[string]interface{}, t *batchTuner) (int, error) {
+	written := 0
+	for len(batch) > 0 {
+		n := min(t.size, len(batch))
+		if err := w.savepoint("db_copy_adaptive"); err != nil {
+			return written, err
+		}
+		start := time.Now()
+		stored, err := c.writeBatch(w, batch[:n])
+		if err != nil {
+			// Errors of the error policies are final at any size
+			var uniqueErr *ErrUniqueViolation
+			var tooMany *ErrTooManyErrors
+			if errors.As(err, &uniqueErr) || errors.As(err, &tooMany) {
+				return written + stored, err
+			}
+			if rollbackErr := w.rollbackTo("db_copy_adaptive"); rollbackErr != nil {
+				return written, rollbackErr
+			}
+			if t.backOff() {
+				continue
+			}
+			return written, err
+		}
+		if err := w.release("db_copy_adaptive"); err != nil {
+			return written, err
+		}
+		t.observe(time.Since(start))
+		written += stored
+		batch = batch[n:]
+	}
+	return written, nil
+}
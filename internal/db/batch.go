@@ -0,0 +1,143 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Maximum number of bind parameters in a single statement
+const (
+	postgresMaxParameters = 65535
+	sqliteMaxParameters   = 32766
+)
+
+// tableBatchSize returns the batch size configured for the current table,
+// falling back to BatchSize. A key matches the table as written, qualified
+// with the default schema (users and public.users), or by its table name
+// alone when the table is in another schema.
+func (c *Copier) tableBatchSize() int {
+	if size := c.TableBatchSizes[c.TableName]; size > 0 {
+		return size
+	}
+	for key, size := range c.TableBatchSizes {
+		if size > 0 && c.sameTable(key, c.TableName) {
+			return size
+		}
+	}
+	if _, table := splitSchema(c.TableName); table != c.TableName {
+		if size := c.TableBatchSizes[table]; size > 0 {
+			return size
+		}
+	}
+	return c.BatchSize
+}
+
+// safeBatchSize returns the batch size capped so that rows × columns stays within
+// the destination's bind parameter limit. It is never less than one row.
+func (c *Copier) safeBatchSize(columnCount int) int {
+	batchSize := c.tableBatchSize()
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	if columnCount == 0 {
+		return batchSize
+	}
+
+	maxRows := c.maxBatchRows(columnCount)
+	if batchSize > maxRows {
+		zap.L().Warn("Reducing batch size to stay under the bind parameter limit",
+			zap.String("table", c.destTableName()),
+			zap.Int("columns", columnCount),
+			zap.Int("requested", batchSize),
+			zap.Int("adjusted", maxRows),
+		)
+		return maxRows
+	}
+	return batchSize
+}
+
+// maxBatchRows returns the most rows of columnCount columns that fit in one
+// insert under the destination's bind parameter limit. It is never less than
+// one row.
+func (c *Copier) maxBatchRows(columnCount int) int {
+	limit := postgresMaxParameters
+	if c.destDBType == DBTypeSQLite {
+		limit = sqliteMaxParameters
+	}
+	if columnCount < 1 {
+		return limit
+	}
+	maxRows := limit / columnCount
+	if maxRows < 1 {
+		maxRows = 1
+	}
+	return maxRows
+}
+
+// batchBounds returns the [start, end) bounds of consecutive batches of at most
+// size records out of total. The last batch holds the remainder, so no record
+// is dropped when total is not a multiple of size, and a size at or above total
+// gives a single batch. A size below one is treated as one.
+func batchBounds(total, size int) [][2]int {
+	if size < 1 {
+		size = 1
+	}
+	var bounds [][2]int
+	for start := 0; start < total; start += size {
+		end := total
+		if size < total-start {
+			end = start + size
+		}
+		bounds = append(bounds, [2]int{start, end})
+	}
+	return bounds
+}
+
+// estimateRowSize returns a rough estimate of the serialized size of a row in bytes
+func estimateRowSize(record map[string]interface{}) int {
+	size := 0
+	for key, value := range record {
+		size += len(key)
+		switch v := value.(type) {
+		case nil:
+			size += 4
+		case string:
+			size += len(v)
+		case []byte:
+			size += len(v)
+		case bool:
+			size += 1
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+			size += 8
+		case time.Time:
+			size += 8
+		default:
+			size += len(fmt.Sprint(v))
+		}
+	}
+	return size
+}
+
+// splitBatchByBytes splits a batch into consecutive chunks whose estimated size stays
+// within MaxBatchBytes. A single row larger than the cap forms a chunk of its own.
+func (c *Copier) splitBatchByBytes(batch []map[string]interface{}) [][]map[string]interface{} {
+	if c.MaxBatchBytes <= 0 {
+		return [][]map[string]interface{}{batch}
+	}
+
+	var chunks [][]map[string]interface{}
+	start, chunkSize := 0, 0
+	for i, record := range batch {
+		rowSize := estimateRowSize(record)
+		if i > start && chunkSize+rowSize > c.MaxBatchBytes {
+			chunks = append(chunks, batch[start:i])
+			start, chunkSize = i, 0
+		}
+		chunkSize += rowSize
+	}
+	chunks = append(chunks, batch[start:])
+
+	return chunks
+}
@@ -0,0 +1,210 @@
+package db
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// newTestCopier returns a connected copier of table from a new SQLite file,
+// set up with the given statements, to an empty SQLite file. Both live in a
+// temporary directory of the test.
+func newTestCopier(t *testing.T, table string, batchSize int, setup ...string) *Copier {
+	t.Helper()
+	dir := t.TempDir()
+	source := filepath.Join(dir, "source.db")
+	execSQLite(t, source, setup...)
+
+	c := NewCopier(source, filepath.Join(dir, "dest.db"), table, batchSize)
+	c.Output = io.Discard
+	if err := c.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+// execSQLite runs statements on a SQLite file through a connection of its own
+func execSQLite(t *testing.T, path string, statements ...string) {
+	t.Helper()
+	conn, err := gorm.Open(sqlite.Open(path), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open %s: %v", path, err)
+	}
+	sqlDB, err := conn.DB()
+	if err != nil {
+		t.Fatalf("open %s: %v", path, err)
+	}
+	defer sqlDB.Close()
+	for _, stmt := range statements {
+		if err := conn.Exec(stmt).Error; err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+}
+
+// countRows returns the number of rows of a table
+func countRows(t *testing.T, conn *gorm.DB, table string) int64 {
+	t.Helper()
+	var count int64
+	if err := conn.Table(table).Count(&count).Error; err != nil {
+		t.Fatalf("count %s: %v", table, err)
+	}
+	return count
+}
+
+// seedItems returns the statements that create an items table of n rows
+func seedItems(n int) []string {
+	return []string{
+		"CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT NOT NULL)",
+		fmt.Sprintf(`INSERT INTO items (id, name)
+			WITH RECURSIVE seq(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM seq WHERE i < %d)
+			SELECT i, 'item ' || i FROM seq`, n),
+	}
+}
+
+func TestBatchBounds(t *testing.T) {
+	tests := []struct {
+		total, size int
+		want        [][2]int
+	}{
+		{0, 10, nil},
+		{1, 10, [][2]int{{0, 1}}},
+		{10, 10, [][2]int{{0, 10}}},
+		{10, 9, [][2]int{{0, 9}, {9, 10}}},
+		{10, 11, [][2]int{{0, 10}}},
+		{10, 3, [][2]int{{0, 3}, {3, 6}, {6, 9}, {9, 10}}},
+		{3, 0, [][2]int{{0, 1}, {1, 2}, {2, 3}}},
+	}
+	for _, tt := range tests {
+		if got := batchBounds(tt.total, tt.size); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("batchBounds(%d, %d) = %v, want %v", tt.total, tt.size, got, tt.want)
+		}
+	}
+}
+
+func TestSafeBatchSize(t *testing.T) {
+	tests := []struct {
+		dbType    DBType
+		batchSize int
+		columns   int
+		want      int
+	}{
+		{DBTypePostgres, 1000, 70, 936},
+		{DBTypeSQLite, 1000, 70, 468},
+		{DBTypePostgres, 500, 70, 500},
+		{DBTypeSQLite, 468, 70, 468},
+		{DBTypeSQLite, 469, 70, 468},
+		{DBTypePostgres, 100000, 1, 65535},
+		{DBTypeSQLite, 10, 40000, 1},
+		{DBTypeSQLite, 0, 70, 1},
+		{DBTypePostgres, 1000, 0, 1000},
+	}
+	for _, tt := range tests {
+		c := &Copier{BatchSize: tt.batchSize, destDBType: tt.dbType}
+		if got := c.safeBatchSize(tt.columns); got != tt.want {
+			t.Errorf("%v: safeBatchSize(%d) with batch size %d = %d, want %d", tt.dbType, tt.columns, tt.batchSize, got, tt.want)
+		}
+	}
+}
+
+func TestTableBatchSize(t *testing.T) {
+	sizes := map[string]int{"users": 10, "sales.orders": 20, "orders": 30, "audit.events": 40}
+	tests := []struct {
+		dbType DBType
+		table  string
+		want   int
+	}{
+		{DBTypePostgres, "users", 10},
+		{DBTypePostgres, "public.users", 10},
+		{DBTypePostgres, "crm.users", 10},
+		{DBTypePostgres, "sales.orders", 20},
+		{DBTypePostgres, "orders", 30},
+		{DBTypePostgres, "events", 1000},
+		{DBTypePostgres, "audit.events", 40},
+		{DBTypePostgres, "accounts", 1000},
+		{DBTypeSQLite, "main.users", 10},
+	}
+	for _, tt := range tests {
+		c := &Copier{BatchSize: 1000, TableBatchSizes: sizes, TableName: tt.table, sourceDBType: tt.dbType}
+		if got := c.tableBatchSize(); got != tt.want {
+			t.Errorf("tableBatchSize() for %s = %d, want %d", tt.table, got, tt.want)
+		}
+	}
+}
+
+func TestCopyWideTable(t *testing.T) {
+	const columns, rows = 70, 500
+	names := make([]string, columns)
+	values := make([]string, columns)
+	for i := range names {
+		names[i] = fmt.Sprintf("c%d", i+1)
+		values[i] = fmt.Sprintf("i * %d", i+1)
+	}
+	setup := []string{
+		fmt.Sprintf("CREATE TABLE wide (%s INTEGER PRIMARY KEY, %s INTEGER)", names[0], strings.Join(names[1:], " INTEGER, ")),
+		fmt.Sprintf(`INSERT INTO wide (%s)
+			WITH RECURSIVE seq(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM seq WHERE i < %d)
+			SELECT %s FROM seq`, strings.Join(names, ", "), rows, strings.Join(values, ", ")),
+	}
+
+	// 1000 rows of 70 columns would need 70000 bind parameters per insert
+	c := newTestCopier(t, "wide", 1000, setup...)
+	if err := c.Copy(); err != nil {
+		t.Fatalf("Copy: %v", err)
+	}
+	if got := countRows(t, c.destConn, "wide"); got != rows {
+		t.Errorf("destination has %d rows, want %d", got, rows)
+	}
+	var last int
+	if err := c.destConn.Raw("SELECT c70 FROM wide WHERE c1 = ?", rows).Scan(&last).Error; err != nil {
+		t.Fatalf("read last row: %v", err)
+	}
+	if last != rows*columns {
+		t.Errorf("last row has c70 = %d, want %d", last, rows*columns)
+	}
+}
+
+func TestCopyBatchSizeAroundRowCount(t *testing.T) {
+	const rows = 25
+	for _, batchSize := range []int{rows - 1, rows, rows + 1, 10 * rows} {
+		t.Run(fmt.Sprintf("batch size %d", batchSize), func(t *testing.T) {
+			c := newTestCopier(t, "items", batchSize, seedItems(rows)...)
+			if err := c.Copy(); err != nil {
+				t.Fatalf("Copy: %v", err)
+			}
+			if got := countRows(t, c.destConn, "items"); got != rows {
+				t.Errorf("destination has %d rows, want %d", got, rows)
+			}
+			var last string
+			if err := c.destConn.Raw("SELECT name FROM items WHERE id = ?", rows).Scan(&last).Error; err != nil {
+				t.Fatalf("read last row: %v", err)
+			}
+			if last != fmt.Sprintf("item %d", rows) {
+				t.Errorf("last row has name %q, want %q", last, fmt.Sprintf("item %d", rows))
+			}
+		})
+	}
+}
+
+func TestCopyWithWorkers(t *testing.T) {
+	const rows = 103
+	for _, workers := range []int{1, 2, 4} {
+		t.Run(fmt.Sprintf("%d workers", workers), func(t *testing.T) {
+			c := newTestCopier(t, "items", 10, seedItems(rows)...)
+			c.Workers = workers
+			if err := c.Copy(); err != nil {
+				t.Fatalf("Copy: %v", err)
+			}
+			if got := countRows(t, c.destConn, "items"); got != rows {
+				t.Errorf("destination has %d rows, want %d", got, rows)
+			}
+		})
+	}
+}
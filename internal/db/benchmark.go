@@ -0,0 +1,82 @@
+package db
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// BenchmarkResult holds the throughput measured for one batch size and worker count
+type BenchmarkResult struct {
+	BatchSize  int
+	Workers    int
+	Rows       int64
+	Duration   time.Duration
+	RowsPerSec float64
+}
+
+// Benchmark copies a table once for every combination of batch size and worker count
+// and measures the throughput of each run. When destDB is empty every run writes to a
+// fresh temporary SQLite file; otherwise the table is dropped from destDB before each run,
+// so destDB must be a throwaway database.
+func Benchmark(sourceDB, destDB, tableName string, batchSizes, workers []int) ([]BenchmarkResult, error) {
+	tmpDir, err := os.MkdirTemp("", "dbcopy-benchmark-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	var results []BenchmarkResult
+	for _, batchSize := range batchSizes {
+		for _, workerCount := range workers {
+			dest := destDB
+			if dest == "" {
+				dest = filepath.Join(tmpDir, fmt.Sprintf("dest-%d-%d.db", batchSize, workerCount))
+			}
+
+			result, err := benchmarkRun(sourceDB, dest, tableName, batchSize, workerCount)
+			if err != nil {
+				return results, fmt.Errorf("benchmark with batch size %d and %d workers failed: %w", batchSize, workerCount, err)
+			}
+			results = append(results, result)
+		}
+	}
+
+	return results, nil
+}
+
+// benchmarkRun performs a single timed copy
+func benchmarkRun(sourceDB, destDB, tableName string, batchSize, workers int) (BenchmarkResult, error) {
+	copier := NewCopier(sourceDB, destDB, tableName, batchSize)
+	copier.Workers = workers
+	copier.silent = true
+
+	if err := copier.Connect(); err != nil {
+		return BenchmarkResult{}, err
+	}
+	defer copier.Close()
+
+	if err := copier.destConn.Migrator().DropTable(tableName); err != nil {
+		return BenchmarkResult{}, fmt.Errorf("failed to drop destination table: %w", err)
+	}
+
+	start := time.Now()
+	if err := copier.Copy(); err != nil {
+		return BenchmarkResult{}, err
+	}
+	duration := time.Since(start)
+
+	var rows int64
+	if err := copier.destConn.Table(tableName).Count(&rows).Error; err != nil {
+		return BenchmarkResult{}, fmt.Errorf("failed to count destination rows: %w", err)
+	}
+
+	return BenchmarkResult{
+		BatchSize:  batchSize,
+		Workers:    workers,
+		Rows:       rows,
+		Duration:   duration,
+		RowsPerSec: float64(rows) / duration.Seconds(),
+	}, nil
+}
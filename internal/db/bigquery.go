@@ -0,0 +1,180 @@
+//go:build bigquery
+
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+
+	"cloud.google.com/go/bigquery"
+	"cloud.google.com/go/civil"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+// bigQueryDataset reads the tables of one BigQuery dataset
+type bigQueryDataset struct {
+	client  *bigquery.Client
+	dataset *bigquery.Dataset
+}
+
+// openBigQuery connects to the dataset of a bigquery://project/dataset
+// connection string. Without a credentials parameter the application default
+// credentials are used; with an endpoint, e.g. of an emulator, none are.
+func openBigQuery(dsn string) (bigQuerySource, error) {
+	project, dataset, credentials, endpoint, err := parseBigQueryDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	var opts []option.ClientOption
+	switch {
+	case endpoint != "":
+		opts = append(opts, option.WithEndpoint(endpoint), option.WithoutAuthentication())
+	case credentials != "":
+		opts = append(opts, option.WithCredentialsFile(credentials))
+	}
+	client, err := bigquery.NewClient(context.Background(), project, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create BigQuery client: %w", err)
+	}
+	return &bigQueryDataset{client: client, dataset: client.Dataset(dataset)}, nil
+}
+
+func (d *bigQueryDataset) tables(ctx context.Context) ([]string, error) {
+	var tables []string
+	it := d.dataset.Tables(ctx)
+	for {
+		table, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, table.TableID)
+	}
+	sort.Strings(tables)
+	return tables, nil
+}
+
+func (d *bigQueryDataset) fields(ctx context.Context, table string) ([]bigQueryField, error) {
+	metadata, err := d.dataset.Table(table).Metadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	fields := make([]bigQueryField, len(metadata.Schema))
+	for i, field := range metadata.Schema {
+		fields[i] = bigQueryField{
+			Name:     field.Name,
+			Type:     string(field.Type),
+			Repeated: field.Repeated,
+			Required: field.Required,
+		}
+	}
+	return fields, nil
+}
+
+func (d *bigQueryDataset) rows(ctx context.Context, table string, pageSize int) (bigQueryRows, error) {
+	it := d.dataset.Table(table).Read(ctx)
+	it.PageInfo().MaxSize = pageSize
+	return &bigQueryTableRows{it: it}, nil
+}
+
+func (d *bigQueryDataset) Close() error {
+	return d.client.Close()
+}
+
+// bigQueryTableRows converts the rows of a table iterator into records
+type bigQueryTableRows struct {
+	it *bigquery.RowIterator
+}
+
+func (r *bigQueryTableRows) next() (map[string]interface{}, error) {
+	var row []bigquery.Value
+	err := r.it.Next(&row)
+	if err == iterator.Done {
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, err
+	}
+	record := make(map[string]interface{}, len(row))
+	for i, field := range r.it.Schema {
+		value, err := bigQueryValue(field, row[i])
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", field.Name, err)
+		}
+		record[field.Name] = value
+	}
+	return record, nil
+}
+
+// bigQueryValue converts a BigQuery value into one the destination drivers
+// accept: dates and times as text, NUMERIC as an exact decimal string, and
+// arrays and records as JSON
+func bigQueryValue(field *bigquery.FieldSchema, value bigquery.Value) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	if field.Repeated || field.Type == bigquery.RecordFieldType {
+		data, err := json.Marshal(bigQueryJSON(field, value))
+		if err != nil {
+			return nil, err
+		}
+		return string(data), nil
+	}
+	return bigQueryScalar(field, value), nil
+}
+
+// bigQueryScalar converts a single non-NULL value
+func bigQueryScalar(field *bigquery.FieldSchema, value bigquery.Value) interface{} {
+	switch v := value.(type) {
+	case *big.Rat:
+		if field.Type == bigquery.BigNumericFieldType {
+			return bigquery.BigNumericString(v)
+		}
+		return bigquery.NumericString(v)
+	case civil.Date:
+		return v.String()
+	case civil.Time:
+		return v.String()
+	case civil.DateTime:
+		return v.String()
+	case *bigquery.IntervalValue:
+		return v.String()
+	}
+	return value
+}
+
+// bigQueryJSON converts a repeated or record value into plain Go values for
+// JSON encoding, naming the fields of records
+func bigQueryJSON(field *bigquery.FieldSchema, value bigquery.Value) interface{} {
+	if value == nil {
+		return nil
+	}
+	if field.Repeated {
+		items, _ := value.([]bigquery.Value)
+		element := *field
+		element.Repeated = false
+		out := make([]interface{}, len(items))
+		for i, item := range items {
+			out[i] = bigQueryJSON(&element, item)
+		}
+		return out
+	}
+	if field.Type == bigquery.RecordFieldType {
+		values, _ := value.([]bigquery.Value)
+		out := make(map[string]interface{}, len(values))
+		for i, sub := range field.Schema {
+			if i < len(values) {
+				out[sub.Name] = bigQueryJSON(sub, values[i])
+			}
+		}
+		return out
+	}
+	return bigQueryScalar(field, value)
+}
@@ -0,0 +1,12 @@
+//go:build !bigquery
+
+package db
+
+import "errors"
+
+// openBigQuery reports that BigQuery support was not compiled in. The BigQuery
+// client pulls in a large dependency tree, so it is only included when
+// building with -tags bigquery.
+func openBigQuery(dsn string) (bigQuerySource, error) {
+	return nil, errors.New("BigQuery support is not included in this build; rebuild with -tags bigquery")
+}
@@ -0,0 +1,226 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// bigQueryField describes a column of a BigQuery table
+type bigQueryField struct {
+	Name     string
+	Type     string // BigQuery type, e.g. STRING or INTEGER
+	Repeated bool
+	Required bool
+}
+
+// bigQuerySource reads the tables of one BigQuery dataset. It is implemented
+// when building with -tags bigquery.
+type bigQuerySource interface {
+	tables(ctx context.Context) ([]string, error)
+	fields(ctx context.Context, table string) ([]bigQueryField, error)
+	rows(ctx context.Context, table string, pageSize int) (bigQueryRows, error)
+	Close() error
+}
+
+// bigQueryRows iterates over the rows of a BigQuery table, keyed by column
+// name. next returns io.EOF after the last row.
+type bigQueryRows interface {
+	next() (map[string]interface{}, error)
+}
+
+// isBigQueryDSN reports whether a connection string names a BigQuery
+// dataset, as bigquery://project/dataset
+func isBigQueryDSN(dsn string) bool {
+	return strings.HasPrefix(dsn, "bigquery://")
+}
+
+// parseBigQueryDSN returns the project, dataset and the optional credentials
+// file and API endpoint of a bigquery://project/dataset?credentials=key.json
+// connection string. An endpoint points the client at an emulator.
+func parseBigQueryDSN(dsn string) (project, dataset, credentials, endpoint string, err error) {
+	rest := strings.TrimPrefix(dsn, "bigquery://")
+	rest, query, _ := strings.Cut(rest, "?")
+	project, dataset, _ = strings.Cut(rest, "/")
+	if project == "" || dataset == "" || strings.Contains(dataset, "/") {
+		return "", "", "", "", fmt.Errorf("invalid BigQuery connection string %q: expected bigquery://project/dataset", dsn)
+	}
+	for _, param := range strings.Split(query, "&") {
+		if value, ok := strings.CutPrefix(param, "credentials="); ok {
+			credentials = value
+		}
+		if value, ok := strings.CutPrefix(param, "endpoint="); ok {
+			endpoint = value
+		}
+	}
+	return project, dataset, credentials, endpoint, nil
+}
+
+// checkBigQueryOptions rejects the options that need a SQL source
+func (c *Copier) checkBigQueryOptions() error {
+	switch {
+	case c.Query != "":
+		return errors.New("--query is not supported for BigQuery sources")
+	case c.Verify != "":
+		return errors.New("--verify is not supported for BigQuery sources")
+	case c.SkipUnchanged:
+		return errors.New("--skip-unchanged is not supported for BigQuery sources")
+	case c.Distinct || len(c.DistinctOn) > 0:
+		return errors.New("--distinct is not supported for BigQuery sources")
+	case c.TimeColumn != "":
+		return errors.New("--time-column is not supported for BigQuery sources")
+	case c.SchemaCache != "":
+		return errors.New("--schema-cache is not supported for BigQuery sources")
+	}
+	return nil
+}
+
+// readBigQuerySchema reads the columns of a BigQuery table from its metadata.
+// BigQuery does not enforce keys, so no column is a primary key.
+func (c *Copier) readBigQuerySchema(table string) ([]Column, error) {
+	fields, err := c.bigQuery.fields(c.copyContext(), table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get column types: %w", err)
+	}
+	columns := make([]Column, len(fields))
+	for i, field := range fields {
+		sourceType := field.Type
+		if field.Repeated {
+			sourceType = "ARRAY"
+		}
+		columns[i] = Column{
+			Name:       field.Name,
+			Type:       c.convertDataType(sourceType, DBTypeBigQuery, c.destDBType),
+			IsNullable: !field.Required,
+		}
+	}
+	return columns, nil
+}
+
+// bigQueryType maps a BigQuery column type to the destination. Arrays and
+// records are copied as JSON, which is JSONB on PostgreSQL and text elsewhere
+// since DuckDB's JSON type needs an extension. GEOGRAPHY values are WKT text.
+func bigQueryType(sourceType string, toDB DBType) string {
+	switch toDB {
+	case DBTypePostgres:
+		switch sourceType {
+		case "STRING", "GEOGRAPHY", "INTERVAL", "RANGE":
+			return "TEXT"
+		case "BYTES":
+			return "BYTEA"
+		case "INTEGER", "INT64":
+			return "BIGINT"
+		case "FLOAT", "FLOAT64":
+			return "DOUBLE PRECISION"
+		case "NUMERIC":
+			return "NUMERIC(38,9)"
+		case "BIGNUMERIC":
+			return "NUMERIC"
+		case "BOOLEAN", "BOOL":
+			return "BOOLEAN"
+		case "TIMESTAMP":
+			return "TIMESTAMPTZ"
+		case "DATETIME":
+			return "TIMESTAMP"
+		case "DATE":
+			return "DATE"
+		case "TIME":
+			return "TIME"
+		case "JSON", "RECORD", "STRUCT", "ARRAY":
+			return "JSONB"
+		}
+	case DBTypeDuckDB:
+		switch sourceType {
+		case "BYTES":
+			return "BLOB"
+		case "INTEGER", "INT64":
+			return "BIGINT"
+		case "FLOAT", "FLOAT64":
+			return "DOUBLE"
+		case "NUMERIC":
+			return "DECIMAL(38,9)"
+		case "BOOLEAN", "BOOL":
+			return "BOOLEAN"
+		case "TIMESTAMP":
+			return "TIMESTAMPTZ"
+		case "DATETIME":
+			return "TIMESTAMP"
+		case "DATE":
+			return "DATE"
+		case "TIME":
+			return "TIME"
+		}
+	default:
+		switch sourceType {
+		case "BYTES":
+			return "BLOB"
+		case "INTEGER", "INT64":
+			return "INTEGER"
+		case "FLOAT", "FLOAT64":
+			return "REAL"
+		case "NUMERIC", "BIGNUMERIC":
+			return "NUMERIC"
+		case "BOOLEAN", "BOOL":
+			return "BOOLEAN"
+		case "TIMESTAMP", "DATETIME":
+			return "DATETIME"
+		}
+	}
+	return "TEXT"
+}
+
+// bigQueryReader reads a BigQuery table page by page
+type bigQueryReader struct {
+	c         *Copier
+	batchSize int
+	rows      bigQueryRows
+}
+
+func (r *bigQueryReader) Columns() ([]Column, error) {
+	return r.c.sourceColumns()
+}
+
+func (r *bigQueryReader) ReadBatch(ctx context.Context) ([]map[string]interface{}, error) {
+	if r.rows == nil {
+		rows, err := r.c.bigQuery.rows(ctx, r.c.TableName, r.batchSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read from source table: %w", err)
+		}
+		r.rows = rows
+	}
+
+	var batch []map[string]interface{}
+	for len(batch) < r.batchSize {
+		record, err := r.rows.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read source row: %w", err)
+		}
+		batch = append(batch, r.c.selectedFields(record))
+	}
+	return batch, nil
+}
+
+func (r *bigQueryReader) Close() error {
+	return nil
+}
+
+// selectedFields drops the columns of a record that are not selected for the
+// current table. SQL sources leave them out of the SELECT instead.
+func (c *Copier) selectedFields(record map[string]interface{}) map[string]interface{} {
+	selected := c.selectedColumns()
+	if selected == nil {
+		return record
+	}
+	kept := make(map[string]interface{}, len(selected))
+	for _, name := range selected {
+		if value, ok := record[name]; ok {
+			kept[name] = value
+		}
+	}
+	return kept
+}
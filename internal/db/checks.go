@@ -0,0 +1,209 @@
+package db
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+// CheckConstraint represents a CHECK constraint on a table
+type CheckConstraint struct {
+	Name       string
+	Expression string
+	// NotValid marks a PostgreSQL check that existing rows may violate
+	NotValid bool
+}
+
+// constraintNamePattern matches a "CONSTRAINT name" prefix at the end of a DDL fragment
+var constraintNamePattern = regexp.MustCompile(`(?i)CONSTRAINT\s+("[^"]+"|\x60[^\x60]+\x60|\[[^\]]+\]|\w+)\s*$`)
+
+// getSourceCheckConstraints retrieves the CHECK constraints of a table from the source database
+func (c *Copier) getSourceCheckConstraints(table string) ([]CheckConstraint, error) {
+	if c.schemaFile != nil {
+		return c.schemaFile.CheckConstraints, nil
+	}
+	switch c.sourceDBType {
+	case DBTypeSQLite:
+		var createSQL string
+		if err := c.sourceConn.Raw("SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&createSQL).Error; err != nil {
+			return nil, fmt.Errorf("failed to get table definition: %w", err)
+		}
+		return parseCheckConstraints(createSQL), nil
+	case DBTypePostgres:
+		var rows []struct {
+			Name       string
+			Definition string
+		}
+		if err := c.sourceConn.Raw(`
+			SELECT conname AS name, pg_get_constraintdef(oid) AS definition
+			FROM pg_constraint
+			WHERE conrelid = ?::regclass AND contype = 'c'
+			ORDER BY conname
+		`, table).Scan(&rows).Error; err != nil {
+			return nil, fmt.Errorf("failed to get check constraints: %w", err)
+		}
+
+		var checks []CheckConstraint
+		for _, row := range rows {
+			// The definition has the form "CHECK ((expr))" with an optional NOT VALID suffix
+			def := strings.TrimSpace(row.Definition)
+			notValid := strings.HasSuffix(def, " NOT VALID")
+			def = strings.TrimSuffix(def, " NOT VALID")
+			def = strings.TrimSpace(strings.TrimPrefix(def, "CHECK"))
+			checks = append(checks, CheckConstraint{
+				Name:       row.Name,
+				Expression: strings.TrimSuffix(strings.TrimPrefix(def, "("), ")"),
+				NotValid:   notValid,
+			})
+		}
+		return checks, nil
+	}
+	return nil, nil
+}
+
+// parseCheckConstraints extracts the CHECK constraints from a SQLite CREATE TABLE statement.
+// Both column-level and table-level constraints are returned.
+func parseCheckConstraints(createSQL string) []CheckConstraint {
+	var checks []CheckConstraint
+	var quote rune
+	for i := 0; i < len(createSQL); i++ {
+		ch := rune(createSQL[i])
+		if quote != 0 {
+			if ch == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch ch {
+		case '\'', '"', '`':
+			quote = ch
+			continue
+		case '[':
+			quote = ']'
+			continue
+		}
+
+		if !isKeywordAt(createSQL, i, "CHECK") {
+			continue
+		}
+
+		// Find the opening parenthesis of the expression
+		j := i + len("CHECK")
+		for j < len(createSQL) && unicode.IsSpace(rune(createSQL[j])) {
+			j++
+		}
+		if j >= len(createSQL) || createSQL[j] != '(' {
+			continue
+		}
+		end := matchingParen(createSQL, j)
+		if end < 0 {
+			break
+		}
+
+		check := CheckConstraint{Expression: strings.TrimSpace(createSQL[j+1 : end])}
+		if m := constraintNamePattern.FindStringSubmatch(createSQL[:i]); m != nil {
+			check.Name = strings.Trim(m[1], "\"`[]")
+		}
+		checks = append(checks, check)
+		i = end
+	}
+	return checks
+}
+
+// isWordChar reports whether b can be part of an identifier or keyword
+func isWordChar(b byte) bool {
+	return b == '_' || unicode.IsLetter(rune(b)) || unicode.IsDigit(rune(b))
+}
+
+// isKeywordAt reports whether keyword appears at position i as a whole word
+func isKeywordAt(s string, i int, keyword string) bool {
+	if i+len(keyword) > len(s) || !strings.EqualFold(s[i:i+len(keyword)], keyword) {
+		return false
+	}
+	if i > 0 && isWordChar(s[i-1]) {
+		return false
+	}
+	if end := i + len(keyword); end < len(s) && isWordChar(s[end]) {
+		return false
+	}
+	return true
+}
+
+// matchingParen returns the index of the parenthesis closing the one at open,
+// skipping over quoted text, or -1 if it is unbalanced
+func matchingParen(s string, open int) int {
+	depth := 0
+	var quote byte
+	for i := open; i < len(s); i++ {
+		ch := s[i]
+		if quote != 0 {
+			if ch == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch ch {
+		case '\'', '"', '`':
+			quote = ch
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// checkDefinition renders a CHECK clause with its optional constraint name
+func checkDefinition(check CheckConstraint) string {
+	if check.Name != "" {
+		return fmt.Sprintf("CONSTRAINT %s CHECK (%s)", check.Name, check.Expression)
+	}
+	return fmt.Sprintf("CHECK (%s)", check.Expression)
+}
+
+// portableChecks filters out check expressions that use PostgreSQL-only casts
+// when the destination is not PostgreSQL, and NOT VALID checks, which only
+// PostgreSQL can hold without checking the rows already copied
+func (c *Copier) portableChecks(checks []CheckConstraint) []CheckConstraint {
+	if c.destDBType == DBTypePostgres {
+		return checks
+	}
+	var portable []CheckConstraint
+	for _, check := range checks {
+		if check.NotValid {
+			c.noticeOnce("check:"+check.Name, fmt.Sprintf("Skipping check constraint %s on %s: it is NOT VALID, so existing rows may violate it", check.Name, c.TableName))
+			c.recordUnportable(UnportableCheck, check.Name, checkDefinition(check)+" NOT VALID", "the constraint is NOT VALID and the destination cannot skip checking existing rows")
+			continue
+		}
+		if c.sourceDBType == DBTypePostgres && strings.Contains(check.Expression, "::") {
+			c.noticeOnce("check:"+check.Name, fmt.Sprintf("Skipping check constraint %s on %s: expression %q uses PostgreSQL casts", check.Name, c.TableName, check.Expression))
+			c.recordUnportable(UnportableCheck, check.Name, checkDefinition(check), "the expression uses PostgreSQL casts")
+			continue
+		}
+		portable = append(portable, check)
+	}
+	return portable
+}
+
+// addCheckConstraints adds check constraints to an existing PostgreSQL table
+// using conn. NOT VALID checks are added as NOT VALID, so the rows already in
+// the table are not checked.
+func (c *Copier) addCheckConstraints(conn *gorm.DB, table string, checks []CheckConstraint) error {
+	for _, check := range checks {
+		alterSQL := fmt.Sprintf("ALTER TABLE %s ADD %s;", table, checkDefinition(check))
+		if check.NotValid {
+			alterSQL = fmt.Sprintf("ALTER TABLE %s ADD %s NOT VALID;", table, checkDefinition(check))
+		}
+		if err := conn.Exec(alterSQL).Error; err != nil {
+			return fmt.Errorf("failed to add check constraint %s: %w", check.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Destination SQL dialects for PostgreSQL-protocol servers
+const (
+	DialectPostgres  = "postgres"
+	DialectCockroach = "crdb"
+)
+
+// detectDestDialect determines whether a PostgreSQL-protocol destination is CockroachDB,
+// either from the DestDialect setting or from the server's version string
+func (c *Copier) detectDestDialect() error {
+	if c.destDBType != DBTypePostgres {
+		return nil
+	}
+
+	switch c.DestDialect {
+	case DialectCockroach:
+		c.destCockroach = true
+		return nil
+	case DialectPostgres:
+		return nil
+	}
+
+	var version string
+	if err := c.destConn.Raw("SELECT version()").Scan(&version).Error; err != nil {
+		return fmt.Errorf("failed to get destination server version: %w", err)
+	}
+	c.destCockroach = strings.Contains(version, "CockroachDB")
+	if c.destCockroach {
+		c.printf("Detected CockroachDB destination\n")
+	}
+	return nil
+}
+
+// cockroachType adjusts a PostgreSQL type for CockroachDB, which lacks a few
+// PostgreSQL types. Serial columns need no mapping: PostgreSQL reports them as
+// integer or bigint with a nextval default.
+func cockroachType(pgType string) string {
+	switch strings.ToUpper(pgType) {
+	case "MONEY":
+		return "DECIMAL"
+	case "CIDR":
+		return "INET"
+	case "XML", "MACADDR", "MACADDR8":
+		return "STRING"
+	}
+	return pgType
+}
@@ -0,0 +1,154 @@
+package db
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// valueKind is the class of a destination column type that rejects values
+// SQLite's dynamic typing lets through
+type valueKind int
+
+const (
+	kindOther valueKind = iota
+	kindInteger
+	kindNumber
+	kindBoolean
+)
+
+// valueKindOf classifies a destination column type
+func valueKindOf(info typeInfo, dbType DBType) valueKind {
+	switch {
+	case integerBits(info.Name, dbType) > 0:
+		return kindInteger
+	case info.Name == "NUMERIC" || info.Name == "REAL" || info.Name == "DOUBLE PRECISION":
+		return kindNumber
+	case info.Name == "BOOLEAN" || info.Name == "BOOL":
+		return kindBoolean
+	}
+	return kindOther
+}
+
+// checkValues verifies that every value fits the type of its destination
+// column before the insert is attempted, so a bad value is reported with its
+// row instead of failing a whole batch. With Coerce, text is trimmed and
+// parsed into the column type where that loses nothing. SQLite destinations
+// accept any value and are not checked.
+func (c *Copier) checkValues(records []map[string]interface{}) error {
+	if c.destDBType == DBTypeSQLite || len(records) == 0 {
+		return nil
+	}
+
+	columnTypes, err := c.destColumnTypes()
+	if err != nil {
+		return err
+	}
+	kinds := make(map[string]valueKind)
+	for _, col := range columnTypes {
+		if kind := valueKindOf(parseType(col.DatabaseTypeName()), c.destDBType); kind != kindOther {
+			kinds[col.Name()] = kind
+		}
+	}
+	if len(kinds) == 0 {
+		return nil
+	}
+
+	for i, record := range records {
+		for name, kind := range kinds {
+			value, ok := record[name]
+			if !ok || value == nil {
+				continue
+			}
+			coerced, err := coerceValue(value, kind, c.Coerce)
+			if err != nil {
+				hint := ""
+				if !c.Coerce {
+					hint = " (--coerce trims and converts values where nothing is lost)"
+				}
+				return fmt.Errorf("row %d of %s, column %s: value %q %w%s", i+1, c.destTableName(), name, fmt.Sprint(value), err, hint)
+			}
+			record[name] = coerced
+		}
+	}
+	return nil
+}
+
+// coerceValue checks a value against a column kind. With coerce the value is
+// returned converted to the kind, otherwise unchanged.
+func coerceValue(value interface{}, kind valueKind, coerce bool) (interface{}, error) {
+	if b, ok := value.([]byte); ok {
+		value = string(b)
+	}
+
+	switch v := value.(type) {
+	case string:
+		s := strings.TrimSpace(v)
+		switch kind {
+		case kindInteger:
+			if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+				if coerce {
+					return n, nil
+				}
+				return value, nil
+			}
+			if !coerce {
+				return nil, fmt.Errorf("is not an integer")
+			}
+			f, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return nil, fmt.Errorf("is not a number")
+			}
+			return integralValue(f)
+		case kindNumber:
+			if _, err := strconv.ParseFloat(s, 64); err != nil {
+				return nil, fmt.Errorf("is not a number")
+			}
+			if coerce {
+				return s, nil
+			}
+		case kindBoolean:
+			// Without coercion only the spellings every destination accepts pass
+			if !coerce {
+				if _, err := strconv.ParseBool(s); err != nil {
+					return nil, fmt.Errorf("is not a boolean")
+				}
+				return value, nil
+			}
+			b, ok := parseBoolean(s)
+			if !ok {
+				return nil, fmt.Errorf("is not a boolean")
+			}
+			return b, nil
+		}
+	case float64:
+		if kind == kindInteger {
+			n, err := integralValue(v)
+			if err != nil || !coerce {
+				return value, err
+			}
+			return n, nil
+		}
+	}
+	return value, nil
+}
+
+// integralValue converts a float without a fractional part to an integer
+func integralValue(f float64) (interface{}, error) {
+	if f != math.Trunc(f) || math.IsInf(f, 0) || math.Abs(f) > math.MaxInt64 {
+		return nil, fmt.Errorf("has a fractional part")
+	}
+	return int64(f), nil
+}
+
+// parseBoolean parses the boolean spellings PostgreSQL accepts
+func parseBoolean(s string) (bool, bool) {
+	switch strings.ToLower(s) {
+	case "t", "true", "y", "yes", "on", "1":
+		return true, true
+	case "f", "false", "n", "no", "off", "0":
+		return false, true
+	}
+	return false, false
+}
@@ -0,0 +1,50 @@
+package db
+
+import "fmt"
+
+// getSourceCollations returns the collations of the columns of a PostgreSQL
+// source table that differ from the database default. SQLite does not report
+// column collations, so SQLite sources have none.
+func (c *Copier) getSourceCollations(table string) (map[string]string, error) {
+	collations := make(map[string]string)
+	if c.sourceDBType != DBTypePostgres || c.schemaFile != nil {
+		return collations, nil
+	}
+
+	var rows []struct {
+		Name      string
+		Collation string
+	}
+	if err := c.sourceConn.Raw(`
+		SELECT a.attname AS name, co.collname AS collation
+		FROM pg_attribute a
+		JOIN pg_collation co ON co.oid = a.attcollation
+		JOIN pg_type t ON t.oid = a.atttypid
+		WHERE a.attrelid = ?::regclass AND a.attnum > 0 AND NOT a.attisdropped
+		  AND a.attcollation <> t.typcollation
+	`, table).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get column collations: %w", err)
+	}
+	for _, row := range rows {
+		collations[row.Name] = row.Collation
+	}
+	return collations, nil
+}
+
+// columnCollation returns the COLLATE clause for a column of the generated
+// CREATE TABLE: the source collation with PreserveCollation, otherwise
+// DestCollation. Only character columns of PostgreSQL destinations get one.
+func (c *Copier) columnCollation(col Column, sourceCollations map[string]string) string {
+	collation := c.DestCollation
+	if source, ok := sourceCollations[col.Name]; ok {
+		collation = source
+	}
+	if collation == "" || !isTextType(parseType(col.Type).Name) {
+		return ""
+	}
+	if c.destDBType != DBTypePostgres {
+		c.noticeOnce("collation", "Column collations are only supported for PostgreSQL destinations and will be ignored")
+		return ""
+	}
+	return " COLLATE " + quoteIdent(collation)
+}
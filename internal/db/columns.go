@@ -0,0 +1,141 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+)
+
+// selectedColumns returns the columns chosen for the current table, or nil
+// when every column is copied
+func (c *Copier) selectedColumns() []string {
+	if c.Query != "" {
+		return nil
+	}
+	return c.Columns[c.TableName]
+}
+
+// sourceSelects returns the select list for reading the current table, or nil
+// to read every column as stored
+func (c *Copier) sourceSelects(columns []Column) []string {
+	if selects := c.spatialSelects(columns); selects != nil {
+		return selects
+	}
+	if selects := c.timeSelects(columns); selects != nil {
+		return selects
+	}
+	return c.selectedColumns()
+}
+
+// hasColumns reports whether all of the given columns are part of the copy
+func (c *Copier) hasColumns(columns []string) bool {
+	selected := c.selectedColumns()
+	if selected == nil {
+		return true
+	}
+	for _, col := range columns {
+		found := false
+		for _, s := range selected {
+			if s == col {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// filterColumns drops the columns that are not selected for the current table
+func (c *Copier) filterColumns(columns []Column) []Column {
+	if c.selectedColumns() == nil {
+		return columns
+	}
+	var filtered []Column
+	for _, col := range columns {
+		if c.hasColumns([]string{col.Name}) {
+			filtered = append(filtered, col)
+		}
+	}
+	return filtered
+}
+
+// destColumnTypes returns the columns of the destination table as reported
+// for a result set. Unlike the catalog queries of GORM's migrator this works
+// for every destination, DuckDB included.
+func (c *Copier) destColumnTypes() ([]*sql.ColumnType, error) {
+	rows, err := c.destConn.Table(c.destTableName()).Limit(0).Rows()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get destination columns: %w", err)
+	}
+	defer rows.Close()
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get destination columns: %w", err)
+	}
+	return columnTypes, nil
+}
+
+// destColumnNames returns the names of the destination table's columns. For
+// destinations without tables they are the destination names of columns.
+func (c *Copier) destColumnNames(columns []Column) (map[string]bool, error) {
+	names := make(map[string]bool)
+	if c.destConn == nil {
+		for _, col := range columns {
+			names[c.destColumn(col.Name)] = true
+		}
+		return names, nil
+	}
+	columnTypes, err := c.destColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	for _, col := range columnTypes {
+		names[col.Name()] = true
+	}
+	return names, nil
+}
+
+// selectedIndexes drops the indexes that cover a column left out of the copy
+func (c *Copier) selectedIndexes(indexes []Index) []Index {
+	var filtered []Index
+	for _, idx := range indexes {
+		if c.hasColumns(idx.Columns) {
+			filtered = append(filtered, idx)
+		}
+	}
+	return filtered
+}
+
+// selectedChecks drops the check constraints whose expression mentions a
+// column left out of the copy
+func (c *Copier) selectedChecks(checks []CheckConstraint) []CheckConstraint {
+	if c.selectedColumns() == nil {
+		return checks
+	}
+	columns, err := c.getSourceSchema(c.TableName)
+	if err != nil {
+		return checks
+	}
+
+	var filtered []CheckConstraint
+	for _, check := range checks {
+		keep := true
+		for _, col := range columns {
+			if c.hasColumns([]string{col.Name}) {
+				continue
+			}
+			pattern := fmt.Sprintf(`(?i)(^|[^\w])"?%s"?($|[^\w])`, regexp.QuoteMeta(col.Name))
+			if regexp.MustCompile(pattern).MatchString(check.Expression) {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			filtered = append(filtered, check)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,106 @@
+package db
+
+import "strings"
+
+// Column name normalizations for the destination
+const (
+	ColumnsCasePreserve = "preserve"
+	ColumnsCaseLower    = "lower"
+	ColumnsCaseUpper    = "upper"
+)
+
+// destColumn returns the destination name of a source column
+func (c *Copier) destColumn(name string) string {
+	switch c.ColumnsCase {
+	case ColumnsCaseLower:
+		return strings.ToLower(name)
+	case ColumnsCaseUpper:
+		return strings.ToUpper(name)
+	}
+	return name
+}
+
+// quoteIdent quotes an identifier so that its case is kept. Double quotes
+// work for PostgreSQL, SQLite and DuckDB alike.
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// quotedDestColumns returns the quoted destination names of source columns
+func (c *Copier) quotedDestColumns(names []string) []string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = quoteIdent(c.destColumn(name))
+	}
+	return quoted
+}
+
+// renameRecordColumns renames the keys of each record to destination column
+// names. Records are left alone when names are preserved.
+func (c *Copier) renameRecordColumns(records []map[string]interface{}) {
+	if c.ColumnsCase == "" || c.ColumnsCase == ColumnsCasePreserve {
+		return
+	}
+	for _, record := range records {
+		for name, value := range record {
+			if dest := c.destColumn(name); dest != name {
+				delete(record, name)
+				record[dest] = value
+			}
+		}
+	}
+}
+
+// renameExpressionColumns rewrites the column references of a check expression
+// to quoted destination column names, so the expression matches the columns
+// as they are created. String literals are left untouched.
+func (c *Copier) renameExpressionColumns(expression string, columns []Column) string {
+	names := make(map[string]string)
+	for _, col := range columns {
+		names[strings.ToLower(col.Name)] = quoteIdent(c.destColumn(col.Name))
+	}
+
+	var out strings.Builder
+	for i := 0; i < len(expression); {
+		ch := expression[i]
+		switch {
+		case ch == '\'':
+			end := strings.IndexByte(expression[i+1:], '\'')
+			if end < 0 {
+				out.WriteString(expression[i:])
+				return out.String()
+			}
+			out.WriteString(expression[i : i+end+2])
+			i += end + 2
+		case ch == '"':
+			end := strings.IndexByte(expression[i+1:], '"')
+			if end < 0 {
+				out.WriteString(expression[i:])
+				return out.String()
+			}
+			word := expression[i+1 : i+1+end]
+			if dest, ok := names[strings.ToLower(word)]; ok {
+				out.WriteString(dest)
+			} else {
+				out.WriteString(expression[i : i+end+2])
+			}
+			i += end + 2
+		case isWordChar(ch):
+			start := i
+			for i < len(expression) && isWordChar(expression[i]) {
+				i++
+			}
+			word := expression[start:i]
+			// A word directly after :: is a type name, not a column
+			if dest, ok := names[strings.ToLower(word)]; ok && !strings.HasSuffix(expression[:start], "::") {
+				out.WriteString(dest)
+			} else {
+				out.WriteString(word)
+			}
+		default:
+			out.WriteByte(ch)
+			i++
+		}
+	}
+	return out.String()
+}
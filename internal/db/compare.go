@@ -0,0 +1,123 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// typeClass groups column types whose values convert into each other without
+// surprises: integer, number, boolean, text, time, binary or json. Types it
+// does not know return "" and are not compared.
+func typeClass(info typeInfo, dbType DBType) string {
+	name := info.Name
+	switch {
+	case integerBits(name, dbType) > 0:
+		return "integer"
+	case name == "NUMERIC" || name == "REAL" || name == "DOUBLE PRECISION":
+		return "number"
+	case name == "BOOLEAN" || name == "BOOL":
+		return "boolean"
+	case isTextType(name):
+		return "text"
+	case strings.HasPrefix(name, "TIMESTAMP") || name == "DATE" || name == "DATETIME":
+		return "time"
+	case name == "BYTEA" || name == "BLOB":
+		return "binary"
+	case name == "JSON" || name == "JSONB":
+		return "json"
+	}
+	return ""
+}
+
+// compatibleTypes reports whether values of the source type can be inserted
+// into a column of the destination type. Integers fit number columns.
+func compatibleTypes(src, dst string) bool {
+	return src == "" || dst == "" || src == dst || src == "integer" && dst == "number"
+}
+
+// compareDestSchema compares an existing destination table with the table the
+// source would create, and fails with the differences that would make the
+// copy insert into the wrong columns or fail part way: source columns missing
+// from the destination, incompatible types, NULLs the destination rejects, and
+// NOT NULL destination columns the copy leaves empty. With ForceIncompatible
+// the differences are only logged. A table that does not exist yet is created
+// from the source and not compared.
+func (c *Copier) compareDestSchema() error {
+	if c.TempTable || !c.destConn.Migrator().HasTable(c.destTableName()) {
+		return nil
+	}
+	columns, err := c.sourceColumns()
+	if err != nil {
+		return fmt.Errorf("failed to get source table schema: %w", err)
+	}
+	destTypes, err := c.destConn.Migrator().ColumnTypes(c.destTableName())
+	if err != nil {
+		return fmt.Errorf("failed to get destination column types: %w", err)
+	}
+
+	filled := make(map[string]bool)
+	for name := range c.Defaults {
+		filled[name] = true
+	}
+	for _, name := range c.SetNow {
+		filled[name] = true
+	}
+
+	var diffs []string
+	copied := make(map[string]bool)
+	for _, col := range columns {
+		name := c.destColumn(col.Name)
+		copied[name] = true
+		var dest *typeInfo
+		nullable := true
+		for _, dc := range destTypes {
+			if dc.Name() == name {
+				info := columnTypeInfo(dc)
+				dest = &info
+				if n, ok := dc.Nullable(); ok {
+					nullable = n
+				}
+				break
+			}
+		}
+		if dest == nil {
+			// Added before the copy
+			if c.IfExists == IfExistsAppendMissingColumns {
+				continue
+			}
+			diffs = append(diffs, fmt.Sprintf("- %s: missing from the destination table", name))
+			continue
+		}
+		// SQLite stores any value in any column
+		src := parseType(col.Type)
+		if c.destDBType != DBTypeSQLite && !compatibleTypes(typeClass(src, c.destDBType), typeClass(*dest, c.destDBType)) {
+			diffs = append(diffs, fmt.Sprintf("~ %s: source %s, destination %s", name, typeString(src), typeString(*dest)))
+		}
+		if col.IsNullable && !col.IsPrimary && !nullable && !filled[name] {
+			diffs = append(diffs, fmt.Sprintf("~ %s: NULL in the source, NOT NULL in the destination", name))
+		}
+	}
+	for _, dc := range destTypes {
+		if copied[dc.Name()] || filled[dc.Name()] {
+			continue
+		}
+		nullable, _ := dc.Nullable()
+		_, hasDefault := dc.DefaultValue()
+		primary, _ := dc.PrimaryKey()
+		if !nullable && !hasDefault && !primary {
+			diffs = append(diffs, fmt.Sprintf("+ %s: only in the destination, NOT NULL without a default", dc.Name()))
+		}
+	}
+	if len(diffs) == 0 {
+		return nil
+	}
+
+	if c.ForceIncompatible {
+		zap.L().Warn("Copying into an existing table that does not match the source schema",
+			zap.String("table", c.destTableName()), zap.Strings("differences", diffs))
+		return nil
+	}
+	return fmt.Errorf("existing destination table does not match the source schema (--force copies anyway):\n  %s", strings.Join(diffs, "\n  "))
+}
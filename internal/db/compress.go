@@ -0,0 +1,34 @@
+package db
+
+import "go.uber.org/zap"
+
+// reportWireCompression reports whether the source connection of a
+// WireCompress copy is compressed. PostgreSQL compresses the wire only through
+// SSL compression, which servers from version 14 on no longer offer and which
+// neither pgx nor Go's TLS negotiates, so in practice the source is read
+// uncompressed; this checks pg_stat_ssl rather than assume so.
+func (c *Copier) reportWireCompression() {
+	if !c.WireCompress {
+		return
+	}
+	if c.sourceDBType != DBTypePostgres {
+		c.noticeOnce("wire-compress", "--wire-compress only applies to PostgreSQL sources and will be ignored")
+		return
+	}
+
+	var status struct {
+		SSL         bool
+		Compression bool
+	}
+	err := c.sourceConn.Raw("SELECT ssl, compression FROM pg_stat_ssl WHERE pid = pg_backend_pid()").Scan(&status).Error
+	switch {
+	case err == nil && status.Compression:
+		c.printf("Wire compression: negotiated\n")
+	case err == nil && !status.SSL:
+		zap.L().Warn("Wire compression not negotiated: the source connection does not use SSL, which PostgreSQL compression requires")
+	default:
+		// pg_stat_ssl lost its compression column along with SSL compression
+		// in PostgreSQL 14
+		zap.L().Warn("Wire compression not negotiated: the PostgreSQL driver and server do not support SSL compression; reading the source uncompressed")
+	}
+}
@@ -0,0 +1,62 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// CopyContext is like Copy but stops when ctx is cancelled. Statements in
+// flight are cancelled and the open transaction is rolled back.
+func (c *Copier) CopyContext(ctx context.Context) error {
+	return c.withContext(ctx, c.Copy)
+}
+
+// CopyAllContext is like CopyAll but stops when ctx is cancelled
+func (c *Copier) CopyAllContext(ctx context.Context) error {
+	return c.withContext(ctx, c.CopyAll)
+}
+
+// CopyTablesContext is like CopyTables but stops when ctx is cancelled
+func (c *Copier) CopyTablesContext(ctx context.Context, tables []string) error {
+	return c.withContext(ctx, func() error {
+		return c.CopyTables(tables)
+	})
+}
+
+// withContext runs fn with both connections bound to ctx
+func (c *Copier) withContext(ctx context.Context, fn func() error) error {
+	source, dest := c.sourceConn, c.destConn
+	if source != nil {
+		c.sourceConn = source.WithContext(ctx)
+	}
+	if dest != nil {
+		c.destConn = dest.WithContext(ctx)
+	}
+	c.ctx = ctx
+	defer func() {
+		c.sourceConn, c.destConn = source, dest
+		c.ctx = nil
+	}()
+
+	err := fn()
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("copy interrupted: %w", ctxErr)
+	}
+	return err
+}
+
+// copyContext returns the context of the running copy
+func (c *Copier) copyContext() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+// interrupted returns the context error once the copy has been cancelled
+func (c *Copier) interrupted() error {
+	if c.ctx == nil {
+		return nil
+	}
+	return c.ctx.Err()
+}
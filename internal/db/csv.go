@@ -0,0 +1,220 @@
+package db
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// isCSVDSN reports whether a destination is a CSV file, or with the csv://
+// prefix a directory to write one CSV file per table into
+func isCSVDSN(dsn string) bool {
+	return strings.HasPrefix(dsn, "csv://") || strings.HasSuffix(strings.ToLower(dsn), ".csv")
+}
+
+// csvPath returns the file the current table is written to
+func (c *Copier) csvPath() string {
+	path := strings.TrimPrefix(c.DestDB, "csv://")
+	if strings.HasSuffix(strings.ToLower(path), ".csv") {
+		return path
+	}
+	return filepath.Join(path, c.destTableName()+".csv")
+}
+
+// checkCSVOptions rejects the options that need a destination database
+func (c *Copier) checkCSVOptions() error {
+	switch {
+	case c.AtomicSwap:
+		return errors.New("--atomic-swap is not supported for CSV destinations; files are replaced only once complete anyway")
+	case c.Verify != "":
+		return errors.New("--verify is not supported for CSV destinations")
+	case c.SkipUnchanged:
+		return errors.New("--skip-unchanged is not supported for CSV destinations")
+	case c.SkipErrors:
+		return errors.New("--skip-errors is not supported for CSV destinations")
+	}
+	return nil
+}
+
+// csvColumns orders the columns of a CSV file: in the order of the selected
+// columns when there are any, otherwise as the source returns them
+func (c *Copier) csvColumns(columns []Column) []Column {
+	selected := c.selectedColumns()
+	if selected == nil {
+		return columns
+	}
+	byName := make(map[string]Column, len(columns))
+	for _, col := range columns {
+		byName[col.Name] = col
+	}
+	ordered := make([]Column, 0, len(columns))
+	for _, name := range selected {
+		if col, ok := byName[name]; ok {
+			ordered = append(ordered, col)
+		}
+	}
+	return ordered
+}
+
+// csvHeader returns the header row of a CSV file. HeaderMap renames source
+// columns in the header only; the others get their destination names.
+func (c *Copier) csvHeader(columns []Column) []string {
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		if name, ok := c.HeaderMap[col.Name]; ok {
+			header[i] = name
+		} else {
+			header[i] = c.destColumn(col.Name)
+		}
+	}
+	return header
+}
+
+// copyToCSV writes the source table or query to a CSV file with a header row.
+// Like a Parquet file, it is written under a temporary name and moved into
+// place once complete.
+func (c *Copier) copyToCSV() error {
+	if err := c.checkCSVOptions(); err != nil {
+		return err
+	}
+	if err := c.recordSchemaNotHeld(); err != nil {
+		return &ErrSchema{Table: c.TableName, Err: err}
+	}
+	path := c.csvPath()
+	if path == strings.TrimPrefix(c.DestDB, "csv://") && len(c.copiedTables) > 0 {
+		return fmt.Errorf("--dest %s is a single CSV file; give a directory with csv:// to copy several tables", c.DestDB)
+	}
+
+	reader := c.newReader(c.tableBatchSize())
+	defer reader.Close()
+	columns, err := reader.Columns()
+	if err != nil {
+		return &ErrSchema{Table: c.destTableName(), Err: fmt.Errorf("failed to get source table schema: %w", err)}
+	}
+	columns = c.csvColumns(columns)
+	for name := range c.HeaderMap {
+		if !hasSourceColumn(columns, name) {
+			return &ErrSchema{Table: c.destTableName(), Err: fmt.Errorf("--header-map column %s is not copied", name)}
+		}
+	}
+	if c.SchemaOnly {
+		c.copiedTables = append(c.copiedTables, c.destTableName())
+		return nil
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", path, err)
+		}
+	}
+	tmpPath := path + ".tmp"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to create CSV file: %w", err)
+	}
+	defer os.Remove(tmpPath)
+	defer f.Close()
+	writer := csv.NewWriter(f)
+	if err := writer.Write(c.csvHeader(columns)); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	destColumns, err := c.destColumnNames(columns)
+	if err != nil {
+		return err
+	}
+	nowValues := c.setNowValues(destColumns)
+	defaults := c.defaultValues(destColumns)
+	limiter := c.newRateLimiter(c.tableBatchSize())
+	total, read := 0, 0
+	defer c.startProgress(0)()
+	for batchNumber := 1; ; batchNumber++ {
+		if err := c.interrupted(); err != nil {
+			return err
+		}
+		batch, err := reader.ReadBatch(c.copyContext())
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			break
+		}
+		read += len(batch)
+		if batch, err = c.filterRows(batch, read-len(batch)+1); err != nil {
+			return err
+		}
+		if err := c.applyLookups(batch); err != nil {
+			return err
+		}
+		c.renameRecordColumns(batch)
+		if err := c.parseTimes(batch); err != nil {
+			return err
+		}
+		applySetNow(batch, nowValues)
+		applyDefaults(batch, defaults)
+
+		if err := c.throttle(limiter, len(batch)); err != nil {
+			return fmt.Errorf("rate limiter failed: %w", err)
+		}
+		row := make([]string, len(columns))
+		for _, record := range batch {
+			for i, col := range columns {
+				row[i] = csvValue(record[c.destColumn(col.Name)])
+			}
+			if err := writer.Write(row); err != nil {
+				return &ErrInsert{Table: c.destTableName(), Batch: batchNumber, Err: err}
+			}
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return &ErrInsert{Table: c.destTableName(), Batch: batchNumber, Err: err}
+		}
+		total += len(batch)
+		c.printBatch("Copied %d records\n", len(batch))
+		c.reportProgress(len(batch))
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to finish CSV file: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to move CSV file into place: %w", err)
+	}
+
+	if total == 0 {
+		if c.FailOnEmpty {
+			return &ErrEmpty{Source: c.TableName}
+		}
+		c.printf("Source returned no rows; wrote a CSV file with only the header\n")
+	}
+	c.printf("Successfully copied %d records from %s to CSV file %s\n", total, c.TableName, path)
+	c.copiedTables = append(c.copiedTables, c.destTableName())
+	return nil
+}
+
+// hasSourceColumn reports whether columns include the named source column
+func hasSourceColumn(columns []Column, name string) bool {
+	for _, col := range columns {
+		if col.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// csvValue formats a value as a CSV field. NULL becomes an empty field.
+func csvValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(value)
+}
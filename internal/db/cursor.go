@@ -0,0 +1,107 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// cursorName is the server-side cursor a FetchSize read declares
+const cursorName = "db_copy_cursor"
+
+// usesCursor reports whether the source is read through a server-side cursor
+// with FetchSize rows per round trip
+func (c *Copier) usesCursor() bool {
+	if c.FetchSize <= 0 {
+		return false
+	}
+	if c.sourceDBType != DBTypePostgres {
+		c.noticeOnce("fetch-size", "--fetch-size only applies to PostgreSQL sources; reading through a regular cursor")
+		return false
+	}
+	return true
+}
+
+// declareCursor declares a cursor for the source table or query. Cursors only
+// live inside a transaction: the snapshot transaction of ReadConsistency when
+// there is one, otherwise a read-only transaction of the reader's own.
+func (r *sqlReader) declareCursor(ctx context.Context) error {
+	r.startRead(ctx)
+
+	query := r.c.sourceQuery()
+	if query == "" {
+		table, err := r.tableQuery()
+		if err != nil {
+			return err
+		}
+		stmt := table.Session(&gorm.Session{DryRun: true}).Find(&[]map[string]interface{}{}).Statement
+		query = r.c.sourceConn.Dialector.Explain(stmt.SQL.String(), stmt.Vars...)
+	}
+
+	r.cursor = r.c.sourceConn.WithContext(r.ctx)
+	if !r.c.inSnapshot {
+		r.cursor = r.cursor.Begin()
+		if r.cursor.Error != nil {
+			return fmt.Errorf("failed to begin source transaction: %w", r.cursor.Error)
+		}
+		r.ownCursorTx = true
+	}
+	if err := r.cursor.Exec(fmt.Sprintf("DECLARE %s NO SCROLL CURSOR FOR %s", cursorName, query)).Error; err != nil {
+		return fmt.Errorf("failed to declare source cursor: %w", err)
+	}
+	return nil
+}
+
+// readCursor reads the next batch from the server-side cursor, fetching
+// FetchSize rows at a time, so neither the server nor the driver holds more
+// of the result than that
+func (r *sqlReader) readCursor(ctx context.Context) ([]map[string]interface{}, error) {
+	if r.cursor == nil {
+		if err := r.declareCursor(ctx); err != nil {
+			return nil, r.readError(err)
+		}
+	}
+
+	var batch []map[string]interface{}
+	for len(batch) < r.batchSize && !r.cursorDone {
+		if r.rows == nil {
+			rows, err := r.cursor.Raw(fmt.Sprintf("FETCH FORWARD %d FROM %s", r.c.FetchSize, cursorName)).Rows()
+			if err != nil {
+				return nil, r.readError(fmt.Errorf("failed to fetch from source cursor: %w", err))
+			}
+			r.rows, r.fetched = rows, 0
+		}
+		if r.rows.Next() {
+			record := make(map[string]interface{})
+			if err := r.c.sourceConn.ScanRows(r.rows, &record); err != nil {
+				return nil, r.readError(fmt.Errorf("failed to read source row: %w", err))
+			}
+			batch = append(batch, record)
+			r.fetched++
+			continue
+		}
+		if err := r.rows.Err(); err != nil {
+			return nil, r.readError(fmt.Errorf("failed to read from source: %w", err))
+		}
+		r.rows.Close()
+		r.rows = nil
+		// A short fetch means the cursor is exhausted
+		r.cursorDone = r.fetched < r.c.FetchSize
+	}
+	return batch, nil
+}
+
+// closeCursor ends the transaction the cursor was declared in, or closes the
+// cursor when it lives in the snapshot transaction
+func (r *sqlReader) closeCursor() error {
+	if r.cursor == nil {
+		return nil
+	}
+	defer func() { r.cursor = nil }()
+	if r.ownCursorTx {
+		// The transaction only read, so rolling it back loses nothing
+		return r.cursor.Rollback().Error
+	}
+	return r.cursor.Exec("CLOSE " + cursorName).Error
+}
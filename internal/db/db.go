@@ -1,10 +1,18 @@
 package db
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"strings"
+	"sync/atomic"
+	"time"
 
-	"github.com/glebarez/sqlite"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -19,89 +27,430 @@ type DBType int
 const (
 	DBTypeSQLite DBType = iota
 	DBTypePostgres
+	DBTypeDuckDB
+	DBTypeRedis
+	DBTypeBigQuery
+	DBTypeParquet
+	DBTypeCSV
 )
 
+// Policies for a table that is missing from the destination database
+const (
+	OnMissingTableCreate = "create"
+	OnMissingTableSkip   = "skip"
+	OnMissingTableError  = "error"
+)
+
+// errTableSkipped signals that the table should not be copied
+var errTableSkipped = errors.New("table skipped")
+
 // Copier handles database copy operations
 type Copier struct {
-	SourceDB     string
-	DestDB       string
-	TableName    string
-	BatchSize    int
-	sourceConn   *gorm.DB
-	destConn     *gorm.DB
-	sourceDBType DBType
-	destDBType   DBType
+	SourceDB           string
+	DestDB             string
+	TableName          string
+	Query              string
+	DestTable          string
+	TypeOverrides      map[string]string
+	TypeRules          []TypeRule
+	Columns            map[string][]string
+	HeaderMap          map[string]string
+	BatchSize          int
+	TableBatchSizes    map[string]int
+	OnMissingTable     string
+	IfExists           string
+	SchemaOnly         bool
+	NoTransaction      bool
+	CopySequences      bool
+	Workers            int
+	MaxBatchBytes      int
+	AdaptiveBatch      bool
+	MinBatch           int
+	MaxBatch           int
+	BatchLatency       time.Duration
+	DestTablespace     string
+	DestTableOptions   string
+	DestOwner          string
+	Analyze            bool
+	Vacuum             bool
+	DestDialect        string
+	RateLimit          int
+	NoChecks           bool
+	NoTriggers         bool
+	DDLOut             string
+	Manifest           string
+	PreSQL             string
+	PostSQL            string
+	PostSQLAlways      bool
+	FailOnEmpty        bool
+	GeometryAsWKT      bool
+	DestPrefix         string
+	DestSuffix         string
+	Strict             bool
+	Heartbeat          time.Duration
+	ProgressInterval   time.Duration
+	AtomicSwap         bool
+	SchemaCache        string
+	CreateDestDB       bool
+	SetNow             []string
+	Defaults           map[string]string
+	Lookups            map[string]map[string]string
+	LookupStrict       bool
+	SkipUnchanged      bool
+	SourceQueryTimeout time.Duration
+	Paginate           bool
+	KeyColumn          string
+	DestPrimaryKey     []string
+	CompareExisting    bool
+	Incremental        bool
+	ForceIncompatible  bool
+	FetchSize          int
+	WireCompress       bool
+	InsertMethod       string
+	CopyNull           string
+	DestReadonlyCheck  bool
+	TempTable          bool
+	CopyStorageParams  bool
+	Coerce             bool
+	RedisTTL           time.Duration
+	ColumnsCase        string
+	Verify             string
+	VerifySampleSize   int
+	DestCollation      string
+	PreserveCollation  bool
+	SkipErrors         bool
+	MaxErrors          int
+	OnUniqueViolation  string
+	TimeFormat         string
+	SourceSchemas      []string
+	SchemaMap          map[string]string
+	Distinct           bool
+	DistinctOn         []string
+	TimeColumn         string
+	Last               time.Duration
+	ReadConsistency    string
+	SourceParams       ConnParams
+	DestParams         ConnParams
+	SourceDSNParams    map[string]string
+	DestDSNParams      map[string]string
+	SourcePassword     string
+	DestPassword       string
+	Output             io.Writer
+	OnProgress         func(table string, rows int)
+	RowFilter          RowFilter
+	createdTables      []string
+	tableResults       []TableResult
+	ddl                []string
+	copiedTables       []string
+	sampleResults      []SampleResult
+	rowsCopied         atomic.Int64
+	rowErrors          atomic.Int64
+	heartbeatRunning   bool
+	inSnapshot         bool
+	watermarkColumn    string
+	watermark          interface{}
+	tableOrder         map[string]int
+	deferredFKs        []deferredForeignKey
+	notValidChecks     []CheckConstraint
+	unportable         []UnportableItem
+	ddlOutStarted      bool
+	schemaFile         *TableSchema
+	stagingSuffix      string
+	schemaCache        *schemaCache
+	ctx                context.Context
+	silent             bool
+	notices            map[string]bool
+	destCockroach      bool
+	sourceConn         *gorm.DB
+	keepalive          []*sql.Conn
+	destConn           *gorm.DB
+	redisConn          *redis.Client
+	bigQuery           bigQuerySource
+	sourceDBType       DBType
+	destDBType         DBType
 }
 
 // NewCopier creates a new instance of Copier
 func NewCopier(sourceDB, destDB, tableName string, batchSize int) *Copier {
 	c := &Copier{
-		SourceDB:  sourceDB,
-		DestDB:    destDB,
-		TableName: tableName,
-		BatchSize: batchSize,
+		SourceDB:       sourceDB,
+		DestDB:         destDB,
+		TableName:      tableName,
+		BatchSize:      batchSize,
+		OnMissingTable: OnMissingTableCreate,
+		IfExists:       IfExistsAppend,
 	}
 
 	// Determine source database type
 	if strings.HasPrefix(sourceDB, "postgres://") {
 		c.sourceDBType = DBTypePostgres
+	} else if isBigQueryDSN(sourceDB) {
+		c.sourceDBType = DBTypeBigQuery
 	} else {
 		c.sourceDBType = DBTypeSQLite
 	}
 
 	// Determine destination database type
-	if strings.HasPrefix(destDB, "postgres://") {
+	switch {
+	case strings.HasPrefix(destDB, "postgres://"):
 		c.destDBType = DBTypePostgres
-	} else {
+	case strings.HasPrefix(destDB, "duckdb://") || strings.HasSuffix(destDB, ".duckdb"):
+		c.destDBType = DBTypeDuckDB
+	case isRedisDSN(destDB):
+		c.destDBType = DBTypeRedis
+	case isParquetDSN(destDB):
+		c.destDBType = DBTypeParquet
+	case isCSVDSN(destDB):
+		c.destDBType = DBTypeCSV
+	default:
 		c.destDBType = DBTypeSQLite
 	}
 
+	// Refresh planner statistics after loading into PostgreSQL by default
+	c.Analyze = c.destDBType == DBTypePostgres
+
 	return c
 }
 
 // Connect establishes connections to both source and destination databases
 func (c *Copier) Connect() error {
-	var err error
+	if err := c.ConnectSource(); err != nil {
+		return err
+	}
+	return c.ConnectDest()
+}
+
+// ConnectSource connects to the source database only, for work that writes
+// nowhere, such as exporting a schema
+func (c *Copier) ConnectSource() error {
+	c.applyConnParams()
 
-	// Connect to source database
+	// Inject passwords supplied outside of the connection strings
+	sourceDSN, err := withPassword(c.SourceDB, c.SourcePassword)
+	if err == nil {
+		sourceDSN, err = withDSNParams(sourceDSN, c.SourceDSNParams)
+	}
+	if err != nil {
+		return &ErrConnect{Database: "source", Err: fmt.Errorf("invalid connection string: %w", err)}
+	}
+
+	if strings.HasPrefix(c.SourceDB, "duckdb://") || strings.HasSuffix(c.SourceDB, ".duckdb") {
+		return &ErrConnect{Database: "source", Err: fmt.Errorf("DuckDB is only supported as a destination")}
+	}
+	var keepalive *sql.Conn
 	switch c.sourceDBType {
 	case DBTypePostgres:
-		c.sourceConn, err = gorm.Open(postgres.Open(c.SourceDB), &gorm.Config{})
+		c.sourceConn, err = gorm.Open(postgres.Open(sourceDSN), gormConfig())
 	case DBTypeSQLite:
-		c.sourceConn, err = gorm.Open(sqlite.Open(c.SourceDB), &gorm.Config{})
+		if isSQLDump(c.SourceDB) {
+			c.sourceConn, keepalive, err = openSQLDump(c.SourceDB)
+		} else {
+			c.sourceConn, keepalive, err = openSQLite(sourceDSN)
+		}
+	case DBTypeBigQuery:
+		c.bigQuery, err = openBigQuery(sourceDSN)
+	}
+	if err != nil {
+		return &ErrConnect{Database: "source", Err: err}
+	}
+	if keepalive != nil {
+		c.keepalive = append(c.keepalive, keepalive)
+	}
+	c.reportWireCompression()
+	return nil
+}
+
+// ConnectDest connects to the destination database only, for work that reads
+// nothing from the source, such as applying a schema file
+func (c *Copier) ConnectDest() error {
+	c.applyConnParams()
+
+	destDSN, err := withPassword(c.DestDB, c.DestPassword)
+	if err == nil {
+		destDSN, err = withDSNParams(destDSN, c.DestDSNParams)
 	}
 	if err != nil {
-		return fmt.Errorf("failed to connect to source database: %w", err)
+		return &ErrConnect{Database: "destination", Err: fmt.Errorf("invalid connection string: %w", err)}
 	}
 
-	// Connect to destination database
+	// Create a missing PostgreSQL destination database. SQLite and DuckDB
+	// create their database files on connect.
+	if c.CreateDestDB && c.destDBType == DBTypePostgres {
+		if err := c.ensureDatabase(destDSN); err != nil {
+			return &ErrConnect{Database: "destination", Err: err}
+		}
+	}
+
+	var keepalive *sql.Conn
 	switch c.destDBType {
 	case DBTypePostgres:
-		c.destConn, err = gorm.Open(postgres.Open(c.DestDB), &gorm.Config{})
+		c.destConn, err = gorm.Open(postgres.Open(destDSN), gormConfig())
 	case DBTypeSQLite:
-		c.destConn, err = gorm.Open(sqlite.Open(c.DestDB), &gorm.Config{})
+		c.destConn, keepalive, err = openSQLite(destDSN)
+		if keepalive != nil {
+			c.keepalive = append(c.keepalive, keepalive)
+		}
+	case DBTypeDuckDB:
+		c.destConn, err = openDuckDB(destDSN)
+	case DBTypeRedis:
+		c.redisConn, err = openRedis(destDSN)
 	}
 	if err != nil {
-		return fmt.Errorf("failed to connect to destination database: %w", err)
+		return &ErrConnect{Database: "destination", Err: err}
+	}
+
+	if err := c.detectDestDialect(); err != nil {
+		return &ErrConnect{Database: "destination", Err: err}
 	}
 
+	if c.DestReadonlyCheck {
+		if err := c.checkDestWritable(); err != nil {
+			return &ErrConnect{Database: "destination", Err: err}
+		}
+	}
+
+	if c.TempTable || c.pinForCopy() {
+		if err := c.pinDestConn(); err != nil {
+			return &ErrConnect{Database: "destination", Err: err}
+		}
+	}
+
+	return nil
+}
+
+// Close closes the source and destination database connections. Temporary
+// tables created with TempTable are dropped with them.
+func (c *Copier) Close() error {
+	for _, conn := range c.keepalive {
+		if err := conn.Close(); err != nil {
+			return err
+		}
+	}
+	c.keepalive = nil
+	for _, conn := range []*gorm.DB{c.sourceConn, c.destConn} {
+		if conn == nil {
+			continue
+		}
+		sqlDB, err := conn.DB()
+		if err != nil {
+			return err
+		}
+		if err := sqlDB.Close(); err != nil {
+			return err
+		}
+	}
+	if c.bigQuery != nil {
+		if err := c.bigQuery.Close(); err != nil {
+			return err
+		}
+	}
+	if c.redisConn != nil {
+		return c.redisConn.Close()
+	}
 	return nil
 }
 
+// destTableName returns the name of the table written in the destination database.
+// An explicit DestTable is used as given; otherwise the source table name gets
+// DestPrefix and DestSuffix.
+func (c *Copier) destTableName() string {
+	if c.DestTable != "" {
+		return c.DestTable
+	}
+	return c.destName(c.TableName)
+}
+
+// destName applies DestPrefix and DestSuffix to the name of a table, index or
+// other schema object created in the destination. A schema-qualified table
+// keeps its schema, mapped through SchemaMap.
+func (c *Copier) destName(name string) string {
+	schema, name := splitSchema(name)
+	name = c.DestPrefix + name + c.DestSuffix
+	if schema == "" {
+		return name
+	}
+	return c.qualifyDestName(schema, name)
+}
+
+// printf writes progress output to c.Output, or stdout when it is unset,
+// unless the copier has been silenced
+func (c *Copier) printf(format string, args ...interface{}) {
+	if c.silent {
+		return
+	}
+	out := c.Output
+	if out == nil {
+		out = os.Stdout
+	}
+	fmt.Fprintf(out, format, args...)
+}
+
+// reportProgress passes the source table and the number of rows just inserted
+// to the OnProgress hook. Query copies are reported under the destination table.
+func (c *Copier) reportProgress(rows int) {
+	c.rowsCopied.Add(int64(rows))
+	if c.OnProgress == nil {
+		return
+	}
+	table := c.TableName
+	if c.Query != "" {
+		table = c.destTableName()
+	}
+	c.OnProgress(table, rows)
+}
+
+// noticeOnce logs a warning the first time it is called with a given key
+func (c *Copier) noticeOnce(key, message string) {
+	if c.notices == nil {
+		c.notices = make(map[string]bool)
+	}
+	if c.notices[key] {
+		return
+	}
+	c.notices[key] = true
+	zap.L().Warn(message)
+}
+
 // Column represents a database column with its properties
 type Column struct {
 	Name       string
 	Type       string
 	IsNullable bool
 	IsPrimary  bool
+	IsSpatial  bool
+	EnumType   string
+	EnumValues []string
 }
 
-// getSourceSchema retrieves the table schema from the source database
-func (c *Copier) getSourceSchema() ([]Column, error) {
+// TableColumns returns the columns of a source table, typed for the destination
+func (c *Copier) TableColumns(table string) ([]Column, error) {
+	return c.getSourceSchema(table)
+}
+
+// getSourceSchema retrieves the table schema from the source database, or
+// from the schema cache when one is configured
+func (c *Copier) getSourceSchema(table string) ([]Column, error) {
+	if c.schemaFile != nil {
+		return c.schemaFileColumns(), nil
+	}
+	if c.SchemaCache != "" {
+		return c.cachedSourceSchema(table, func() ([]Column, error) {
+			return c.readSourceSchema(table)
+		})
+	}
+	return c.readSourceSchema(table)
+}
+
+// readSourceSchema reads the table schema from the source database
+func (c *Copier) readSourceSchema(table string) ([]Column, error) {
+	if c.sourceDBType == DBTypeBigQuery {
+		return c.readBigQuerySchema(table)
+	}
+
 	var columns []Column
 
 	// Get table schema using GORM's Migrator
-	columnTypes, err := c.sourceConn.Migrator().ColumnTypes(c.TableName)
+	columnTypes, err := c.sourceConn.Migrator().ColumnTypes(table)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get column types: %w", err)
 	}
@@ -114,7 +463,7 @@ func (c *Copier) getSourceSchema() ([]Column, error) {
 			Name string
 			Pk   int
 		}
-		if err := c.sourceConn.Raw("SELECT name, pk FROM pragma_table_info(?) WHERE pk > 0", c.TableName).Scan(&pks).Error; err != nil {
+		if err := c.sourceConn.Raw("SELECT name, pk FROM pragma_table_info(?) WHERE pk > 0", table).Scan(&pks).Error; err != nil {
 			return nil, fmt.Errorf("failed to get primary keys: %w", err)
 		}
 		for _, pk := range pks {
@@ -126,7 +475,7 @@ func (c *Copier) getSourceSchema() ([]Column, error) {
 			FROM pg_index i
 			JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey)
 			WHERE i.indrelid = ?::regclass AND i.indisprimary
-		`, c.TableName).Scan(&primaryKeys).Error; err != nil {
+		`, table).Scan(&primaryKeys).Error; err != nil {
 			return nil, fmt.Errorf("failed to get primary keys: %w", err)
 		}
 	}
@@ -137,6 +486,19 @@ func (c *Copier) getSourceSchema() ([]Column, error) {
 		pkMap[pk] = true
 	}
 
+	enums, err := c.getSourceEnums(table)
+	if err != nil {
+		return nil, err
+	}
+
+	notNull, err := c.getNotNullColumns(table)
+	if err != nil {
+		return nil, err
+	}
+
+	// Some catalogs report a column once per constraint it takes part in
+	seen := make(map[string]int)
+
 	// Convert column information to our Column type
 	for _, col := range columnTypes {
 		nullable, ok := col.Nullable()
@@ -144,15 +506,116 @@ func (c *Copier) getSourceSchema() ([]Column, error) {
 			// If we can't determine nullability, assume it's nullable
 			nullable = true
 		}
+		// The catalog's NOT NULL flag is authoritative. SQLite does not set it
+		// on an INTEGER PRIMARY KEY, but no primary key column holds NULL.
+		if notNull[col.Name()] || pkMap[col.Name()] {
+			nullable = false
+		}
+
+		if i, ok := seen[col.Name()]; ok {
+			columns[i].IsNullable = columns[i].IsNullable && nullable
+			continue
+		}
+		seen[col.Name()] = len(columns)
 
 		// Get the database type name
 		dbTypeName := col.DatabaseTypeName()
+		colType := c.convertDataType(dbTypeName, c.sourceDBType, c.destDBType)
+
+		// PostGIS types report their modifiers (subtype and SRID) in the full column type
+		spatial := c.sourceDBType == DBTypePostgres && isSpatialType(dbTypeName)
+		if spatial {
+			fullType, ok := col.ColumnType()
+			if !ok || fullType == "" {
+				fullType = dbTypeName
+			}
+			colType = c.spatialType(fullType)
+		}
+
+		// Enums keep their type between PostgreSQL databases and become
+		// CHECK-constrained text elsewhere
+		enum := enums[col.Name()]
+		if enum.EnumType != "" {
+			colType = "TEXT"
+			if c.destDBType == DBTypePostgres {
+				colType = enum.EnumType
+			}
+		}
 
 		columns = append(columns, Column{
 			Name:       col.Name(),
-			Type:       c.convertDataType(dbTypeName, c.sourceDBType, c.destDBType),
+			Type:       colType,
 			IsNullable: nullable,
 			IsPrimary:  pkMap[col.Name()],
+			IsSpatial:  spatial,
+			EnumType:   enum.EnumType,
+			EnumValues: enum.EnumValues,
+		})
+	}
+
+	return columns, nil
+}
+
+// sourceColumns returns the selected columns of the source table or query,
+// with the column rules of TypeRules and any user-supplied type overrides
+// applied
+func (c *Copier) sourceColumns() ([]Column, error) {
+	var columns []Column
+	var err error
+	if c.Query != "" {
+		columns, err = c.getQuerySchema()
+	} else {
+		columns, err = c.getSourceSchema(c.TableName)
+	}
+	if err != nil {
+		return nil, err
+	}
+	columns = c.filterColumns(columns)
+
+	for i, col := range columns {
+		if c.destCockroach {
+			columns[i].Type = cockroachType(col.Type)
+		}
+		if mapped, ok := c.columnTypeRule(col.Name); ok {
+			columns[i].Type = mapped
+		}
+		if override, ok := c.TypeOverrides[col.Name]; ok {
+			columns[i].Type = override
+		}
+	}
+	return c.applyDestPrimaryKey(columns)
+}
+
+// sourceQuery returns Query without the trailing semicolons it is often
+// written with, which would break it once wrapped in a subquery or cursor
+func (c *Copier) sourceQuery() string {
+	return strings.TrimRight(strings.TrimSpace(c.Query), ";")
+}
+
+// getQuerySchema infers the columns of the source query from its result set.
+// Columns whose type the driver cannot report become TEXT.
+func (c *Copier) getQuerySchema() ([]Column, error) {
+	rows, err := c.sourceConn.Raw(fmt.Sprintf("SELECT * FROM (%s) AS source_query LIMIT 0", c.sourceQuery())).Rows()
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute source query: %w", err)
+	}
+	defer rows.Close()
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get query column types: %w", err)
+	}
+
+	var columns []Column
+	for _, col := range columnTypes {
+		colType := "TEXT"
+		if dbTypeName := col.DatabaseTypeName(); dbTypeName != "" {
+			colType = c.convertDataType(dbTypeName, c.sourceDBType, c.destDBType)
+		}
+		columns = append(columns, Column{
+			Name:       col.Name(),
+			Type:       colType,
+			IsNullable: true,
 		})
 	}
 
@@ -161,8 +624,25 @@ func (c *Copier) getSourceSchema() ([]Column, error) {
 
 // convertDataType converts data types between different databases
 func (c *Copier) convertDataType(sourceType string, fromDB, toDB DBType) string {
+	if mapped, ok := c.sourceTypeRule(sourceType); ok {
+		return mapped
+	}
 	sourceType = strings.ToUpper(sourceType)
 
+	if toDB == DBTypeParquet {
+		return parquetType(sourceType, fromDB)
+	}
+	if toDB == DBTypeCSV {
+		// CSV fields are untyped text
+		return sourceType
+	}
+	if fromDB == DBTypeBigQuery {
+		return bigQueryType(sourceType, toDB)
+	}
+	if toDB == DBTypeDuckDB {
+		return duckDBType(sourceType, fromDB)
+	}
+
 	// If source and destination are the same type, no conversion needed
 	if fromDB == toDB {
 		return sourceType
@@ -213,78 +693,527 @@ func (c *Copier) convertDataType(sourceType string, fromDB, toDB DBType) string
 	return "TEXT" // Default fallback
 }
 
-// ensureTableExists creates the table in the destination database if it doesn't exist
+// ensureTableExists creates the table in the destination database if it
+// doesn't exist, and otherwise applies the IfExists policy
 func (c *Copier) ensureTableExists() error {
 	// Check if table exists using GORM's migrator
-	if c.destConn.Migrator().HasTable(c.TableName) {
-		return nil
+	if c.TempTable {
+		exists, err := c.tempTableExists()
+		if err != nil {
+			return fmt.Errorf("failed to check for temporary table: %w", err)
+		}
+		if exists {
+			return nil
+		}
+	} else if c.destConn.Migrator().HasTable(c.destTableName()) {
+		return c.addMissingColumns()
+	}
+
+	// Decide what to do with a table that is absent from the destination
+	switch c.OnMissingTable {
+	case OnMissingTableSkip:
+		return errTableSkipped
+	case OnMissingTableError:
+		return fmt.Errorf("table '%s' does not exist in destination database", c.destTableName())
 	}
 
 	// Get schema from source
-	columns, err := c.getSourceSchema()
+	columns, err := c.sourceColumns()
 	if err != nil {
 		return fmt.Errorf("failed to get source table schema: %w", err)
 	}
 
-	// Create table definition
+	var sourceCollations map[string]string
+	if c.PreserveCollation && c.Query == "" {
+		sourceCollations, err = c.getSourceCollations(c.TableName)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Create table definition. CockroachDB and keys of several columns get
+	// the primary key as a table-level constraint.
+	tableKey := c.destCockroach
+	keyColumns := 0
+	for _, col := range columns {
+		if col.IsPrimary {
+			keyColumns++
+		}
+	}
+	if keyColumns > 1 {
+		tableKey = true
+	}
 	var columnDefs []string
+	var primaryKeys []string
 	for _, col := range columns {
-		def := fmt.Sprintf("%s %s", col.Name, col.Type)
+		name := quoteIdent(c.destColumn(col.Name))
+		def := fmt.Sprintf("%s %s%s", name, col.Type, c.columnCollation(col, sourceCollations))
 		if col.IsPrimary {
-			def += " PRIMARY KEY"
+			primaryKeys = append(primaryKeys, name)
+			if !tableKey {
+				def += " PRIMARY KEY"
+			}
 		}
 		if !col.IsNullable {
 			def += " NOT NULL"
 		}
+		def += c.enumCheck(col)
 		columnDefs = append(columnDefs, def)
 	}
+	if tableKey && len(primaryKeys) > 0 {
+		columnDefs = append(columnDefs, fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(primaryKeys, ", ")))
+	}
+
+	// Add unique constraints and foreign keys as table-level constraints
+	var uniques []UniqueConstraint
+	var foreignKeys []ForeignKey
+	if c.Query == "" {
+		uniques, err = c.getSourceUniqueConstraints(c.TableName)
+		if err != nil {
+			return fmt.Errorf("failed to get source unique constraints: %w", err)
+		}
+		foreignKeys, err = c.getSourceForeignKeys(c.TableName)
+		if err != nil {
+			return fmt.Errorf("failed to get source foreign keys: %w", err)
+		}
+	}
+	for _, uc := range uniques {
+		if c.hasColumns(uc.Columns) {
+			if uc.Name != "" {
+				uc.Name = c.objectName(uc.Name)
+			}
+			uc.Columns = c.quotedDestColumns(uc.Columns)
+			columnDefs = append(columnDefs, uniqueDefinition(uc))
+		}
+	}
+	if c.TempTable && len(foreignKeys) > 0 {
+		c.noticeOnce("temp-fk", "Foreign keys are not created on temporary tables")
+		for _, fk := range foreignKeys {
+			c.recordUnportable(UnportableForeignKey, fk.Name, foreignKeyDefinition(fk), "the destination table is temporary")
+		}
+		foreignKeys = nil
+	}
+	for _, fk := range foreignKeys {
+		if c.hasColumns(fk.Columns) {
+			// SQLite resolves references only when rows are checked, so only
+			// other destinations wait for a table copied later
+			deferred := c.referencesLater(fk.RefTable) && c.destDBType != DBTypeSQLite
+			// Referenced tables are expected to be copied with the same naming
+			fk.RefTable = c.destName(fk.RefTable)
+			fk.Columns = c.quotedDestColumns(fk.Columns)
+			fk.RefColumns = c.quotedDestColumns(fk.RefColumns)
+			if deferred {
+				c.deferredFKs = append(c.deferredFKs, deferredForeignKey{Table: c.destTableName(), ForeignKey: fk})
+				continue
+			}
+			columnDefs = append(columnDefs, foreignKeyDefinition(fk))
+		}
+	}
+
+	// Copy CHECK constraints: inline for SQLite and DuckDB, added with
+	// ALTER TABLE for PostgreSQL
+	var checks []CheckConstraint
+	if c.Query == "" && !c.NoChecks {
+		checks, err = c.getSourceCheckConstraints(c.TableName)
+		if err != nil {
+			return fmt.Errorf("failed to get source check constraints: %w", err)
+		}
+		checks = c.selectedChecks(c.portableChecks(checks))
+		for i := range checks {
+			checks[i].Expression = c.renameExpressionColumns(checks[i].Expression, columns)
+		}
+	}
+	if c.destDBType != DBTypePostgres {
+		for _, check := range checks {
+			columnDefs = append(columnDefs, checkDefinition(check))
+		}
+	}
+
+	var comments []Comment
+	if c.Query == "" {
+		if comments, err = c.getSourceComments(c.TableName); err != nil {
+			return err
+		}
+	}
+
+	var storageParams []string
+	if c.CopyStorageParams && c.Query == "" {
+		storageParams, err = c.getSourceStorageParams(c.TableName)
+		if err != nil {
+			return err
+		}
+	}
 
 	// Create table using SQL
-	createTableSQL := fmt.Sprintf("CREATE TABLE %s (\n  %s\n);",
-		c.TableName,
+	create := "CREATE TABLE"
+	if c.TempTable {
+		create = "CREATE TEMP TABLE"
+	}
+	createTableSQL := fmt.Sprintf("%s %s (\n  %s\n)%s;",
+		create,
+		c.destTableName(),
 		strings.Join(columnDefs, ",\n  "),
+		c.tableOptions(storageParams),
 	)
 
-	if err := c.destConn.Exec(createTableSQL).Error; err != nil {
-		return fmt.Errorf("failed to create table: %w", err)
+	var indexes []Index
+	if c.Query == "" {
+		indexes, err = c.getSourceIndexes(c.TableName)
+		if err != nil {
+			return fmt.Errorf("failed to get source indexes: %w", err)
+		}
+		indexes = c.selectedIndexes(indexes)
+	}
+
+	// Run the whole schema setup in one transaction so that a failure part way
+	// leaves the destination unchanged. PostgreSQL, SQLite and DuckDB all roll
+	// back CREATE TABLE and CREATE INDEX; VACUUM, the one SQLite statement that
+	// cannot run in a transaction, is never part of the schema setup.
+	err = c.recordDDL(c.destConn).Transaction(func(tx *gorm.DB) error {
+		if err := c.createDestSchema(tx); err != nil {
+			return err
+		}
+		if err := c.createEnumTypes(tx, columns); err != nil {
+			return err
+		}
+		if err := tx.Exec(createTableSQL).Error; err != nil {
+			return fmt.Errorf("failed to create table: %w", err)
+		}
+		if err := c.copyComments(tx, comments); err != nil {
+			return err
+		}
+
+		if c.destDBType == DBTypePostgres {
+			// NOT VALID checks would still reject copied rows that violate
+			// them, so they are added once the rows are in
+			var valid []CheckConstraint
+			c.notValidChecks = nil
+			for _, check := range checks {
+				if check.NotValid {
+					c.notValidChecks = append(c.notValidChecks, check)
+					continue
+				}
+				valid = append(valid, check)
+			}
+			if err := c.addCheckConstraints(tx, c.destTableName(), valid); err != nil {
+				return err
+			}
+		}
+
+		// Recreate secondary indexes
+		if err := c.createIndexes(tx, c.destTableName(), indexes); err != nil {
+			return err
+		}
+
+		// Recreate sequences backing serial and identity columns
+		if c.CopySequences && !c.TempTable {
+			if err := c.createSequences(tx); err != nil {
+				return err
+			}
+		}
+		return c.setDestOwner(tx)
+	})
+	if err != nil {
+		return err
 	}
 
-	fmt.Printf("Created table '%s' in destination database\n", c.TableName)
+	c.createdTables = append(c.createdTables, c.destTableName())
+	c.printf("Created table '%s' in destination database\n", c.destTableName())
 	return nil
 }
 
+// duckDBType converts a source data type to the closest DuckDB type
+func duckDBType(sourceType string, fromDB DBType) string {
+	if fromDB == DBTypeSQLite {
+		switch {
+		case strings.Contains(sourceType, "INT"):
+			return "BIGINT"
+		case strings.Contains(sourceType, "REAL"), strings.Contains(sourceType, "DOUBLE"), strings.Contains(sourceType, "FLOAT"):
+			return "DOUBLE"
+		case strings.Contains(sourceType, "BLOB"):
+			return "BLOB"
+		case strings.Contains(sourceType, "BOOL"):
+			return "BOOLEAN"
+		case strings.Contains(sourceType, "DATETIME"), strings.Contains(sourceType, "TIMESTAMP"):
+			return "TIMESTAMP"
+		case strings.Contains(sourceType, "DATE"):
+			return "DATE"
+		case strings.Contains(sourceType, "NUMERIC"), strings.Contains(sourceType, "DECIMAL"):
+			return "DECIMAL(38,10)"
+		default:
+			return "VARCHAR"
+		}
+	}
+
+	switch sourceType {
+	case "BIGINT", "INT8":
+		return "BIGINT"
+	case "INTEGER", "INT", "INT4":
+		return "INTEGER"
+	case "SMALLINT", "INT2":
+		return "SMALLINT"
+	case "DOUBLE PRECISION", "FLOAT8":
+		return "DOUBLE"
+	case "REAL", "FLOAT4":
+		return "REAL"
+	case "NUMERIC", "DECIMAL":
+		return "DECIMAL(38,10)"
+	case "BYTEA":
+		return "BLOB"
+	case "BOOLEAN", "BOOL":
+		return "BOOLEAN"
+	case "DATE":
+		return "DATE"
+	case "TIME", "TIME WITHOUT TIME ZONE":
+		return "TIME"
+	case "TIMESTAMP", "TIMESTAMP WITHOUT TIME ZONE":
+		return "TIMESTAMP"
+	case "TIMESTAMPTZ", "TIMESTAMP WITH TIME ZONE":
+		return "TIMESTAMPTZ"
+	case "INTERVAL":
+		return "INTERVAL"
+	case "UUID":
+		return "UUID"
+	default:
+		return "VARCHAR"
+	}
+}
+
+// tableOptions returns the clauses appended after the column list of a generated
+// CREATE TABLE, including the given storage parameters and, last, DestTableOptions
+func (c *Copier) tableOptions(storageParams []string) string {
+	var options []string
+	if len(storageParams) > 0 {
+		if c.destDBType == DBTypePostgres && !c.destCockroach {
+			options = append(options, "WITH ("+strings.Join(storageParams, ", ")+")")
+		} else {
+			c.noticeOnce("storage-params", "--copy-storage-params is only supported for PostgreSQL destinations and will be ignored")
+		}
+	}
+	if c.DestTablespace != "" {
+		if c.destDBType == DBTypePostgres && !c.destCockroach {
+			options = append(options, "TABLESPACE "+c.DestTablespace)
+		} else {
+			c.noticeOnce("tablespace", "--dest-tablespace is only supported for PostgreSQL destinations and will be ignored")
+		}
+	}
+	// Passed through unvalidated, for table options db-copy does not model
+	if c.DestTableOptions != "" {
+		options = append(options, strings.TrimSpace(c.DestTableOptions))
+	}
+
+	if len(options) == 0 {
+		return ""
+	}
+	return " " + strings.Join(options, " ")
+}
+
 // Copy performs the actual data copy operation
-func (c *Copier) Copy() error {
+func (c *Copier) Copy() (err error) {
+	if c.ReadConsistency != "" && !c.inSnapshot {
+		return c.withSnapshot(c.Copy)
+	}
+	defer c.startHeartbeat()()
+
+	// Record the outcome for the report. The staging copy of --atomic-swap is
+	// left to the outer copy, which records it under the live table's name.
+	skipped := ""
+	if c.stagingSuffix == "" {
+		defer func(start time.Time, rows, errs int64) {
+			c.recordTable(start, rows, errs, skipped, err)
+		}(time.Now(), c.rowsCopied.Load(), c.rowErrors.Load())
+	}
+
+	if c.sourceDBType == DBTypeBigQuery {
+		if err := c.checkBigQueryOptions(); err != nil {
+			return err
+		}
+	}
+	if c.qualifiedTables() && c.sourceDBType != DBTypePostgres {
+		return errSourceSchemas
+	}
+	if c.TempTable {
+		if err := c.checkTempTable(); err != nil {
+			return err
+		}
+	}
+	// The watermark of an Incremental copy is set per table
+	c.watermarkColumn, c.watermark = "", nil
+	if c.Incremental && (c.destDBType == DBTypeRedis || c.destDBType == DBTypeParquet || c.destDBType == DBTypeCSV) {
+		c.noticeOnce("incremental", "Redis, Parquet and CSV destinations keep no watermark; incremental copies read every row")
+	}
+	if c.InsertMethod != "" && c.InsertMethod != InsertMultiValues && (c.destDBType == DBTypeRedis || c.destDBType == DBTypeParquet || c.destDBType == DBTypeCSV) {
+		c.noticeOnce("insert-method", "--insert-method only applies to SQL destinations and will be ignored")
+	}
+	if c.destDBType == DBTypeRedis {
+		return c.copyToRedis()
+	}
+	if c.destDBType == DBTypeParquet {
+		return c.copyToParquet()
+	}
+	if c.destDBType == DBTypeCSV {
+		return c.copyToCSV()
+	}
+	if len(c.HeaderMap) > 0 {
+		c.noticeOnce("header-map", "--header-map only applies to CSV destinations and will be ignored")
+	}
+
+	// Rows are read in full before the first insert, so a copy between two
+	// tables of one database never reads and writes at the same time
+	if c.Query == "" && c.sameDatabase() && c.sameTable(c.TableName, c.destTableName()) {
+		return fmt.Errorf("source and destination are the same table %s; set --dest-table, --dest-table-prefix or --dest-table-suffix to copy within one database", c.TableName)
+	}
+
+	if c.AtomicSwap && c.stagingSuffix == "" {
+		return c.copyWithSwap()
+	}
+
+	// Warn about column conversions that may lose data before anything is written
+	if err := c.checkConversions(); err != nil {
+		return &ErrSchema{Table: c.destTableName(), Err: err}
+	}
+	if c.CompareExisting {
+		if err := c.compareDestSchema(); err != nil {
+			return &ErrSchema{Table: c.destTableName(), Err: err}
+		}
+	}
+
 	// Ensure destination table exists with correct schema
+	createdBefore := len(c.createdTables)
 	if err := c.ensureTableExists(); err != nil {
-		return err
+		if errors.Is(err, errTableSkipped) {
+			skipped = "not present in destination database"
+			c.printf("Skipping table '%s': %s\n", c.destTableName(), skipped)
+			return nil
+		}
+		return &ErrSchema{Table: c.destTableName(), Err: err}
+	}
+
+	created := len(c.createdTables) > createdBefore
+
+	if c.SchemaOnly {
+		if created {
+			if err := c.finishCreatedTable(); err != nil {
+				return err
+			}
+		}
+		c.copiedTables = append(c.copiedTables, c.destTableName())
+		return nil
+	}
+
+	// Get the primary key column name. Query results have no primary key,
+	// so every row they return is inserted.
+	var primaryKeyColumn string
+	if c.Query == "" {
+		var err error
+		primaryKeyColumn, err = c.getPrimaryKeyColumnName()
+		if err != nil {
+			return &ErrSchema{Table: c.destTableName(), Err: fmt.Errorf("failed to get primary key column name: %w", err)}
+		}
+		// Without its primary key column the rows cannot be matched against the destination
+		if primaryKeyColumn != "" && !c.hasColumns([]string{primaryKeyColumn}) {
+			primaryKeyColumn = ""
+		}
+		if err := c.startIncremental(primaryKeyColumn); err != nil {
+			return &ErrSchema{Table: c.destTableName(), Err: err}
+		}
 	}
 
-	// Get the primary key column name.
-	primaryKeyColumn, err := c.getPrimaryKeyColumnName()
+	// Keep multi-row inserts under the destination's bind parameter limit
+	columns, err := c.sourceColumns()
 	if err != nil {
-		return fmt.Errorf("failed to get primary key column name: %w", err)
+		return &ErrSchema{Table: c.destTableName(), Err: fmt.Errorf("failed to get source table schema: %w", err)}
+	}
+	batchSize := c.safeBatchSize(len(columns))
+	tuner := c.newBatchTuner(len(columns))
+	if tuner != nil {
+		// Rows are split into inserts of the tuned size as they are written
+		batchSize = tuner.max
+	}
+	reader := c.newReader(batchSize)
+	defer reader.Close()
+	if tuner != nil {
+		c.printf("Using adaptive batch sizes of %d to %d for table %s\n", tuner.min, tuner.max, c.destTableName())
+	} else {
+		c.printf("Using batch size %d for table %s\n", batchSize, c.destTableName())
+	}
+
+	// Read every row first: rows already in the destination are filtered out
+	// and the values are checked before anything is inserted
+	records, err := readAll(c.copyContext(), reader)
+	if err != nil {
+		return err
+	}
+	if err := c.reportDuplicates(len(records), columns); err != nil {
+		return err
+	}
+	if c.RowFilter != nil {
+		read := len(records)
+		if records, err = c.filterRows(records, 1); err != nil {
+			return err
+		}
+		c.printf("Row filter kept %d of %d rows\n", len(records), read)
+	}
+	if err := c.applyLookups(records); err != nil {
+		return err
+	}
+	c.renameRecordColumns(records)
+
+	// An empty source often means the wrong table or query was given. Above
+	// the watermark of an incremental copy it only means nothing is new.
+	if len(records) == 0 && c.watermark == nil {
+		source := c.TableName
+		if c.Query != "" {
+			source = "query"
+		}
+		if c.FailOnEmpty {
+			return &ErrEmpty{Source: source}
+		}
+		zap.L().Warn("Source returned no rows; nothing was copied", zap.String("source", source))
+	}
+
+	// Catch values the destination column types reject before inserting
+	if err := c.parseTimes(records); err != nil {
+		return err
+	}
+	if err := c.checkValues(records); err != nil {
+		return err
 	}
 
-	// Get the data from source table using GORM
-	var records []map[string]interface{}
-	if err := c.sourceConn.Table(c.TableName).Find(&records).Error; err != nil {
-		return fmt.Errorf("failed to read from source table: %w", err)
+	// --set-now and --default may fill destination columns the source lacks
+	destColumns, err := c.destColumnNames(columns)
+	if err != nil {
+		return err
 	}
+	nowValues := c.setNowValues(destColumns)
+	defaults := c.defaultValues(destColumns)
 
-	// Begin transaction in destination database
-	tx := c.destConn.Begin()
+	// Begin transaction in destination database, unless every batch autocommits.
+	// Concurrent workers cannot share a transaction, so they always autocommit.
+	autocommit := c.NoTransaction || c.Workers > 1
+	tx := c.destConn
+	if !autocommit {
+		tx = c.destConn.Begin()
+	}
+	rollback := func() {
+		if !autocommit {
+			tx.Rollback()
+		}
+	}
 	defer func() {
 		if r := recover(); r != nil {
-			tx.Rollback()
+			rollback()
 		}
 	}()
 
 	// Identify existing primary keys in the destination table
 	var existingPrimaryKeys []interface{}
-	if err := c.destConn.Model(&struct{}{}).Table(c.TableName).Pluck(primaryKeyColumn, &existingPrimaryKeys).Error; err != nil {
-		tx.Rollback()
-		return fmt.Errorf("failed to get existing primary keys from destination table: %w", err)
+	if primaryKeyColumn != "" {
+		primaryKeyColumn = c.destColumn(primaryKeyColumn)
+		if err := c.destConn.Model(&struct{}{}).Table(c.destTableName()).Pluck(primaryKeyColumn, &existingPrimaryKeys).Error; err != nil {
+			rollback()
+			return fmt.Errorf("failed to get existing primary keys from destination table: %w", err)
+		}
 	}
 
 	existingPrimaryKeyMap := make(map[interface{}]bool)
@@ -294,14 +1223,39 @@ func (c *Copier) Copy() error {
 
 	// Copy data in batches, filtering out existing records
 	totalRecords := len(records)
-	for i := 0; i < totalRecords; i += c.BatchSize {
-		end := i + c.BatchSize
-		if end > totalRecords {
-			end = totalRecords
+	batchNumber := 0
+	lastCommittedBatch := 0
+	limiter := c.newRateLimiter(batchSize)
+	start := time.Now()
+	insertedRows := 0
+	skippedBefore := c.rowErrors.Load()
+	var violation error
+	violationSkipped := 0
+	var pendingBatches [][]map[string]interface{}
+	writer := c.newTableWriter(tx, !autocommit)
+	defer writer.closeStatements()
+	write := c.writeBatch
+	if tuner != nil {
+		write = func(w *tableWriter, batch []map[string]interface{}) (int, error) {
+			return c.writeBatchAdaptive(w, batch, tuner)
+		}
+	}
+	defer c.startProgress(totalRecords)()
+	for _, bounds := range batchBounds(totalRecords, batchSize) {
+		if err := c.interrupted(); err != nil {
+			rollback()
+			if c.NoTransaction {
+				return fmt.Errorf("%w (last committed batch: %d)", err, lastCommittedBatch)
+			}
+			return err
 		}
 
 		var batch []map[string]interface{}
-		for _, record := range records[i:end] {
+		for _, record := range records[bounds[0]:bounds[1]] {
+			if primaryKeyColumn == "" {
+				batch = append(batch, record)
+				continue
+			}
 			primaryKeyValue, ok := record[primaryKeyColumn]
 			if !ok {
 				// Handle cases where primary key is missing (should probably be logged)
@@ -312,27 +1266,152 @@ func (c *Copier) Copy() error {
 			}
 		}
 
-		if len(batch) > 0 {
-			if err := tx.Table(c.TableName).Create(&batch).Error; err != nil {
-				tx.Rollback()
-				return fmt.Errorf("failed to insert batch into destination table: %w", err)
+		if len(batch) == 0 {
+			c.printf("No new records to copy in current batch\n")
+			continue
+		}
+		applySetNow(batch, nowValues)
+		applyDefaults(batch, defaults)
+
+		// Oversized batches are flushed in smaller pieces
+		for _, chunk := range c.splitBatchByBytes(batch) {
+			batchNumber++
+
+			// After a unique violation the remaining rows are only counted
+			if violation != nil {
+				violationSkipped += len(chunk)
+				continue
 			}
-			fmt.Printf("Copied %d records (new records only)\n", len(batch))
-		} else {
-			fmt.Println("No new records to copy in current batch")
+			if c.Workers > 1 {
+				insertedRows += len(chunk)
+				pendingBatches = append(pendingBatches, chunk)
+				continue
+			}
+
+			if err := c.throttle(limiter, len(chunk)); err != nil {
+				rollback()
+				return fmt.Errorf("rate limiter failed: %w", err)
+			}
+			written, err := write(writer, chunk)
+			var uniqueErr *ErrUniqueViolation
+			if errors.As(err, &uniqueErr) {
+				violation = err
+				violationSkipped += len(chunk)
+				continue
+			}
+			if err != nil {
+				rollback()
+				var insertErr error = &ErrInsert{Table: c.destTableName(), Batch: batchNumber, Err: err}
+				var tooMany *ErrTooManyErrors
+				if errors.As(err, &tooMany) {
+					insertErr = err
+				}
+				if c.NoTransaction {
+					return fmt.Errorf("%w (last committed batch: %d)", insertErr, lastCommittedBatch)
+				}
+				return insertErr
+			}
+			insertedRows += written
+			c.printBatch("Copied %d records (new records only)\n", written)
+			c.reportProgress(written)
+			lastCommittedBatch = batchNumber
+		}
+	}
+
+	if c.Workers > 1 {
+		var skipped int
+		if skipped, err = c.insertBatchesConcurrently(pendingBatches, limiter); err != nil {
+			var uniqueErr *ErrUniqueViolation
+			if !errors.As(err, &uniqueErr) {
+				return err
+			}
+			violation, violationSkipped = err, skipped
+		}
+	} else if !autocommit {
+		if err := writer.Close(); err != nil {
+			return err
+		}
+	} else {
+		c.printf("Last committed batch: %d\n", lastCommittedBatch)
+	}
+
+	if violation != nil {
+		c.reportSkippedTable(violation, violationSkipped)
+	}
+	if skipped := c.rowErrors.Load() - skippedBefore; skipped > 0 {
+		c.printf("Skipped %d rows that failed to insert into %s\n", skipped, c.destTableName())
+	}
+
+	if c.CopySequences {
+		if err := c.syncSequences(); err != nil {
+			return err
+		}
+	}
+
+	if created {
+		if err := c.finishCreatedTable(); err != nil {
+			return err
 		}
 	}
 
-	if err := tx.Commit().Error; err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+	if err := c.runMaintenance(); err != nil {
+		return err
+	}
+
+	if limiter != nil {
+		elapsed := time.Since(start).Seconds()
+		if elapsed > 0 {
+			c.printf("Effective rate: %.0f rows/sec (limit %d rows/sec)\n", float64(insertedRows)/elapsed, c.RateLimit)
+		}
 	}
 
-	fmt.Printf("Successfully copied %d records from %s to destination database\n", totalRecords, c.TableName)
+	source := c.TableName
+	if c.Query != "" {
+		source = "query"
+	}
+	c.printf("Successfully copied %d records from %s to destination table %s\n", totalRecords-violationSkipped, source, c.destTableName())
+	c.copiedTables = append(c.copiedTables, c.destTableName())
+	if err := c.printWatermark(); err != nil {
+		return err
+	}
+
+	if c.Verify == VerifySample && c.Query == "" {
+		result, err := c.verifySample(columns)
+		if err != nil {
+			return fmt.Errorf("sample verification failed: %w", err)
+		}
+		if result.SampleSize > 0 {
+			c.sampleResults = append(c.sampleResults, result)
+			c.printf("Sample verification of %s: %d rows checked, %d passed, %d failed\n", result.Table, result.SampleSize, result.Passed, result.Failed)
+			if result.Failed > 0 {
+				return fmt.Errorf("sample verification of %s found %d of %d rows missing or different", result.Table, result.Failed, result.SampleSize)
+			}
+		}
+	}
 	return nil
 }
 
+// finishCreatedTable adds the NOT VALID checks and triggers of a table this
+// copy created. They come after the rows, so that neither applies to copied
+// rows.
+func (c *Copier) finishCreatedTable() error {
+	checks := c.notValidChecks
+	c.notValidChecks = nil
+	if err := c.addCheckConstraints(c.destConn, c.destTableName(), checks); err != nil {
+		return err
+	}
+	return c.copyTriggers()
+}
+
 // getPrimaryKeyColumnName retrieves the name of the primary key column for the given table.
 func (c *Copier) getPrimaryKeyColumnName() (string, error) {
+	if c.explicitKey() != "" {
+		return c.implicitKeyColumn()
+	}
+	if len(c.DestPrimaryKey) > 1 {
+		// Rows are matched on one column, which a composite key does not give
+		return "", nil
+	}
 	var primaryKeyColumns []struct {
 		Name string
 	}
@@ -340,25 +1419,27 @@ func (c *Copier) getPrimaryKeyColumnName() (string, error) {
 	// This part is database-specific and will need adaptation depending on DB type.
 	if c.sourceDBType == DBTypeSQLite {
 		//SQLite
-		if err := c.sourceConn.Raw("PRAGMA table_info(?)", c.TableName).Scan(&primaryKeyColumns).Error; err != nil {
+		if err := c.sourceConn.Raw("SELECT name FROM pragma_table_info(?) WHERE pk > 0 ORDER BY pk", c.TableName).Scan(&primaryKeyColumns).Error; err != nil {
 			return "", fmt.Errorf("failed to get primary key information: %w", err)
 		}
-		for _, col := range primaryKeyColumns {
-			if col.Name == "ID" {
-				return col.Name, nil
-			}
+		if len(primaryKeyColumns) > 0 {
+			return primaryKeyColumns[0].Name, nil
 		}
-		return "", fmt.Errorf("primary key not found for table: %s", c.TableName)
+		return c.primaryKeyFallback()
+	} else if c.sourceDBType == DBTypeBigQuery {
+		// BigQuery does not enforce keys, so every row is inserted
+		return "", nil
 	} else if c.sourceDBType == DBTypePostgres {
 		//PostgreSQL
 		//Adapt this part appropriately for Postgres if 'ID' is not the primary key.
-		if err := c.sourceConn.Raw(`SELECT column_name FROM information_schema.columns WHERE table_name = $1 AND column_default LIKE 'nextval%'`, c.TableName).Scan(&primaryKeyColumns).Error; err != nil {
+		schema, table := splitSchema(c.TableName)
+		if err := c.sourceConn.Raw(`SELECT column_name FROM information_schema.columns WHERE table_schema = COALESCE(NULLIF($1, ''), current_schema()) AND table_name = $2 AND column_default LIKE 'nextval%'`, schema, table).Scan(&primaryKeyColumns).Error; err != nil {
 			return "", fmt.Errorf("failed to get primary key information: %w", err)
 		}
 		if len(primaryKeyColumns) > 0 {
 			return primaryKeyColumns[0].Name, nil
 		}
-		return "", fmt.Errorf("primary key not found for table: %s", c.TableName)
+		return c.primaryKeyFallback()
 	}
 	return "", fmt.Errorf("Unsupported database type")
 }
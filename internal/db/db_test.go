@@ -0,0 +1,150 @@
+package db
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// postgresTestDSN names the environment variable holding the connection
+// string of a PostgreSQL database the tests may create tables in. Tests that
+// need PostgreSQL are skipped without it.
+const postgresTestDSN = "DBCOPY_TEST_POSTGRES_DSN"
+
+// wantNullable maps the columns of a test table to whether they are nullable
+var wantNullable = map[string]bool{
+	"id":        false,
+	"code":      false,
+	"email":     false,
+	"nickname":  true,
+	"status":    false,
+	"parent_id": true,
+}
+
+// checkNullability checks that the columns of a schema have the nullability of
+// wantNullable, and that each column appears once
+func checkNullability(t *testing.T, columns []Column) {
+	t.Helper()
+	seen := make(map[string]bool)
+	for _, col := range columns {
+		if seen[col.Name] {
+			t.Errorf("column %s appears more than once", col.Name)
+		}
+		seen[col.Name] = true
+		if want, ok := wantNullable[col.Name]; ok && col.IsNullable != want {
+			t.Errorf("column %s has IsNullable %v, want %v", col.Name, col.IsNullable, want)
+		}
+	}
+	if len(seen) != len(wantNullable) {
+		t.Errorf("schema has %d columns, want %d", len(seen), len(wantNullable))
+	}
+}
+
+// checkDestNotNull checks that the NOT NULL columns of wantNullable are
+// NOT NULL in the SQLite destination, and the others are not
+func checkDestNotNull(t *testing.T, c *Copier, table string) {
+	t.Helper()
+	var columns []struct {
+		Name    string
+		NotNull bool
+	}
+	if err := c.destConn.Raw("SELECT name, \"notnull\" AS not_null FROM pragma_table_info(?)", table).Scan(&columns).Error; err != nil {
+		t.Fatalf("read destination columns: %v", err)
+	}
+	for _, col := range columns {
+		if want := !wantNullable[col.Name]; col.NotNull != want {
+			t.Errorf("destination column %s has NOT NULL %v, want %v", col.Name, col.NotNull, want)
+		}
+	}
+}
+
+func TestSQLiteNullability(t *testing.T) {
+	c := newTestCopier(t, "members", 100,
+		`CREATE TABLE members (
+			id INTEGER PRIMARY KEY,
+			code TEXT NOT NULL UNIQUE CHECK (code <> ''),
+			email TEXT NOT NULL,
+			nickname TEXT,
+			status TEXT NOT NULL DEFAULT 'active',
+			parent_id INTEGER REFERENCES members (id)
+		)`,
+		"INSERT INTO members (id, code, email, nickname, parent_id) VALUES (1, 'a', 'a@example.com', NULL, NULL), (2, 'b', 'b@example.com', 'bee', 1)",
+	)
+	columns, err := c.getSourceSchema("members")
+	if err != nil {
+		t.Fatalf("getSourceSchema: %v", err)
+	}
+	checkNullability(t, columns)
+
+	if err := c.Copy(); err != nil {
+		t.Fatalf("Copy: %v", err)
+	}
+	checkDestNotNull(t, c, "members")
+}
+
+func TestPostgresNullability(t *testing.T) {
+	dsn := os.Getenv(postgresTestDSN)
+	if dsn == "" {
+		t.Skipf("%s is not set", postgresTestDSN)
+	}
+
+	c := NewCopier(dsn, filepath.Join(t.TempDir(), "dest.db"), "dbcopy_test_members", 100)
+	c.Output = io.Discard
+	if err := c.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+
+	// code takes part in a primary key, a unique and a check constraint, which
+	// must not turn into several rows of the schema
+	for _, stmt := range []string{
+		"DROP TABLE IF EXISTS dbcopy_test_members",
+		`CREATE TABLE dbcopy_test_members (
+			id INTEGER NOT NULL,
+			code TEXT NOT NULL UNIQUE CHECK (code <> ''),
+			email TEXT NOT NULL,
+			nickname TEXT,
+			status TEXT NOT NULL DEFAULT 'active',
+			parent_id INTEGER,
+			PRIMARY KEY (id, code),
+			FOREIGN KEY (parent_id, code) REFERENCES dbcopy_test_members (id, code)
+		)`,
+		"INSERT INTO dbcopy_test_members (id, code, email, nickname, parent_id) VALUES (1, 'a', 'a@example.com', NULL, NULL), (2, 'b', 'b@example.com', 'bee', NULL)",
+	} {
+		if err := c.sourceConn.Exec(stmt).Error; err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	t.Cleanup(func() { c.sourceConn.Exec("DROP TABLE IF EXISTS dbcopy_test_members") })
+
+	columns, err := c.getSourceSchema("dbcopy_test_members")
+	if err != nil {
+		t.Fatalf("getSourceSchema: %v", err)
+	}
+	checkNullability(t, columns)
+
+	if err := c.Copy(); err != nil {
+		t.Fatalf("Copy: %v", err)
+	}
+	checkDestNotNull(t, c, "dbcopy_test_members")
+}
+
+func TestCopyQueryWithTrailingSemicolon(t *testing.T) {
+	for _, query := range []string{
+		"SELECT id, name FROM items WHERE id <= 3",
+		"SELECT id, name FROM items WHERE id <= 3;",
+		"  SELECT id, name FROM items WHERE id <= 3 ;;\n",
+	} {
+		c := newTestCopier(t, "", 100, seedItems(10)...)
+		c.Query = query
+		c.DestTable = "picked"
+		if err := c.Copy(); err != nil {
+			t.Errorf("Copy of query %q: %v", query, err)
+			continue
+		}
+		if got := countRows(t, c.destConn, "picked"); got != 3 {
+			t.Errorf("query %q copied %d rows, want 3", query, got)
+		}
+	}
+}
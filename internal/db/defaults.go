@@ -0,0 +1,29 @@
+package db
+
+// defaultValues returns the Defaults for the columns the destination table has
+func (c *Copier) defaultValues(destColumns map[string]bool) map[string]string {
+	values := make(map[string]string)
+	for name, value := range c.Defaults {
+		if destColumns[name] {
+			values[name] = value
+		}
+	}
+	return values
+}
+
+// applyDefaults sets the given columns of each record in a batch to their
+// default value where they are NULL or missing. Missing columns are
+// destination columns the source does not have. The destination converts the
+// text value to the column type.
+func applyDefaults(batch []map[string]interface{}, defaults map[string]string) {
+	if len(defaults) == 0 {
+		return
+	}
+	for _, record := range batch {
+		for name, value := range defaults {
+			if v, ok := record[name]; !ok || v == nil {
+				record[name] = value
+			}
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// distinctRows restricts a read of the source table to distinct rows. With
+// Distinct rows equal in every selected column are read once. With DistinctOn
+// rows are equal when those columns are, and the first row of each group in
+// the order of those columns is kept: DISTINCT ON on PostgreSQL, GROUP BY on
+// SQLite, which takes the other columns from one row of the group.
+func (c *Copier) distinctRows(query *gorm.DB, selects []string) *gorm.DB {
+	if len(c.DistinctOn) == 0 {
+		if c.Distinct {
+			return query.Distinct(selects)
+		}
+		return query
+	}
+
+	on := make([]string, len(c.DistinctOn))
+	for i, col := range c.DistinctOn {
+		on[i] = quoteIdent(col)
+	}
+	keys := strings.Join(on, ", ")
+	if c.sourceDBType != DBTypePostgres {
+		return query.Group(keys).Order(keys)
+	}
+	list := "*"
+	if len(selects) > 0 {
+		list = strings.Join(selects, ", ")
+	}
+	return query.Select(fmt.Sprintf("DISTINCT ON (%s) %s", keys, list)).Order(keys)
+}
+
+// reportDuplicates prints how many source rows were collapsed into the
+// distinct rows that were read. The rows are counted with the same --last
+// and watermark conditions as the read, so rows outside them are not taken
+// for duplicates.
+func (c *Copier) reportDuplicates(distinct int, columns []Column) error {
+	if (!c.Distinct && len(c.DistinctOn) == 0) || c.Query != "" {
+		return nil
+	}
+	query := c.sourceConn.WithContext(c.copyContext()).Table(c.TableName)
+	if c.TimeColumn != "" && hasSourceColumn(columns, c.TimeColumn) {
+		query = c.timeFilter(query)
+	}
+	query = c.watermarkRange(query)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return fmt.Errorf("failed to count source rows: %w", err)
+	}
+	c.printf("Collapsed %d duplicate rows of %s into %d distinct rows\n", total-int64(distinct), c.TableName, distinct)
+	return nil
+}
@@ -0,0 +1,36 @@
+package db
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReportDuplicatesWithinTimeRange(t *testing.T) {
+	c := newTestCopier(t, "events", 100,
+		"CREATE TABLE events (id INTEGER PRIMARY KEY, kind TEXT, created_at DATETIME)",
+		`INSERT INTO events (id, kind, created_at) VALUES
+			(1, 'login', datetime('now', '-1 hour')),
+			(2, 'login', datetime('now', '-1 hour')),
+			(3, 'logout', datetime('now', '-3 hours')),
+			(4, 'login', datetime('now', '-10 days')),
+			(5, 'login', datetime('now', '-10 days'))`,
+	)
+	var out bytes.Buffer
+	c.Output = &out
+	c.Distinct = true
+	c.Columns = map[string][]string{"events": {"kind", "created_at"}}
+	c.DestTable = "kinds"
+	c.TimeColumn = "created_at"
+	c.Last = 24 * time.Hour
+	if err := c.Copy(); err != nil {
+		t.Fatalf("Copy: %v", err)
+	}
+
+	// Three rows lie within the last day and two of them are distinct; the
+	// two older duplicates are not counted
+	if want := "Collapsed 1 duplicate rows of events into 2 distinct rows"; !strings.Contains(out.String(), want) {
+		t.Errorf("output does not contain %q:\n%s", want, out.String())
+	}
+}
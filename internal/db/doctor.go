@@ -0,0 +1,269 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// doctorTimeout bounds how long Diagnose waits for a server to answer
+const doctorTimeout = 10 * time.Second
+
+// Check is the outcome of one step of Diagnose
+type Check struct {
+	Database string `json:"database"` // "source" or "destination"
+	Step     string `json:"step"`
+	OK       bool   `json:"ok"`
+	Detail   string `json:"detail"`
+	Hint     string `json:"hint,omitempty"`
+}
+
+// Diagnose checks that the source and destination connection strings parse,
+// that both databases answer, which dialect and server version they run and,
+// when TableName is set, that the table can be read from the source. Unlike
+// Connect it never creates a database or file, and it copies nothing.
+func (c *Copier) Diagnose() []Check {
+	c.applyConnParams()
+
+	sourceType := c.sourceDBType
+	if strings.HasPrefix(c.SourceDB, "duckdb://") || strings.HasSuffix(c.SourceDB, ".duckdb") {
+		sourceType = DBTypeDuckDB
+	}
+	checks := c.diagnose("source", c.SourceDB, c.SourcePassword, sourceType)
+	return append(checks, c.diagnose("destination", c.DestDB, c.DestPassword, c.destDBType)...)
+}
+
+// diagnose runs the checks for one side. Each step needs the previous one to
+// have passed, so the first failure ends the checks for that side.
+func (c *Copier) diagnose(database, dsn, password string, dbType DBType) []Check {
+	var checks []Check
+	pass := func(step, format string, args ...interface{}) {
+		checks = append(checks, Check{Database: database, Step: step, OK: true, Detail: fmt.Sprintf(format, args...)})
+	}
+	fail := func(step string, err error) []Check {
+		return append(checks, Check{Database: database, Step: step, Detail: err.Error(), Hint: diagnosisHint(err)})
+	}
+
+	// Parse the connection string
+	switch dbType {
+	case DBTypePostgres:
+		withPw, err := withPassword(dsn, password)
+		if err != nil {
+			return fail("parse", err)
+		}
+		u, err := url.Parse(withPw)
+		if err != nil {
+			return fail("parse", fmt.Errorf("failed to parse connection string: %w", err))
+		}
+		pass("parse", "PostgreSQL server %s, database %s", u.Host, strings.TrimPrefix(u.Path, "/"))
+		if u.Query().Get("connect_timeout") == "" {
+			q := u.Query()
+			q.Set("connect_timeout", fmt.Sprint(int(doctorTimeout.Seconds())))
+			u.RawQuery = q.Encode()
+		}
+		dsn = u.String()
+	case DBTypeParquet:
+		path := strings.TrimPrefix(dsn, "parquet://")
+		dir := path
+		if strings.HasSuffix(strings.ToLower(path), ".parquet") {
+			dir = filepath.Dir(path)
+		}
+		if _, err := os.Stat(dir); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fail("parse", fmt.Errorf("Parquet destination %s: %w", path, err))
+		}
+		pass("parse", "Parquet destination %s; files are written by the copy", path)
+		return checks
+	case DBTypeCSV:
+		path := strings.TrimPrefix(dsn, "csv://")
+		dir := path
+		if strings.HasSuffix(strings.ToLower(path), ".csv") {
+			dir = filepath.Dir(path)
+		}
+		if _, err := os.Stat(dir); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fail("parse", fmt.Errorf("CSV destination %s: %w", path, err))
+		}
+		pass("parse", "CSV destination %s; files are written by the copy", path)
+		return checks
+	case DBTypeRedis:
+		opts, err := redis.ParseURL(dsn)
+		if err != nil {
+			return fail("parse", fmt.Errorf("invalid Redis URL: %w", err))
+		}
+		pass("parse", "Redis server %s, database %d", opts.Addr, opts.DB)
+	default:
+		if database == "source" && dbType == DBTypeDuckDB {
+			return fail("parse", errors.New("DuckDB is only supported as a destination"))
+		}
+		kind := "SQLite"
+		if dbType == DBTypeDuckDB {
+			kind = "DuckDB"
+		} else if database == "source" && isSQLDump(dsn) {
+			kind = "SQL dump"
+		}
+		if dbType == DBTypeSQLite && isInMemorySQLite(dsn) {
+			pass("parse", "in-memory SQLite database, which lasts only as long as the copy")
+			break
+		}
+		path := databaseFilePath(dsn)
+		_, err := os.Stat(path)
+		switch {
+		case err == nil:
+			pass("parse", "%s file %s", kind, path)
+		case errors.Is(err, os.ErrNotExist) && database == "destination":
+			if _, err := os.Stat(filepath.Dir(path)); err != nil {
+				return fail("parse", fmt.Errorf("directory of %s file %s: %w", kind, path, err))
+			}
+			pass("parse", "%s file %s does not exist yet and will be created by the copy", kind, path)
+			return checks
+		default:
+			return fail("parse", fmt.Errorf("%s file %s: %w", kind, path, err))
+		}
+	}
+
+	// Connect and ping
+	ctx, cancel := context.WithTimeout(context.Background(), doctorTimeout)
+	defer cancel()
+	if dbType == DBTypeRedis {
+		client, err := openRedis(dsn)
+		if err != nil {
+			return fail("connect", err)
+		}
+		defer client.Close()
+		pass("connect", "Redis answered a ping")
+		info, err := client.InfoMap(ctx, "server").Result()
+		if err != nil {
+			return fail("version", err)
+		}
+		pass("version", "Redis %s", info["Server"]["redis_version"])
+		return checks
+	}
+
+	var conn *gorm.DB
+	var err error
+	switch dbType {
+	case DBTypePostgres:
+		conn, err = gorm.Open(postgres.Open(dsn), gormConfig())
+	case DBTypeDuckDB:
+		conn, err = openDuckDB(dsn)
+	default:
+		if database == "source" && isSQLDump(dsn) {
+			// Loading the dump is what connecting to it means
+			var keepalive *sql.Conn
+			if conn, keepalive, err = openSQLDump(dsn); err == nil {
+				defer keepalive.Close()
+			}
+		} else {
+			conn, err = gorm.Open(sqlite.Open(dsn), gormConfig())
+		}
+	}
+	if err != nil {
+		return fail("connect", err)
+	}
+	sqlDB, err := conn.DB()
+	if err != nil {
+		return fail("connect", err)
+	}
+	defer sqlDB.Close()
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return fail("connect", err)
+	}
+	conn = conn.WithContext(ctx)
+	pass("connect", "connected")
+
+	// Report the dialect and server version
+	var version string
+	switch dbType {
+	case DBTypeSQLite:
+		err = conn.Raw("SELECT 'SQLite ' || sqlite_version()").Scan(&version).Error
+	case DBTypeDuckDB:
+		err = conn.Raw("SELECT 'DuckDB ' || version()").Scan(&version).Error
+	default:
+		err = conn.Raw("SELECT version()").Scan(&version).Error
+	}
+	if err != nil {
+		return fail("version", err)
+	}
+	pass("version", "%s", version)
+
+	if c.TableName == "" || c.Query != "" {
+		return checks
+	}
+
+	// Check the table
+	if database == "source" {
+		rows, err := conn.Raw(fmt.Sprintf("SELECT * FROM %s LIMIT 1", c.TableName)).Rows()
+		if err != nil {
+			return fail("table", fmt.Errorf("table %s is not readable: %w", c.TableName, err))
+		}
+		rows.Close()
+		pass("table", "table %s exists and is readable", c.TableName)
+		return checks
+	}
+
+	table := c.destTableName()
+	if !conn.Migrator().HasTable(table) {
+		pass("table", "table %s does not exist yet and will be created by the copy", table)
+		return checks
+	}
+	if dbType == DBTypePostgres {
+		var canInsert bool
+		if err := conn.Raw("SELECT has_table_privilege(?, 'INSERT')", table).Scan(&canInsert).Error; err != nil {
+			return fail("table", err)
+		}
+		if !canInsert {
+			return fail("table", fmt.Errorf("permission denied: the user cannot insert into table %s", table))
+		}
+	}
+	pass("table", "table %s exists and accepts inserts", table)
+	return checks
+}
+
+// databaseFilePath returns the file of an SQLite or DuckDB connection string
+func databaseFilePath(dsn string) string {
+	path := strings.TrimPrefix(strings.TrimPrefix(dsn, "duckdb://"), "file:")
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+	return path
+}
+
+// diagnosisHint suggests a fix for a failure seen by Diagnose, or returns ""
+func diagnosisHint(err error) string {
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "ssl is not enabled on the server"):
+		return "The server does not accept SSL: add sslmode=disable to the connection string"
+	case strings.Contains(msg, "no pg_hba.conf entry") && strings.Contains(msg, "no encryption"):
+		return "The server requires SSL: add sslmode=require to the connection string"
+	case strings.Contains(msg, "password authentication failed"):
+		return "Check the user name and password, or pass the password with --password-prompt"
+	case strings.Contains(msg, "database") && strings.Contains(msg, "does not exist"):
+		return "Create the database first or, for the destination, copy with --create-dest-db"
+	case strings.Contains(msg, "connection refused"):
+		return "Nothing is listening there: check that the server is running and the host and port are right"
+	case strings.Contains(msg, "no such host"):
+		return "The host name cannot be resolved: check the host in the connection string"
+	case strings.Contains(msg, "timeout") || strings.Contains(msg, "deadline exceeded"):
+		return "The server did not answer in time: check the host, the port and any firewall in between"
+	case strings.Contains(msg, "no such file or directory"):
+		return "Check the path; an SQLite source must already exist"
+	case strings.Contains(msg, "permission denied"):
+		return "Check the file permissions, or grant the database user access to the object"
+	case strings.Contains(msg, "not a database"):
+		return "The file is not an SQLite database"
+	case strings.Contains(msg, "no such table") || strings.Contains(msg, "does not exist"):
+		return "Check the table name and that the user may read it"
+	}
+	return ""
+}
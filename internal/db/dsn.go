@@ -0,0 +1,175 @@
+package db
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// NeedsPassword reports whether a connection string is for a server database
+// and does not carry a password of its own
+func NeedsPassword(dsn string) bool {
+	if !strings.HasPrefix(dsn, "postgres://") {
+		return false
+	}
+	u, err := url.Parse(dsn)
+	if err != nil || u.User == nil {
+		return true
+	}
+	_, ok := u.User.Password()
+	return !ok
+}
+
+// IsReadableDSN reports whether a connection string names a database that can
+// be copied from. DuckDB, Parquet, CSV and Redis are only supported as destinations.
+func IsReadableDSN(dsn string) bool {
+	duckDB := strings.HasPrefix(dsn, "duckdb://") || strings.HasSuffix(dsn, ".duckdb")
+	return !duckDB && !isParquetDSN(dsn) && !isCSVDSN(dsn) && !isRedisDSN(dsn)
+}
+
+// withPassword returns the connection string with its password replaced.
+// SQLite paths and empty passwords are returned unchanged.
+func withPassword(dsn, password string) (string, error) {
+	if password == "" || !strings.HasPrefix(dsn, "postgres://") {
+		return dsn, nil
+	}
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse connection string: %w", err)
+	}
+	username := ""
+	if u.User != nil {
+		username = u.User.Username()
+	}
+	u.User = url.UserPassword(username, password)
+	return u.String(), nil
+}
+
+// withDSNParams returns the connection string with extra driver parameters in
+// its query string, replacing parameters of the same name. SQLite paths take
+// them the same way as URLs.
+func withDSNParams(dsn string, params map[string]string) (string, error) {
+	if len(params) == 0 {
+		return dsn, nil
+	}
+	base, rawQuery, _ := strings.Cut(dsn, "?")
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse connection string parameters: %w", err)
+	}
+	for key, value := range params {
+		query.Set(key, value)
+	}
+	return base + "?" + query.Encode(), nil
+}
+
+// ensureDatabase creates the database named in a PostgreSQL connection string
+// if it does not exist yet, connecting through the server's maintenance
+// database to do so
+func (c *Copier) ensureDatabase(dsn string) error {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return fmt.Errorf("failed to parse connection string: %w", err)
+	}
+	name := strings.TrimPrefix(u.Path, "/")
+	if name == "" || name == "postgres" {
+		return nil
+	}
+
+	maintenance := *u
+	maintenance.Path = "/postgres"
+	conn, err := gorm.Open(postgres.Open(maintenance.String()), gormConfig())
+	if err != nil {
+		return fmt.Errorf("failed to connect to maintenance database: %w", err)
+	}
+	defer func() {
+		if sqlDB, err := conn.DB(); err == nil {
+			sqlDB.Close()
+		}
+	}()
+
+	var exists bool
+	if err := conn.Raw("SELECT EXISTS (SELECT 1 FROM pg_database WHERE datname = ?)", name).Scan(&exists).Error; err != nil {
+		return fmt.Errorf("failed to check for database %s: %w", name, err)
+	}
+	if exists {
+		return nil
+	}
+
+	quoted := `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+	if err := conn.Exec("CREATE DATABASE " + quoted).Error; err != nil {
+		return fmt.Errorf("failed to create database %s: %w", name, err)
+	}
+	c.printf("Created destination database '%s'\n", name)
+	return nil
+}
+
+// ConnParams are the parts of a PostgreSQL connection given separately
+// instead of as a connection URL
+type ConnParams struct {
+	Host     string
+	Port     int
+	User     string
+	DBName   string
+	Password string
+}
+
+// IsSet reports whether any connection detail was given
+func (p ConnParams) IsSet() bool {
+	return p != ConnParams{}
+}
+
+// withDBName returns the parameters with name as the database if none is set
+func (p ConnParams) withDBName(name string) ConnParams {
+	if p.DBName == "" {
+		p.DBName = name
+	}
+	return p
+}
+
+// dsn assembles a PostgreSQL connection URL. The user name and password are
+// escaped, so they may contain any character.
+func (p ConnParams) dsn() string {
+	host := p.Host
+	if host == "" {
+		host = "localhost"
+	}
+	port := p.Port
+	if port == 0 {
+		port = 5432
+	}
+	u := url.URL{
+		Scheme: "postgres",
+		Host:   fmt.Sprintf("%s:%d", host, port),
+		Path:   "/" + p.DBName,
+	}
+	switch {
+	case p.Password != "":
+		u.User = url.UserPassword(p.User, p.Password)
+	case p.User != "":
+		u.User = url.User(p.User)
+	}
+	return u.String()
+}
+
+// applyConnParams replaces connection strings that are not URLs with ones
+// assembled from SourceParams and DestParams, using a plain connection string
+// as the database name if none was given. Connection details are only
+// understood by PostgreSQL, so the database is treated as PostgreSQL.
+func (c *Copier) applyConnParams() {
+	if c.SourceParams.IsSet() && !strings.Contains(c.SourceDB, "://") {
+		c.SourceDB = c.SourceParams.withDBName(c.SourceDB).dsn()
+		c.sourceDBType = DBTypePostgres
+	}
+	if c.DestParams.IsSet() && !strings.Contains(c.DestDB, "://") {
+		c.DestDB = c.DestParams.withDBName(c.DestDB).dsn()
+		if c.destDBType != DBTypePostgres {
+			// Match the default NewCopier picks for PostgreSQL destinations
+			c.destDBType = DBTypePostgres
+			c.Analyze = true
+		}
+	}
+}
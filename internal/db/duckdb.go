@@ -0,0 +1,23 @@
+//go:build duckdb
+
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	_ "github.com/marcboeker/go-duckdb"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// openDuckDB opens a DuckDB database file. DuckDB's SQL is close enough to
+// PostgreSQL's that GORM's PostgreSQL dialector can drive it.
+func openDuckDB(dsn string) (*gorm.DB, error) {
+	sqlDB, err := sql.Open("duckdb", strings.TrimPrefix(dsn, "duckdb://"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open DuckDB database: %w", err)
+	}
+	return gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), gormConfig())
+}
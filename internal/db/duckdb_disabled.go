@@ -0,0 +1,15 @@
+//go:build !duckdb
+
+package db
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// openDuckDB reports that DuckDB support was not compiled in. DuckDB needs cgo,
+// so it is only included when building with -tags duckdb.
+func openDuckDB(dsn string) (*gorm.DB, error) {
+	return nil, errors.New("DuckDB support is not included in this build; rebuild with -tags duckdb")
+}
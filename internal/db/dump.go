@@ -0,0 +1,79 @@
+package db
+
+import (
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// isSQLDump reports whether a source is a SQL script, plain or gzipped, to be
+// loaded into an in-memory SQLite database instead of opened as a database
+func isSQLDump(dsn string) bool {
+	lower := strings.ToLower(dsn)
+	return strings.HasSuffix(lower, ".sql") || strings.HasSuffix(lower, ".sql.gz")
+}
+
+// openSQLDump loads the SQL dump at path into a new in-memory SQLite database
+// and returns a connection to it. The in-memory database lives as long as the
+// returned keepalive connection is open, so close it only after the database.
+func openSQLDump(path string) (*gorm.DB, *sql.Conn, error) {
+	script, err := readSQLDump(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	conn, keepalive, err := openSQLite(newMemoryDSN("dump"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, statement := range splitScript(script) {
+		if _, err := keepalive.ExecContext(context.Background(), statement.SQL); err != nil {
+			keepalive.Close()
+			if sqlDB, err := conn.DB(); err == nil {
+				sqlDB.Close()
+			}
+			return nil, nil, fmt.Errorf("failed to load %s: line %d: %w\n  %d | %s", path, statement.Line, err, statement.Line, firstLine(statement.SQL))
+		}
+	}
+	return conn, keepalive, nil
+}
+
+// readSQLDump reads a SQL dump, decompressing it when its name ends in .gz
+func readSQLDump(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(strings.ToLower(path), ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return "", fmt.Errorf("failed to decompress %s: %w", path, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return string(data), nil
+}
+
+// firstLine returns the first line of a statement, shortened for error messages
+func firstLine(statement string) string {
+	line, _, _ := strings.Cut(statement, "\n")
+	if len(line) > 80 {
+		line = line[:77] + "..."
+	}
+	return line
+}
@@ -0,0 +1,90 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// getSourceEnums returns the PostgreSQL enum type and its labels, in sort
+// order, for each enum column of a table
+func (c *Copier) getSourceEnums(table string) (map[string]Column, error) {
+	enums := make(map[string]Column)
+	if c.sourceDBType != DBTypePostgres {
+		return enums, nil
+	}
+
+	var rows []struct {
+		ColumnName string
+		TypeName   string
+		Label      string
+	}
+	if err := c.sourceConn.Raw(`
+		SELECT a.attname AS column_name, t.typname AS type_name, e.enumlabel AS label
+		FROM pg_attribute a
+		JOIN pg_type t ON t.oid = a.atttypid
+		JOIN pg_enum e ON e.enumtypid = t.oid
+		WHERE a.attrelid = ?::regclass AND a.attnum > 0 AND NOT a.attisdropped
+		ORDER BY a.attnum, e.enumsortorder
+	`, table).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get enum types: %w", err)
+	}
+
+	for _, row := range rows {
+		enum := enums[row.ColumnName]
+		enum.EnumType = row.TypeName
+		enum.EnumValues = append(enum.EnumValues, row.Label)
+		enums[row.ColumnName] = enum
+	}
+	return enums, nil
+}
+
+// enumLabels renders enum labels as a list of SQL string literals
+func enumLabels(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// enumCheck returns the CHECK clause restricting a text column to the enum
+// labels, for destinations without enum types
+func (c *Copier) enumCheck(col Column) string {
+	if len(col.EnumValues) == 0 || c.destDBType == DBTypePostgres {
+		return ""
+	}
+	return fmt.Sprintf(" CHECK (%s IN (%s))", quoteIdent(c.destColumn(col.Name)), enumLabels(col.EnumValues))
+}
+
+// createEnumTypes creates the enum types used by the columns on a PostgreSQL
+// destination using conn. Types that already exist are left as they are.
+func (c *Copier) createEnumTypes(conn *gorm.DB, columns []Column) error {
+	if c.destDBType != DBTypePostgres {
+		return nil
+	}
+
+	created := make(map[string]bool)
+	for _, col := range columns {
+		if col.EnumType == "" || created[col.EnumType] || col.Type != col.EnumType {
+			continue
+		}
+		created[col.EnumType] = true
+
+		var exists bool
+		if err := conn.Raw("SELECT EXISTS (SELECT 1 FROM pg_type WHERE typname = ?)", col.EnumType).Scan(&exists).Error; err != nil {
+			return fmt.Errorf("failed to check for type %s: %w", col.EnumType, err)
+		}
+		if exists {
+			continue
+		}
+
+		createTypeSQL := fmt.Sprintf("CREATE TYPE %s AS ENUM (%s);", col.EnumType, enumLabels(col.EnumValues))
+		if err := conn.Exec(createTypeSQL).Error; err != nil {
+			return fmt.Errorf("failed to create enum type %s: %w", col.EnumType, err)
+		}
+		c.printf("Created enum type '%s'\n", col.EnumType)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package db
+
+import "fmt"
+
+// ErrConnect is returned when a connection to the source or destination database fails
+type ErrConnect struct {
+	Database string // "source" or "destination"
+	Err      error
+}
+
+func (e *ErrConnect) Error() string {
+	return fmt.Sprintf("failed to connect to %s database: %v", e.Database, e.Err)
+}
+
+func (e *ErrConnect) Unwrap() error {
+	return e.Err
+}
+
+// ErrSchema is returned when the schema of a table cannot be read or created
+type ErrSchema struct {
+	Table string
+	Err   error
+}
+
+func (e *ErrSchema) Error() string {
+	return fmt.Sprintf("schema error for table %s: %v", e.Table, e.Err)
+}
+
+func (e *ErrSchema) Unwrap() error {
+	return e.Err
+}
+
+// ErrInsert is returned when a batch cannot be inserted into the destination table
+type ErrInsert struct {
+	Table string
+	Batch int
+	Err   error
+}
+
+func (e *ErrInsert) Error() string {
+	return fmt.Sprintf("failed to insert batch %d into destination table %s: %v", e.Batch, e.Table, e.Err)
+}
+
+func (e *ErrInsert) Unwrap() error {
+	return e.Err
+}
+
+// ErrEmpty is returned with FailOnEmpty when the source table or query has no rows
+type ErrEmpty struct {
+	Source string
+}
+
+func (e *ErrEmpty) Error() string {
+	return fmt.Sprintf("no rows to copy from %s", e.Source)
+}
+
+// ErrTooManyErrors is returned with SkipErrors once MaxErrors rows have failed
+// to insert
+type ErrTooManyErrors struct {
+	Table  string
+	Errors int
+	Err    error
+}
+
+func (e *ErrTooManyErrors) Error() string {
+	return fmt.Sprintf("aborting copy into %s after %d rows failed to insert (--max-errors): last error: %v", e.Table, e.Errors, e.Err)
+}
+
+func (e *ErrTooManyErrors) Unwrap() error {
+	return e.Err
+}
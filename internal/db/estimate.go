@@ -0,0 +1,83 @@
+package db
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RowCount is the number of rows of a source table. Estimated counts come
+// from the planner statistics and may be off.
+type RowCount struct {
+	Table     string `json:"table"`
+	Rows      int64  `json:"rows"`
+	Estimated bool   `json:"estimated"`
+}
+
+// CountSourceRows counts the rows of each source table. With approx the
+// planner's estimate is used where statistics exist, avoiding a full scan,
+// and the exact count otherwise.
+func (c *Copier) CountSourceRows(tables []string, approx bool) ([]RowCount, error) {
+	if c.sourceConn == nil {
+		return nil, fmt.Errorf("rows cannot be counted for this source")
+	}
+	var counts []RowCount
+	for _, table := range tables {
+		rc := RowCount{Table: table}
+		if approx {
+			rows, ok, err := c.estimateRows(table)
+			if err != nil {
+				return nil, err
+			}
+			rc.Rows, rc.Estimated = rows, ok
+		}
+		if !rc.Estimated {
+			if err := c.sourceConn.Table(table).Count(&rc.Rows).Error; err != nil {
+				return nil, fmt.Errorf("failed to count rows of source table %s: %w", table, err)
+			}
+		}
+		counts = append(counts, rc)
+	}
+	return counts, nil
+}
+
+// estimateRows reads the planner's row estimate for a source table. ok is
+// false when the table has no statistics, e.g. before its first ANALYZE.
+func (c *Copier) estimateRows(table string) (rows int64, ok bool, err error) {
+	switch c.sourceDBType {
+	case DBTypePostgres:
+		// reltuples is -1 (or 0 with no pages before PostgreSQL 14) until the
+		// table has been analyzed or vacuumed
+		var stats struct {
+			Reltuples float64
+			Relpages  int64
+		}
+		if err := c.sourceConn.Raw("SELECT reltuples, relpages FROM pg_class WHERE oid = ?::regclass", table).Scan(&stats).Error; err != nil {
+			return 0, false, fmt.Errorf("failed to read statistics of table %s: %w", table, err)
+		}
+		if stats.Reltuples < 0 || (stats.Reltuples == 0 && stats.Relpages == 0) {
+			return 0, false, nil
+		}
+		return int64(stats.Reltuples), true, nil
+	case DBTypeSQLite:
+		// sqlite_stat1 only exists once ANALYZE has been run; the first number
+		// of each stat is the row count of the table
+		var exists int64
+		if err := c.sourceConn.Raw("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'sqlite_stat1'").Scan(&exists).Error; err != nil {
+			return 0, false, fmt.Errorf("failed to look for sqlite_stat1: %w", err)
+		}
+		if exists == 0 {
+			return 0, false, nil
+		}
+		var stats []string
+		if err := c.sourceConn.Raw("SELECT stat FROM sqlite_stat1 WHERE tbl = ?", table).Scan(&stats).Error; err != nil {
+			return 0, false, fmt.Errorf("failed to read statistics of table %s: %w", table, err)
+		}
+		for _, stat := range stats {
+			if n, err := strconv.ParseInt(strings.Fields(stat + " ")[0], 10, 64); err == nil {
+				return n, true, nil
+			}
+		}
+	}
+	return 0, false, nil
+}
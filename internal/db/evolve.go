@@ -0,0 +1,81 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Policies for a destination table that already exists
+const (
+	// IfExistsAppend copies into the table as it is
+	IfExistsAppend = "append"
+	// IfExistsAppendMissingColumns first adds the source columns it lacks
+	IfExistsAppendMissingColumns = "append-missing-columns"
+)
+
+// addMissingColumns adds the source columns that an existing destination
+// table lacks, with IfExistsAppendMissingColumns, so that a table created
+// before the source gained columns can be copied into. Columns get the type
+// and collation a new table would give them. Existing rows get the --default
+// value of a new column, or NULL: a column that is NOT NULL in the source
+// stays NOT NULL only when it has a --default, since the rows already in the
+// table have no value for it. Existing columns and rows are left alone.
+func (c *Copier) addMissingColumns() error {
+	if c.IfExists != IfExistsAppendMissingColumns {
+		return nil
+	}
+	columns, err := c.sourceColumns()
+	if err != nil {
+		return fmt.Errorf("failed to get source table schema: %w", err)
+	}
+	destTypes, err := c.destConn.Migrator().ColumnTypes(c.destTableName())
+	if err != nil {
+		return fmt.Errorf("failed to get destination column types: %w", err)
+	}
+	existing := make(map[string]bool, len(destTypes))
+	for _, dc := range destTypes {
+		existing[dc.Name()] = true
+	}
+	var missing []Column
+	for _, col := range columns {
+		if !existing[c.destColumn(col.Name)] {
+			missing = append(missing, col)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	var sourceCollations map[string]string
+	if c.PreserveCollation && c.Query == "" {
+		if sourceCollations, err = c.getSourceCollations(c.TableName); err != nil {
+			return err
+		}
+	}
+
+	return c.recordDDL(c.destConn).Transaction(func(tx *gorm.DB) error {
+		if err := c.createEnumTypes(tx, missing); err != nil {
+			return err
+		}
+		for _, col := range missing {
+			name := c.destColumn(col.Name)
+			def := fmt.Sprintf("%s %s%s", quoteIdent(name), col.Type, c.columnCollation(col, sourceCollations))
+			if value, ok := c.Defaults[name]; ok {
+				def += " DEFAULT '" + strings.ReplaceAll(value, "'", "''") + "'"
+				if !col.IsNullable {
+					def += " NOT NULL"
+				}
+			} else if !col.IsNullable {
+				c.noticeOnce("add-column:"+c.destTableName()+"."+name, fmt.Sprintf("Adding column %s to %s as nullable: it is NOT NULL in the source, but the rows already in the table have no value for it; set --default to keep it NOT NULL", name, c.destTableName()))
+			}
+			def += c.enumCheck(col)
+			if err := tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s;", c.destTableName(), def)).Error; err != nil {
+				return fmt.Errorf("failed to add column %s: %w", name, err)
+			}
+			c.printf("Added column '%s' to destination table '%s'\n", name, c.destTableName())
+		}
+		return nil
+	})
+}
@@ -0,0 +1,51 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// isSpatialType reports whether a PostgreSQL type is a PostGIS geometry or geography type
+func isSpatialType(pgType string) bool {
+	pgType = strings.ToUpper(pgType)
+	return strings.HasPrefix(pgType, "GEOMETRY") || strings.HasPrefix(pgType, "GEOGRAPHY")
+}
+
+// spatialAsWKT reports whether spatial columns are copied as WKT text rather
+// than as PostGIS values. Only PostgreSQL destinations can hold the latter.
+func (c *Copier) spatialAsWKT() bool {
+	return c.GeometryAsWKT || c.destDBType != DBTypePostgres
+}
+
+// spatialType returns the destination type of a PostGIS column. fullType is the
+// source type including its modifiers, e.g. geometry(Point,4326).
+func (c *Copier) spatialType(fullType string) string {
+	if c.spatialAsWKT() {
+		return "TEXT"
+	}
+	c.noticeOnce("postgis", "Copying PostGIS columns requires the postgis extension on the destination database")
+	return fullType
+}
+
+// spatialSelects returns the select list for reading the source table with
+// PostGIS columns converted to WKT, or nil when no conversion is needed.
+// Without conversion the values are passed through in their source encoding.
+func (c *Copier) spatialSelects(columns []Column) []string {
+	if !c.spatialAsWKT() {
+		return nil
+	}
+	var selects []string
+	converted := false
+	for _, col := range columns {
+		if col.IsSpatial {
+			selects = append(selects, fmt.Sprintf("ST_AsText(%s) AS %s", quoteIdent(col.Name), quoteIdent(col.Name)))
+			converted = true
+			continue
+		}
+		selects = append(selects, quoteIdent(col.Name))
+	}
+	if !converted {
+		return nil
+	}
+	return selects
+}
@@ -0,0 +1,28 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSpatialSelects(t *testing.T) {
+	columns := []Column{
+		{Name: "id", IsPrimary: true},
+		{Name: "order"},
+		{Name: "Shape", IsSpatial: true},
+		{Name: "geom", IsSpatial: true},
+	}
+	c := &Copier{destDBType: DBTypeSQLite}
+	want := []string{`"id"`, `"order"`, `ST_AsText("Shape") AS "Shape"`, `ST_AsText("geom") AS "geom"`}
+	if got := c.spatialSelects(columns); !reflect.DeepEqual(got, want) {
+		t.Errorf("spatialSelects() = %q, want %q", got, want)
+	}
+
+	if got := c.spatialSelects(columns[:2]); got != nil {
+		t.Errorf("spatialSelects() without spatial columns = %q, want nil", got)
+	}
+	c = &Copier{destDBType: DBTypePostgres}
+	if got := c.spatialSelects(columns); got != nil {
+		t.Errorf("spatialSelects() for a PostGIS destination = %q, want nil", got)
+	}
+}
@@ -0,0 +1,40 @@
+package db
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// startHeartbeat logs a message every Heartbeat interval until the returned
+// function is called, so that slow phases without other output do not look
+// hung. Nested calls share the outer heartbeat.
+func (c *Copier) startHeartbeat() func() {
+	if c.Heartbeat <= 0 || c.heartbeatRunning {
+		return func() {}
+	}
+	c.heartbeatRunning = true
+
+	start := time.Now()
+	ticker := time.NewTicker(c.Heartbeat)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				zap.L().Info("Still working",
+					zap.Int64("rows_copied", c.rowsCopied.Load()),
+					zap.Duration("elapsed", time.Since(start).Round(time.Second)),
+				)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(done)
+		c.heartbeatRunning = false
+	}
+}
@@ -0,0 +1,145 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// RunWithHooks runs PreSQL on the destination, then copy, then PostSQL once
+// copy has succeeded. With PostSQLAlways the post-copy statements also run
+// after a failed copy, and the copy error is returned.
+func (c *Copier) RunWithHooks(copy func() error) error {
+	if err := c.runHook("pre-sql", c.PreSQL); err != nil {
+		return err
+	}
+
+	copyErr := copy()
+	if copyErr != nil {
+		if !c.PostSQLAlways || c.PostSQL == "" {
+			return copyErr
+		}
+		zap.L().Warn("Copy failed, running post-sql anyway", zap.Error(copyErr))
+		if err := c.runHook("post-sql", c.PostSQL); err != nil {
+			zap.L().Error("post-sql failed after failed copy", zap.Error(err))
+		}
+		return copyErr
+	}
+
+	return c.runHook("post-sql", c.PostSQL)
+}
+
+// runHook executes each statement of a hook script on the destination in its
+// own statement, outside of the copy transaction
+func (c *Copier) runHook(name, script string) error {
+	if c.destConn == nil && script != "" {
+		return fmt.Errorf("%s is not supported for this destination", name)
+	}
+	for i, statement := range splitStatements(script) {
+		result := c.destConn.Exec(statement)
+		if result.Error != nil {
+			return fmt.Errorf("%s statement %d failed: %w", name, i+1, result.Error)
+		}
+		c.printf("%s statement %d: %d rows affected\n", name, i+1, result.RowsAffected)
+	}
+	return nil
+}
+
+// sqlStatement is one statement of a SQL script and the line it starts on
+type sqlStatement struct {
+	SQL  string
+	Line int
+}
+
+// splitStatements splits a SQL script on semicolons that are not inside quotes,
+// dollar-quoted bodies, -- line comments or /* */ block comments. Comments are
+// dropped, and so are empty statements.
+func splitStatements(script string) []string {
+	var statements []string
+	for _, statement := range splitScript(script) {
+		statements = append(statements, statement.SQL)
+	}
+	return statements
+}
+
+// splitScript is splitStatements keeping the line each statement starts on
+func splitScript(script string) []sqlStatement {
+	var statements []sqlStatement
+	var current strings.Builder
+	line, start := 1, 0
+	write := func(s string) {
+		if start == 0 {
+			if trimmed := strings.TrimLeft(s, " \t\r\n"); trimmed != "" {
+				start = line + strings.Count(s[:len(s)-len(trimmed)], "\n")
+			}
+		}
+		current.WriteString(s)
+		line += strings.Count(s, "\n")
+	}
+	flush := func() {
+		if statement := strings.TrimSpace(current.String()); statement != "" {
+			statements = append(statements, sqlStatement{SQL: statement, Line: start})
+		}
+		current.Reset()
+		start = 0
+	}
+
+	for i := 0; i < len(script); i++ {
+		ch := script[i]
+		switch {
+		case ch == '\'' || ch == '"':
+			end := strings.IndexByte(script[i+1:], ch)
+			if end < 0 {
+				write(script[i:])
+				i = len(script)
+				continue
+			}
+			write(script[i : i+end+2])
+			i += end + 1
+		case ch == '-' && strings.HasPrefix(script[i:], "--"):
+			end := strings.IndexByte(script[i:], '\n')
+			if end < 0 {
+				i = len(script)
+				continue
+			}
+			i += end - 1
+		case ch == '/' && strings.HasPrefix(script[i:], "/*"):
+			// A block comment separates tokens like a space and keeps its lines
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				i = len(script)
+				continue
+			}
+			write(" ")
+			line += strings.Count(script[i:i+2+end], "\n")
+			i += end + 3
+		case ch == '$':
+			// Dollar quoting: $$ ... $$ or $tag$ ... $tag$
+			tagEnd := strings.IndexByte(script[i+1:], '$')
+			tag := ""
+			if tagEnd >= 0 {
+				tag = script[i : i+tagEnd+2]
+			}
+			if tag == "" || strings.ContainsAny(tag[1:len(tag)-1], " \t\n;'\"") {
+				write(string(ch))
+				continue
+			}
+			end := strings.Index(script[i+len(tag):], tag)
+			if end < 0 {
+				write(script[i:])
+				i = len(script)
+				continue
+			}
+			write(script[i : i+len(tag)+end+len(tag)])
+			i += len(tag) + end + len(tag) - 1
+		case ch == ';':
+			flush()
+		default:
+			write(string(ch))
+		}
+	}
+	flush()
+
+	return statements
+}
@@ -0,0 +1,29 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitScript(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []sqlStatement
+	}{
+		{"semicolons", "SELECT 1; SELECT 2;", []sqlStatement{{"SELECT 1", 1}, {"SELECT 2", 1}}},
+		{"quoted semicolon", "INSERT INTO t VALUES ('a;b');\nSELECT 2", []sqlStatement{{"INSERT INTO t VALUES ('a;b')", 1}, {"SELECT 2", 2}}},
+		{"line comment", "-- drop; everything\nSELECT 1;", []sqlStatement{{"SELECT 1", 2}}},
+		{"block comment", "/* step 1; cleanup */ DELETE FROM t;", []sqlStatement{{"DELETE FROM t", 1}}},
+		{"multi-line block comment", "/*\n first; second\n*/\nSELECT 1;\nSELECT 2;", []sqlStatement{{"SELECT 1", 4}, {"SELECT 2", 5}}},
+		{"block comment between tokens", "SELECT 1/*;*/FROM t;", []sqlStatement{{"SELECT 1 FROM t", 1}}},
+		{"unterminated block comment", "SELECT 1; /* never; closed", []sqlStatement{{"SELECT 1", 1}}},
+		{"comment markers in quotes", "SELECT '/* not; a comment */';", []sqlStatement{{"SELECT '/* not; a comment */'", 1}}},
+		{"dollar quoting", "CREATE FUNCTION f() RETURNS int AS $$ SELECT 1; $$ LANGUAGE sql;", []sqlStatement{{"CREATE FUNCTION f() RETURNS int AS $$ SELECT 1; $$ LANGUAGE sql", 1}}},
+	}
+	for _, tt := range tests {
+		if got := splitScript(tt.script); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: splitScript(%q) = %+v, want %+v", tt.name, tt.script, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package db
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// Insert methods of a SQL destination
+const (
+	// InsertMultiValues inserts a batch with one INSERT of many VALUES rows
+	InsertMultiValues = "multi-values"
+	// InsertPrepared prepares one INSERT per table and executes it per row
+	InsertPrepared = "prepared"
+	// InsertCopy streams a batch with PostgreSQL's COPY FROM STDIN
+	InsertCopy = "copy"
+	// InsertAuto picks the method that is usually fastest for the destination
+	InsertAuto = "auto"
+)
+
+// insertMethod returns the method rows are inserted with. Auto picks COPY for
+// PostgreSQL, which loads large batches several times faster than INSERT, and
+// multi-row INSERTs elsewhere. COPY falls back to INSERTs on destinations
+// without it.
+func (c *Copier) insertMethod() string {
+	copyable := c.destDBType == DBTypePostgres && !c.destCockroach
+	switch c.InsertMethod {
+	case InsertPrepared:
+		return InsertPrepared
+	case InsertCopy:
+		if copyable {
+			return InsertCopy
+		}
+		c.noticeOnce("insert-method", "--insert-method copy is only supported for PostgreSQL destinations; using multi-values")
+	case InsertAuto:
+		if copyable {
+			return InsertCopy
+		}
+	}
+	return InsertMultiValues
+}
+
+// pinForCopy reports whether ConnectDest has to hold one connection for COPY.
+// COPY runs on the driver connection itself, which for a transaction is only
+// reachable when the copier owns the connection the transaction runs on.
+// Concurrent workers never share a transaction and take a pooled connection
+// per batch instead.
+func (c *Copier) pinForCopy() bool {
+	return c.insertMethod() == InsertCopy && c.Workers <= 1
+}
+
+// sortedColumns returns the column names of a row in sorted order, the order
+// GORM inserts them in
+func sortedColumns(row map[string]interface{}) []string {
+	columns := make([]string, 0, len(row))
+	for name := range row {
+		columns = append(columns, name)
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+// rowValues returns the values of a row in the order of columns
+func rowValues(row map[string]interface{}, columns []string) []interface{} {
+	values := make([]interface{}, len(columns))
+	for i, name := range columns {
+		values[i] = row[name]
+	}
+	return values
+}
+
+// quotedColumnList returns columns as a quoted, comma-separated list
+func quotedColumnList(columns []string) string {
+	quoted := make([]string, len(columns))
+	for i, name := range columns {
+		quoted[i] = quoteIdent(name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// writePrepared executes a prepared INSERT for every row of a group of rows
+// with the same columns. The statement is prepared once per column list and
+// reused for the rest of the table.
+func (w *tableWriter) writePrepared(rows []map[string]interface{}) error {
+	columns := sortedColumns(rows[0])
+	stmt, err := w.preparedInsert(columns)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if _, err := stmt.ExecContext(w.ctx, rowValues(row, columns)...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// preparedInsert returns the prepared INSERT for a column list, preparing it
+// on first use
+func (w *tableWriter) preparedInsert(columns []string) (*sql.Stmt, error) {
+	key := strings.Join(columns, "\x00")
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if stmt, ok := w.stmts[key]; ok {
+		return stmt, nil
+	}
+
+	placeholders := make([]string, len(columns))
+	for i := range columns {
+		placeholders[i] = "?"
+		if w.dbType == DBTypePostgres {
+			placeholders[i] = fmt.Sprintf("$%d", i+1)
+		}
+	}
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", w.table, quotedColumnList(columns), strings.Join(placeholders, ", "))
+	stmt, err := w.conn.Statement.ConnPool.PrepareContext(w.ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare insert: %w", err)
+	}
+	if w.stmts == nil {
+		w.stmts = make(map[string]*sql.Stmt)
+	}
+	w.stmts[key] = stmt
+	return stmt, nil
+}
+
+// writeCopy loads a group of rows with the same columns with one COPY FROM
+// STDIN in the text format. It runs on the pinned destination connection, and
+// so inside its transaction, or otherwise on a connection of the pool.
+func (w *tableWriter) writeCopy(rows []map[string]interface{}) error {
+	columns := sortedColumns(rows[0])
+	encoder, err := w.copyEncoder(columns)
+	if err != nil {
+		return err
+	}
+	var buf []byte
+	for i, row := range rows {
+		if buf, err = encoder.appendRow(buf, rowValues(row, columns)); err != nil {
+			return fmt.Errorf("failed to encode row %d for COPY: %w", i+1, err)
+		}
+	}
+
+	conn := w.pinned
+	if conn == nil {
+		pool, err := w.conn.DB()
+		if err != nil {
+			return err
+		}
+		if conn, err = pool.Conn(w.ctx); err != nil {
+			return err
+		}
+		defer conn.Close()
+	}
+	query := fmt.Sprintf("COPY %s (%s) FROM STDIN", w.table, quotedColumnList(columns))
+	if w.copyNull != "" {
+		query += " WITH (NULL '" + strings.ReplaceAll(w.copyNull, "'", "''") + "')"
+	}
+	return conn.Raw(func(driverConn interface{}) error {
+		pgConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("COPY needs a pgx connection, got %T", driverConn)
+		}
+		_, err := pgConn.Conn().PgConn().CopyFrom(w.ctx, bytes.NewReader(buf), query)
+		return err
+	})
+}
+
+// copyEncoder returns the COPY encoder for a column list, which needs the
+// destination column types to tell bytea from text
+func (w *tableWriter) copyEncoder(columns []string) (*copyEncoder, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.columnTypes == nil {
+		columnTypes, err := w.conn.Migrator().ColumnTypes(w.table)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get destination column types: %w", err)
+		}
+		w.columnTypes = make(map[string]string, len(columnTypes))
+		for _, col := range columnTypes {
+			w.columnTypes[col.Name()] = col.DatabaseTypeName()
+		}
+	}
+	types := make([]string, len(columns))
+	for i, name := range columns {
+		types[i] = w.columnTypes[name]
+	}
+	return newCopyEncoder(types, w.copyNull), nil
+}
+
+// closeStatements closes the prepared statements of the writer once the table
+// is written. Those of a transaction are closed with it anyway.
+func (w *tableWriter) closeStatements() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for key, stmt := range w.stmts {
+		stmt.Close()
+		delete(w.stmts, key)
+	}
+}
+
+// pinnedDestConn returns the destination connection pinned by ConnectDest, if
+// any
+func (c *Copier) pinnedDestConn() *sql.Conn {
+	if pinned, ok := c.destConn.ConnPool.(*pinnedConn); ok {
+		return pinned.Conn
+	}
+	return nil
+}
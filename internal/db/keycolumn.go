@@ -0,0 +1,115 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// primaryKeyFallback returns the implicit key of a table in which
+// getPrimaryKeyColumnName found no primary key
+func (c *Copier) primaryKeyFallback() (string, error) {
+	key, err := c.implicitKeyColumn()
+	if err != nil || key != "" {
+		return key, err
+	}
+	return "", fmt.Errorf("primary key not found for table: %s", c.TableName)
+}
+
+// explicitKey returns the key column the user chose: KeyColumn, or else the
+// column of a single-column DestPrimaryKey
+func (c *Copier) explicitKey() string {
+	if c.KeyColumn == "" && len(c.DestPrimaryKey) == 1 {
+		return c.DestPrimaryKey[0]
+	}
+	return c.KeyColumn
+}
+
+// implicitKeyColumn returns the column that identifies the rows of a source
+// table without a primary key: explicitKey when set, otherwise its only
+// single-column unique constraint or unique index over a NOT NULL column.
+// Rows are matched and paged on it like on a primary key. It returns "" when
+// the table has no such column, and an error when it has several, since
+// picking one would be a guess.
+func (c *Copier) implicitKeyColumn() (string, error) {
+	columns, err := c.getSourceSchema(c.TableName)
+	if err != nil {
+		return "", err
+	}
+	notNull := make(map[string]bool, len(columns))
+	for _, col := range columns {
+		notNull[col.Name] = !col.IsNullable
+	}
+
+	if key := c.explicitKey(); key != "" {
+		if _, ok := notNull[key]; !ok {
+			return "", fmt.Errorf("key column %s not found in table %s", key, c.TableName)
+		}
+		return key, nil
+	}
+
+	constraints, err := c.getSourceUniqueConstraints(c.TableName)
+	if err != nil {
+		return "", err
+	}
+	indexes, err := c.getSourceIndexes(c.TableName)
+	if err != nil {
+		return "", err
+	}
+	var candidates []string
+	seen := make(map[string]bool)
+	add := func(cols []string) {
+		if len(cols) == 1 && notNull[cols[0]] && !seen[cols[0]] {
+			seen[cols[0]] = true
+			candidates = append(candidates, cols[0])
+		}
+	}
+	for _, uc := range constraints {
+		add(uc.Columns)
+	}
+	for _, idx := range indexes {
+		// A partial index leaves the rows outside its predicate unconstrained
+		if idx.Unique && idx.Predicate == "" {
+			add(idx.Columns)
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return "", nil
+	case 1:
+		c.noticeOnce("key-column:"+c.TableName, fmt.Sprintf("Table %s has no primary key; using its unique NOT NULL column %s as the key", c.TableName, candidates[0]))
+		return candidates[0], nil
+	}
+	return "", fmt.Errorf("table %s has no primary key and several unique NOT NULL columns (%s); choose one with --key-column",
+		c.TableName, strings.Join(candidates, ", "))
+}
+
+// applyDestPrimaryKey makes the DestPrimaryKey columns the primary key of the
+// destination table in place of any the source reports. A key column the
+// source allows NULL in is created NOT NULL, so rows with NULL in it fail.
+func (c *Copier) applyDestPrimaryKey(columns []Column) ([]Column, error) {
+	if len(c.DestPrimaryKey) == 0 {
+		return columns, nil
+	}
+	key := make(map[string]bool, len(c.DestPrimaryKey))
+	for _, name := range c.DestPrimaryKey {
+		key[name] = true
+	}
+	for i, col := range columns {
+		columns[i].IsPrimary = key[col.Name]
+		if !key[col.Name] {
+			continue
+		}
+		delete(key, col.Name)
+		if col.IsNullable {
+			c.noticeOnce("dest-primary-key:"+col.Name, fmt.Sprintf("Column %s allows NULL in the source but is created NOT NULL as part of --dest-primary-key; rows with NULL in it will fail", col.Name))
+			columns[i].IsNullable = false
+		}
+	}
+	for _, name := range c.DestPrimaryKey {
+		if key[name] {
+			return nil, fmt.Errorf("--dest-primary-key column %s is not among the columns copied", name)
+		}
+	}
+	return columns, nil
+}
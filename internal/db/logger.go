@@ -0,0 +1,32 @@
+package db
+
+import (
+	"log"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// colorful tells whether the SQL log may use terminal colors
+var colorful = true
+
+// SetColor enables or disables colors in the SQL log. It is on by default;
+// callers writing to a file or pipe should turn it off.
+func SetColor(enabled bool) {
+	colorful = enabled
+}
+
+// gormConfig returns the GORM configuration used for every connection. It
+// matches GORM's defaults except that SQL errors and slow queries are logged
+// to stderr, keeping stdout free for command results.
+func gormConfig() *gorm.Config {
+	return &gorm.Config{
+		Logger: logger.New(log.New(os.Stderr, "\r\n", log.LstdFlags), logger.Config{
+			SlowThreshold: 200 * time.Millisecond,
+			LogLevel:      logger.Warn,
+			Colorful:      colorful,
+		}),
+	}
+}
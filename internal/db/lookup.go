@@ -0,0 +1,66 @@
+package db
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ReadLookupFile reads a lookup table from a CSV file of old,new value pairs
+// without a header row
+func ReadLookupFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = 2
+	lookup := make(map[string]string)
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return lookup, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid lookup file %s: %w", path, err)
+		}
+		if _, ok := lookup[row[0]]; ok {
+			return nil, fmt.Errorf("invalid lookup file %s: value %q is mapped twice", path, row[0])
+		}
+		lookup[row[0]] = row[1]
+	}
+}
+
+// applyLookups replaces the values of the Lookups columns of each record with
+// the value their lookup table maps them to. Records are keyed by source
+// column names. NULLs are left alone; values missing from the lookup table
+// pass through unchanged, or fail the copy with LookupStrict.
+func (c *Copier) applyLookups(records []map[string]interface{}) error {
+	if len(c.Lookups) == 0 {
+		return nil
+	}
+	for i, record := range records {
+		for name, lookup := range c.Lookups {
+			value, ok := record[name]
+			if !ok || value == nil {
+				continue
+			}
+			key := fmt.Sprint(value)
+			if b, ok := value.([]byte); ok {
+				key = string(b)
+			}
+			mapped, ok := lookup[key]
+			if !ok {
+				if c.LookupStrict {
+					return fmt.Errorf("row %d of %s, column %s: value %q is not in its --lookup table", i+1, c.TableName, name, key)
+				}
+				continue
+			}
+			record[name] = mapped
+		}
+	}
+	return nil
+}
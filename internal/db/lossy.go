@@ -0,0 +1,228 @@
+package db
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// typeInfo is a column type split into its name and size modifiers
+type typeInfo struct {
+	Name      string
+	Length    int64
+	Precision int64
+	Scale     int64
+}
+
+// typePattern splits a type such as "character varying(255)" or "NUMERIC(30,10)".
+// The closing parenthesis is optional because GORM's SQLite migrator cuts
+// column types at the first comma.
+var typePattern = regexp.MustCompile(`^\s*([A-Za-z_][A-Za-z0-9_ ]*?)\s*(?:\(\s*(\d+)\s*(?:,\s*(\d+)\s*)?\)?)?\s*$`)
+
+// typeAliases maps spellings of the same type to one name
+var typeAliases = map[string]string{
+	"CHARACTER VARYING": "VARCHAR",
+	"CHARACTER":         "CHAR",
+	"BPCHAR":            "CHAR",
+	"INT2":              "SMALLINT",
+	"INT":               "INTEGER",
+	"INT4":              "INTEGER",
+	"INT8":              "BIGINT",
+	"FLOAT4":            "REAL",
+	"FLOAT8":            "DOUBLE PRECISION",
+	"FLOAT":             "DOUBLE PRECISION",
+	"DOUBLE":            "DOUBLE PRECISION",
+	"DECIMAL":           "NUMERIC",
+}
+
+// parseType parses a SQL type name with optional length or precision and scale
+func parseType(sqlType string) typeInfo {
+	m := typePattern.FindStringSubmatch(sqlType)
+	if m == nil {
+		return typeInfo{Name: strings.ToUpper(strings.TrimSpace(sqlType))}
+	}
+	info := typeInfo{Name: strings.ToUpper(m[1])}
+	if alias, ok := typeAliases[info.Name]; ok {
+		info.Name = alias
+	}
+	first, _ := strconv.ParseInt(m[2], 10, 64)
+	second, _ := strconv.ParseInt(m[3], 10, 64)
+	if info.Name == "NUMERIC" {
+		info.Precision, info.Scale = first, second
+	} else {
+		info.Length = first
+	}
+	return info
+}
+
+// columnTypeInfo returns the full type of a column reported by GORM
+func columnTypeInfo(col gorm.ColumnType) typeInfo {
+	fullType, ok := col.ColumnType()
+	if !ok || fullType == "" {
+		fullType = col.DatabaseTypeName()
+	}
+	info := parseType(fullType)
+	if info.Length == 0 {
+		if length, ok := col.Length(); ok && length > 0 {
+			info.Length = length
+		}
+	}
+	if info.Name == "NUMERIC" && info.Precision == 0 {
+		if precision, scale, ok := col.DecimalSize(); ok && precision > 0 {
+			info.Precision, info.Scale = precision, scale
+		}
+	}
+	return info
+}
+
+// integerBits returns the width of an integer type in a database, or 0 for other types.
+// SQLite stores every INTEGER in up to 64 bits.
+func integerBits(name string, dbType DBType) int {
+	switch name {
+	case "SMALLINT":
+		if dbType == DBTypeSQLite {
+			return 64
+		}
+		return 16
+	case "INTEGER":
+		if dbType == DBTypeSQLite {
+			return 64
+		}
+		return 32
+	case "BIGINT":
+		return 64
+	}
+	return 0
+}
+
+// isTextType reports whether a type holds character data
+func isTextType(name string) bool {
+	switch name {
+	case "VARCHAR", "CHAR", "TEXT", "NVARCHAR", "NCHAR", "CLOB", "STRING":
+		return true
+	}
+	return false
+}
+
+// conversionRisk describes how data may be lost converting from src to dst,
+// or returns "" when the conversion is safe
+func (c *Copier) conversionRisk(src, dst typeInfo) string {
+	srcBits := integerBits(src.Name, c.sourceDBType)
+	dstBits := integerBits(dst.Name, c.destDBType)
+
+	switch {
+	case srcBits > 0 && dstBits > 0 && srcBits > dstBits:
+		return fmt.Sprintf("%d-bit integers may overflow a %d-bit column", srcBits, dstBits)
+
+	case src.Name == "NUMERIC" && (dst.Name == "REAL" || dst.Name == "DOUBLE PRECISION"):
+		if src.Precision == 0 || src.Precision > 15 {
+			return "floating point keeps only about 15 significant digits"
+		}
+
+	case src.Name == "NUMERIC" && dstBits > 0:
+		if src.Scale > 0 || src.Precision == 0 {
+			return "fractional digits are dropped"
+		}
+
+	case src.Name == "NUMERIC" && dst.Name == "NUMERIC" && dst.Precision > 0:
+		if src.Precision == 0 || src.Precision-src.Scale > dst.Precision-dst.Scale {
+			return "values may exceed the destination precision"
+		}
+		if src.Scale > dst.Scale {
+			return "fractional digits are rounded"
+		}
+
+	case src.Name == "DOUBLE PRECISION" && dst.Name == "REAL" && c.destDBType != DBTypeSQLite:
+		return "double precision values are rounded to single precision"
+
+	case isTextType(src.Name) && (dst.Name == "VARCHAR" || dst.Name == "CHAR") && dst.Length > 0:
+		if src.Length == 0 || src.Length > dst.Length {
+			return fmt.Sprintf("values longer than %d characters do not fit", dst.Length)
+		}
+	}
+	return ""
+}
+
+// lossyConversions compares the source column types of the current table with
+// the existing destination table, or the types it would be created with
+func (c *Copier) lossyConversions() ([]string, error) {
+	if c.sourceConn == nil {
+		return nil, nil
+	}
+	sourceTypes, err := c.sourceConn.Migrator().ColumnTypes(c.TableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get column types: %w", err)
+	}
+
+	destTypes := make(map[string]typeInfo)
+	if c.destConn.Migrator().HasTable(c.destTableName()) {
+		columnTypes, err := c.destConn.Migrator().ColumnTypes(c.destTableName())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get destination column types: %w", err)
+		}
+		for _, col := range columnTypes {
+			destTypes[col.Name()] = columnTypeInfo(col)
+		}
+	} else {
+		columns, err := c.sourceColumns()
+		if err != nil {
+			return nil, err
+		}
+		for _, col := range columns {
+			destTypes[c.destColumn(col.Name)] = parseType(col.Type)
+		}
+	}
+
+	var risks []string
+	for _, col := range sourceTypes {
+		dst, ok := destTypes[c.destColumn(col.Name())]
+		if !ok {
+			continue
+		}
+		src := columnTypeInfo(col)
+		if risk := c.conversionRisk(src, dst); risk != "" {
+			zap.L().Warn("Lossy column conversion",
+				zap.String("table", c.destTableName()),
+				zap.String("column", col.Name()),
+				zap.String("source_type", typeString(src)),
+				zap.String("dest_type", typeString(dst)),
+				zap.String("risk", risk),
+			)
+			risks = append(risks, fmt.Sprintf("%s (%s -> %s): %s", col.Name(), typeString(src), typeString(dst), risk))
+		}
+	}
+	return risks, nil
+}
+
+// checkConversions warns about lossy column conversions before any data is
+// copied, and fails with Strict
+func (c *Copier) checkConversions() error {
+	if c.Query != "" {
+		return nil
+	}
+	risks, err := c.lossyConversions()
+	if err != nil {
+		return err
+	}
+	if c.Strict && len(risks) > 0 {
+		return fmt.Errorf("lossy column conversions with --strict: %s", strings.Join(risks, "; "))
+	}
+	return nil
+}
+
+// typeString renders a typeInfo back as a SQL type
+func typeString(info typeInfo) string {
+	switch {
+	case info.Precision > 0 && info.Scale > 0:
+		return fmt.Sprintf("%s(%d,%d)", info.Name, info.Precision, info.Scale)
+	case info.Precision > 0:
+		return fmt.Sprintf("%s(%d)", info.Name, info.Precision)
+	case info.Length > 0:
+		return fmt.Sprintf("%s(%d)", info.Name, info.Length)
+	}
+	return info.Name
+}
@@ -0,0 +1,47 @@
+package db
+
+import "fmt"
+
+// runMaintenance refreshes planner statistics and reclaims space on the destination
+// table after a successful copy. It must run outside of any transaction, since
+// VACUUM cannot be executed inside one.
+func (c *Copier) runMaintenance() error {
+	var statements []string
+
+	switch c.destDBType {
+	case DBTypePostgres:
+		// CockroachDB has no VACUUM; it garbage collects automatically
+		if c.destCockroach && c.Vacuum {
+			c.noticeOnce("vacuum", "VACUUM is not supported by CockroachDB and will be skipped")
+			if c.Analyze {
+				statements = append(statements, "ANALYZE "+c.destTableName())
+			}
+			break
+		}
+		switch {
+		case c.Vacuum && c.Analyze:
+			statements = append(statements, "VACUUM ANALYZE "+c.destTableName())
+		case c.Vacuum:
+			statements = append(statements, "VACUUM "+c.destTableName())
+		case c.Analyze:
+			statements = append(statements, "ANALYZE "+c.destTableName())
+		}
+	case DBTypeSQLite, DBTypeDuckDB:
+		// SQLite and DuckDB can only vacuum the whole database file
+		if c.Vacuum {
+			statements = append(statements, "VACUUM")
+		}
+		if c.Analyze {
+			statements = append(statements, "ANALYZE "+c.destTableName())
+		}
+	}
+
+	for _, stmt := range statements {
+		if err := c.destConn.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("failed to run %s: %w", stmt, err)
+		}
+		c.printf("Ran %s on destination database\n", stmt)
+	}
+
+	return nil
+}
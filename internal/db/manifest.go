@@ -0,0 +1,188 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// UnportableItem is a piece of source metadata that the destination did not
+// get: a comment, constraint, index or trigger it cannot hold, or that could
+// not be carried over as it is. Definition is in the terms of the source.
+type UnportableItem struct {
+	Table      string `json:"table"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name,omitempty"`
+	Definition string `json:"definition"`
+	Reason     string `json:"reason"`
+}
+
+// Kinds of unportable metadata
+const (
+	UnportableComment    = "comment"
+	UnportablePrimaryKey = "primary_key"
+	UnportableUnique     = "unique"
+	UnportableForeignKey = "foreign_key"
+	UnportableCheck      = "check"
+	UnportableIndex      = "index"
+	UnportableTrigger    = "trigger"
+)
+
+// Unportable returns the source metadata left behind so far, in the order it
+// was met, for a manifest of what has to be migrated by hand
+func (c *Copier) Unportable() []UnportableItem {
+	return append([]UnportableItem{}, c.unportable...)
+}
+
+// recordUnportable adds source metadata of the current table that the
+// destination did not get to the manifest
+func (c *Copier) recordUnportable(kind, name, definition, reason string) {
+	c.unportable = append(c.unportable, UnportableItem{
+		Table:      c.TableName,
+		Kind:       kind,
+		Name:       name,
+		Definition: definition,
+		Reason:     reason,
+	})
+}
+
+// Comment is a table or column comment of the source. Column is empty for the
+// comment of the table itself.
+type Comment struct {
+	Column  string
+	Comment string
+}
+
+// getSourceComments retrieves the table and column comments of a PostgreSQL
+// source table. Other sources have none.
+func (c *Copier) getSourceComments(table string) ([]Comment, error) {
+	if c.sourceDBType != DBTypePostgres || c.schemaFile != nil {
+		return nil, nil
+	}
+	var comments []Comment
+	if err := c.sourceConn.Raw(`
+		SELECT '' AS "column", obj_description(?::regclass, 'pg_class') AS comment
+		WHERE obj_description(?::regclass, 'pg_class') IS NOT NULL
+		UNION ALL
+		SELECT a.attname, col_description(a.attrelid, a.attnum)
+		FROM pg_attribute a
+		WHERE a.attrelid = ?::regclass AND a.attnum > 0 AND NOT a.attisdropped
+			AND col_description(a.attrelid, a.attnum) IS NOT NULL
+	`, table, table, table).Scan(&comments).Error; err != nil {
+		return nil, fmt.Errorf("failed to get comments: %w", err)
+	}
+	return comments, nil
+}
+
+// commentStatement returns the COMMENT ON statement that sets a comment on a
+// table, or on one of its columns
+func commentStatement(table string, comment Comment, column string) string {
+	target := "TABLE " + table
+	if comment.Column != "" {
+		target = "COLUMN " + table + "." + column
+	}
+	return fmt.Sprintf("COMMENT ON %s IS '%s'", target, strings.ReplaceAll(comment.Comment, "'", "''"))
+}
+
+// copyComments sets the source comments of the columns that are copied on a
+// PostgreSQL destination table, using tx. Other destinations have no
+// comments, so there they go to the manifest.
+func (c *Copier) copyComments(tx *gorm.DB, comments []Comment) error {
+	for _, comment := range comments {
+		if comment.Column != "" && !c.hasColumns([]string{comment.Column}) {
+			continue
+		}
+		if c.destDBType != DBTypePostgres {
+			c.recordUnportable(UnportableComment, comment.Column, commentStatement(c.TableName, comment, quoteIdent(comment.Column)), "the destination does not store comments")
+			continue
+		}
+		if err := tx.Exec(commentStatement(c.destTableName(), comment, quoteIdent(c.destColumn(comment.Column)))).Error; err != nil {
+			return fmt.Errorf("failed to copy comment: %w", err)
+		}
+	}
+	return nil
+}
+
+// indexStatement returns the CREATE INDEX statement of a source index
+func indexStatement(table string, idx Index) string {
+	unique := ""
+	if idx.Unique {
+		unique = "UNIQUE "
+	}
+	stmt := fmt.Sprintf("CREATE %sINDEX %s ON %s (%s)", unique, idx.Name, table, strings.Join(idx.Columns, ", "))
+	if idx.Predicate != "" {
+		stmt += " WHERE " + idx.Predicate
+	}
+	return stmt
+}
+
+// recordSchemaNotHeld adds the comments, keys, constraints, indexes and
+// triggers of the source table to the manifest when the destination, such as
+// a file or Redis, holds only the rows. The source is only asked for them
+// when a manifest is written.
+func (c *Copier) recordSchemaNotHeld() error {
+	if c.Manifest == "" || c.Query != "" {
+		return nil
+	}
+	reason := "the destination holds only rows"
+
+	comments, err := c.getSourceComments(c.TableName)
+	if err != nil {
+		return err
+	}
+	for _, comment := range comments {
+		c.recordUnportable(UnportableComment, comment.Column, commentStatement(c.TableName, comment, quoteIdent(comment.Column)), reason)
+	}
+
+	columns, err := c.getSourceSchema(c.TableName)
+	if err != nil {
+		return fmt.Errorf("failed to get source table schema: %w", err)
+	}
+	var primaryKey []string
+	for _, col := range columns {
+		if col.IsPrimary {
+			primaryKey = append(primaryKey, col.Name)
+		}
+	}
+	if len(primaryKey) > 0 {
+		c.recordUnportable(UnportablePrimaryKey, "", fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(primaryKey, ", ")), reason)
+	}
+
+	uniques, err := c.getSourceUniqueConstraints(c.TableName)
+	if err != nil {
+		return fmt.Errorf("failed to get source unique constraints: %w", err)
+	}
+	for _, uc := range uniques {
+		c.recordUnportable(UnportableUnique, uc.Name, uniqueDefinition(uc), reason)
+	}
+	foreignKeys, err := c.getSourceForeignKeys(c.TableName)
+	if err != nil {
+		return fmt.Errorf("failed to get source foreign keys: %w", err)
+	}
+	for _, fk := range foreignKeys {
+		c.recordUnportable(UnportableForeignKey, fk.Name, foreignKeyDefinition(fk), reason)
+	}
+	checks, err := c.getSourceCheckConstraints(c.TableName)
+	if err != nil {
+		return fmt.Errorf("failed to get source check constraints: %w", err)
+	}
+	for _, check := range checks {
+		c.recordUnportable(UnportableCheck, check.Name, checkDefinition(check), reason)
+	}
+	indexes, err := c.getSourceIndexes(c.TableName)
+	if err != nil {
+		return fmt.Errorf("failed to get source indexes: %w", err)
+	}
+	for _, idx := range indexes {
+		c.recordUnportable(UnportableIndex, idx.Name, indexStatement(c.TableName, idx), reason)
+	}
+	triggers, err := c.getSourceTriggers(c.TableName)
+	if err != nil {
+		return err
+	}
+	for _, trigger := range triggers {
+		c.recordUnportable(UnportableTrigger, trigger.Name, triggerStatements(trigger), reason)
+	}
+	return nil
+}
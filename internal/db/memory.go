@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// memoryCount numbers the in-memory databases opened by the copier, so that
+// copiers in one process never share one by accident
+var memoryCount atomic.Int64
+
+// isInMemorySQLite reports whether a SQLite connection string names an
+// in-memory database rather than a file
+func isInMemorySQLite(dsn string) bool {
+	return dsn == ":memory:" || strings.HasPrefix(dsn, "file::memory:") || strings.Contains(dsn, "mode=memory")
+}
+
+// newMemoryDSN returns the connection string of a new, empty in-memory
+// database that every connection of a pool opens
+func newMemoryDSN(name string) string {
+	return fmt.Sprintf("file:db_copy_%s_%d?mode=memory&cache=shared", name, memoryCount.Add(1))
+}
+
+// openSQLite opens a SQLite database. In-memory databases get a keepalive
+// connection, which holds the database for as long as it is open, so close it
+// only after the database. A plain :memory: database belongs to a single
+// connection, so it is replaced by a new shared-cache database that every
+// connection of the pool sees.
+func openSQLite(dsn string) (*gorm.DB, *sql.Conn, error) {
+	if !isInMemorySQLite(dsn) {
+		conn, err := gorm.Open(sqlite.Open(dsn), gormConfig())
+		return conn, nil, err
+	}
+	if dsn == ":memory:" || !strings.Contains(dsn, "cache=shared") {
+		dsn = newMemoryDSN("memory")
+	}
+
+	conn, err := gorm.Open(sqlite.Open(dsn), gormConfig())
+	if err != nil {
+		return nil, nil, err
+	}
+	sqlDB, err := conn.DB()
+	if err != nil {
+		return nil, nil, err
+	}
+	keepalive, err := sqlDB.Conn(context.Background())
+	if err != nil {
+		sqlDB.Close()
+		return nil, nil, err
+	}
+	return conn, keepalive, nil
+}
+
+// NewInMemoryCopier returns a connected copier between two new, empty
+// in-memory SQLite databases, for tests. The databases live until Close. Fill
+// the source through a connection of your own to SourceDB, which sees the same
+// data while the copier is connected, and read the copy back through DestDB.
+func NewInMemoryCopier(tableName string, batchSize int) (*Copier, error) {
+	c := NewCopier(newMemoryDSN("source"), newMemoryDSN("dest"), tableName, batchSize)
+	if err := c.Connect(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
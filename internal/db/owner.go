@@ -0,0 +1,24 @@
+package db
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// setDestOwner hands the created destination table to DestOwner using conn.
+// PostgreSQL moves the table's indexes and the sequences it owns along with
+// it, so one ALTER TABLE covers everything created for the table.
+func (c *Copier) setDestOwner(conn *gorm.DB) error {
+	if c.DestOwner == "" {
+		return nil
+	}
+	if c.destDBType != DBTypePostgres {
+		c.noticeOnce("dest-owner", "--dest-owner is only supported for PostgreSQL destinations and will be ignored")
+		return nil
+	}
+	if err := conn.Exec(fmt.Sprintf("ALTER TABLE %s OWNER TO %s;", c.destTableName(), c.DestOwner)).Error; err != nil {
+		return fmt.Errorf("failed to set owner of table %s to %s: %w", c.destTableName(), c.DestOwner, err)
+	}
+	return nil
+}
@@ -0,0 +1,442 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// isParquetDSN reports whether a destination is a Parquet file, or with the
+// parquet:// prefix a directory to write one Parquet file per table into
+func isParquetDSN(dsn string) bool {
+	return strings.HasPrefix(dsn, "parquet://") || strings.HasSuffix(strings.ToLower(dsn), ".parquet")
+}
+
+// parquetPath returns the file the current table is written to
+func (c *Copier) parquetPath() string {
+	path := strings.TrimPrefix(c.DestDB, "parquet://")
+	if strings.HasSuffix(strings.ToLower(path), ".parquet") {
+		return path
+	}
+	return filepath.Join(path, c.destTableName()+".parquet")
+}
+
+// checkParquetOptions rejects the options that need a destination database
+func (c *Copier) checkParquetOptions() error {
+	switch {
+	case c.AtomicSwap:
+		return errors.New("--atomic-swap is not supported for Parquet destinations; files are replaced only once complete anyway")
+	case c.Verify != "":
+		return errors.New("--verify is not supported for Parquet destinations")
+	case c.SkipUnchanged:
+		return errors.New("--skip-unchanged is not supported for Parquet destinations")
+	case c.SkipErrors:
+		return errors.New("--skip-errors is not supported for Parquet destinations")
+	}
+	return nil
+}
+
+// parquetType maps a source column type to the Parquet type it is written as:
+// INT64, DOUBLE, BOOLEAN, STRING, BYTES, TIMESTAMP, DATE, TIME, JSON or
+// DECIMAL(precision,scale). SQLite NUMERIC columns hold plain numbers and
+// become DOUBLE; exact numerics of other sources become DECIMAL(38,9).
+func parquetType(sourceType string, fromDB DBType) string {
+	switch {
+	case strings.Contains(sourceType, "INTERVAL"), strings.Contains(sourceType, "POINT"):
+		return "STRING"
+	case strings.Contains(sourceType, "INT"), sourceType == "SERIAL", sourceType == "BIGSERIAL":
+		return "INT64"
+	case strings.Contains(sourceType, "BOOL"):
+		return "BOOLEAN"
+	case strings.Contains(sourceType, "FLOAT"), strings.Contains(sourceType, "DOUBLE"), strings.Contains(sourceType, "REAL"):
+		return "DOUBLE"
+	case strings.Contains(sourceType, "NUMERIC"), strings.Contains(sourceType, "DECIMAL"):
+		if fromDB == DBTypeSQLite {
+			return "DOUBLE"
+		}
+		return "DECIMAL(38,9)"
+	case strings.Contains(sourceType, "TIMESTAMP"), strings.Contains(sourceType, "DATETIME"):
+		return "TIMESTAMP"
+	case strings.Contains(sourceType, "DATE"):
+		return "DATE"
+	case strings.Contains(sourceType, "TIME"):
+		return "TIME"
+	case strings.Contains(sourceType, "BLOB"), strings.Contains(sourceType, "BYTEA"), strings.Contains(sourceType, "BYTES"):
+		return "BYTES"
+	case strings.Contains(sourceType, "JSON"), sourceType == "RECORD", sourceType == "ARRAY":
+		return "JSON"
+	default:
+		return "STRING"
+	}
+}
+
+// parquetColumn is a column of the Parquet file and how its values are encoded
+type parquetColumn struct {
+	name      string
+	kind      string // parquetType without the decimal modifiers
+	precision int
+	scale     int
+	optional  bool
+}
+
+// parquetSchema builds the Parquet schema of the destination file. Nullable
+// columns become optional fields, so a NULL is stored as an absent value.
+func (c *Copier) parquetSchema(columns []Column) (*parquet.Schema, []parquetColumn, error) {
+	group := parquet.Group{}
+	byName := make(map[string]parquetColumn)
+	for _, col := range columns {
+		pc := parquetColumn{name: c.destColumn(col.Name), kind: col.Type, optional: col.IsNullable && !col.IsPrimary}
+		var node parquet.Node
+		switch {
+		case col.Type == "INT64":
+			node = parquet.Int(64)
+		case col.Type == "DOUBLE":
+			node = parquet.Leaf(parquet.DoubleType)
+		case col.Type == "BOOLEAN":
+			node = parquet.Leaf(parquet.BooleanType)
+		case col.Type == "BYTES":
+			node = parquet.Leaf(parquet.ByteArrayType)
+		case col.Type == "TIMESTAMP":
+			node = parquet.Timestamp(parquet.Microsecond)
+		case col.Type == "DATE":
+			node = parquet.Date()
+		case col.Type == "TIME":
+			node = parquet.Time(parquet.Microsecond)
+		case col.Type == "JSON":
+			node = parquet.JSON()
+		case strings.HasPrefix(col.Type, "DECIMAL("):
+			if _, err := fmt.Sscanf(col.Type, "DECIMAL(%d,%d)", &pc.precision, &pc.scale); err != nil || pc.precision < 1 || pc.precision > 38 || pc.scale < 0 || pc.scale > pc.precision {
+				return nil, nil, fmt.Errorf("column %s: invalid Parquet type %s: expected DECIMAL(precision,scale) with a precision of at most 38", col.Name, col.Type)
+			}
+			pc.kind = "DECIMAL"
+			physical := parquet.FixedLenByteArrayType(16)
+			if pc.precision <= 18 {
+				physical = parquet.Int64Type
+			}
+			node = parquet.Decimal(pc.scale, pc.precision, physical)
+		default:
+			pc.kind = "STRING"
+			node = parquet.String()
+		}
+		if pc.optional {
+			node = parquet.Optional(node)
+		}
+		group[pc.name] = node
+		byName[pc.name] = pc
+	}
+
+	// The schema orders its fields by name, and rows must follow that order
+	schema := parquet.NewSchema(c.destTableName(), group)
+	ordered := make([]parquetColumn, 0, len(byName))
+	for _, field := range schema.Fields() {
+		ordered = append(ordered, byName[field.Name()])
+	}
+	return schema, ordered, nil
+}
+
+// copyToParquet streams the current table into a Parquet file, one row group
+// per batch. The file is written under a temporary name and renamed when
+// complete, so a failed copy never leaves a truncated file behind.
+func (c *Copier) copyToParquet() error {
+	if err := c.checkParquetOptions(); err != nil {
+		return err
+	}
+	if err := c.recordSchemaNotHeld(); err != nil {
+		return &ErrSchema{Table: c.TableName, Err: err}
+	}
+	path := c.parquetPath()
+	if path == strings.TrimPrefix(c.DestDB, "parquet://") && len(c.copiedTables) > 0 {
+		return fmt.Errorf("--dest %s is a single Parquet file; give a directory with parquet:// to copy several tables", c.DestDB)
+	}
+
+	reader := c.newReader(c.tableBatchSize())
+	defer reader.Close()
+	columns, err := reader.Columns()
+	if err != nil {
+		return &ErrSchema{Table: c.destTableName(), Err: fmt.Errorf("failed to get source table schema: %w", err)}
+	}
+	schema, parquetColumns, err := c.parquetSchema(columns)
+	if err != nil {
+		return &ErrSchema{Table: c.destTableName(), Err: err}
+	}
+	if c.SchemaOnly {
+		c.copiedTables = append(c.copiedTables, c.destTableName())
+		return nil
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", path, err)
+		}
+	}
+	tmpPath := path + ".tmp"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to create Parquet file: %w", err)
+	}
+	defer os.Remove(tmpPath)
+	defer f.Close()
+	writer := parquet.NewWriter(f, schema)
+
+	destColumns, err := c.destColumnNames(columns)
+	if err != nil {
+		return err
+	}
+	nowValues := c.setNowValues(destColumns)
+	defaults := c.defaultValues(destColumns)
+	limiter := c.newRateLimiter(c.tableBatchSize())
+	total, read := 0, 0
+	defer c.startProgress(0)()
+	for batchNumber := 1; ; batchNumber++ {
+		if err := c.interrupted(); err != nil {
+			return err
+		}
+		batch, err := reader.ReadBatch(c.copyContext())
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			break
+		}
+		read += len(batch)
+		if batch, err = c.filterRows(batch, read-len(batch)+1); err != nil {
+			return err
+		}
+		if err := c.applyLookups(batch); err != nil {
+			return err
+		}
+		c.renameRecordColumns(batch)
+		if err := c.parseTimes(batch); err != nil {
+			return err
+		}
+		applySetNow(batch, nowValues)
+		applyDefaults(batch, defaults)
+
+		if err := c.throttle(limiter, len(batch)); err != nil {
+			return fmt.Errorf("rate limiter failed: %w", err)
+		}
+		rows := make([]parquet.Row, len(batch))
+		for i, record := range batch {
+			if rows[i], err = parquetRow(parquetColumns, record); err != nil {
+				return &ErrInsert{Table: c.destTableName(), Batch: batchNumber, Err: fmt.Errorf("row %d: %w", i+1, err)}
+			}
+		}
+		if _, err := writer.WriteRows(rows); err != nil {
+			return &ErrInsert{Table: c.destTableName(), Batch: batchNumber, Err: err}
+		}
+		if err := writer.Flush(); err != nil {
+			return &ErrInsert{Table: c.destTableName(), Batch: batchNumber, Err: err}
+		}
+		total += len(batch)
+		c.printBatch("Copied %d records\n", len(batch))
+		c.reportProgress(len(batch))
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to finish Parquet file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to finish Parquet file: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to move Parquet file into place: %w", err)
+	}
+
+	if total == 0 {
+		if c.FailOnEmpty {
+			return &ErrEmpty{Source: c.TableName}
+		}
+		c.printf("Source returned no rows; wrote an empty Parquet file\n")
+	}
+	c.printf("Successfully copied %d records from %s to Parquet file %s\n", total, c.TableName, path)
+	c.copiedTables = append(c.copiedTables, c.destTableName())
+	return nil
+}
+
+// parquetRow encodes a record as a row of the file's columns
+func parquetRow(columns []parquetColumn, record map[string]interface{}) (parquet.Row, error) {
+	row := make(parquet.Row, len(columns))
+	for i, col := range columns {
+		raw := record[col.name]
+		if raw == nil {
+			if !col.optional {
+				return nil, fmt.Errorf("column %s is NULL but not nullable", col.name)
+			}
+			row[i] = parquet.NullValue().Level(0, 0, i)
+			continue
+		}
+		value, err := parquetValue(col, raw)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", col.name, err)
+		}
+		definition := 0
+		if col.optional {
+			definition = 1
+		}
+		row[i] = value.Level(0, definition, i)
+	}
+	return row, nil
+}
+
+// parquetTimeLayouts are the text forms of timestamps read from SQLite
+var parquetTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+}
+
+// parquetValue converts a value read from the source to the physical value
+// of a Parquet column
+func parquetValue(col parquetColumn, raw interface{}) (parquet.Value, error) {
+	if b, ok := raw.([]byte); ok && col.kind != "BYTES" {
+		raw = string(b)
+	}
+	switch col.kind {
+	case "INT64":
+		switch v := raw.(type) {
+		case int64:
+			return parquet.Int64Value(v), nil
+		case int32:
+			return parquet.Int64Value(int64(v)), nil
+		case int:
+			return parquet.Int64Value(int64(v)), nil
+		case bool:
+			if v {
+				return parquet.Int64Value(1), nil
+			}
+			return parquet.Int64Value(0), nil
+		case float64:
+			if v == float64(int64(v)) {
+				return parquet.Int64Value(int64(v)), nil
+			}
+		case string:
+			if n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64); err == nil {
+				return parquet.Int64Value(n), nil
+			}
+		}
+	case "DOUBLE":
+		switch v := raw.(type) {
+		case float64:
+			return parquet.DoubleValue(v), nil
+		case float32:
+			return parquet.DoubleValue(float64(v)), nil
+		case int64:
+			return parquet.DoubleValue(float64(v)), nil
+		case int32:
+			return parquet.DoubleValue(float64(v)), nil
+		case string:
+			if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+				return parquet.DoubleValue(f), nil
+			}
+		}
+	case "BOOLEAN":
+		switch v := raw.(type) {
+		case bool:
+			return parquet.BooleanValue(v), nil
+		case int64:
+			return parquet.BooleanValue(v != 0), nil
+		case string:
+			if b, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+				return parquet.BooleanValue(b), nil
+			}
+		}
+	case "BYTES":
+		switch v := raw.(type) {
+		case []byte:
+			return parquet.ByteArrayValue(v), nil
+		case string:
+			return parquet.ByteArrayValue([]byte(v)), nil
+		}
+	case "TIMESTAMP", "DATE", "TIME":
+		t, ok := raw.(time.Time)
+		if s, isString := raw.(string); isString {
+			ok = parseParquetTime(col.kind, s, &t)
+		}
+		if ok {
+			switch col.kind {
+			case "TIMESTAMP":
+				return parquet.Int64Value(t.UnixMicro()), nil
+			case "DATE":
+				days := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix() / 86400
+				return parquet.Int32Value(int32(days)), nil
+			default:
+				midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+				return parquet.Int64Value(t.Sub(midnight).Microseconds()), nil
+			}
+		}
+	case "DECIMAL":
+		return parquetDecimal(col, raw)
+	default:
+		switch v := raw.(type) {
+		case string:
+			return parquet.ByteArrayValue([]byte(v)), nil
+		case time.Time:
+			return parquet.ByteArrayValue([]byte(v.Format(time.RFC3339Nano))), nil
+		default:
+			return parquet.ByteArrayValue([]byte(fmt.Sprint(v))), nil
+		}
+	}
+	return parquet.Value{}, fmt.Errorf("cannot write %T value %v as Parquet %s", raw, raw, col.kind)
+}
+
+// parseParquetTime parses the text form of a timestamp, date or time of day
+func parseParquetTime(kind, s string, t *time.Time) bool {
+	layouts := parquetTimeLayouts
+	if kind == "TIME" {
+		layouts = []string{"15:04:05.999999999", "15:04:05.999999999Z07:00", "15:04:05.999999999-07"}
+	}
+	for _, layout := range layouts {
+		if parsed, err := time.Parse(layout, strings.TrimSpace(s)); err == nil {
+			*t = parsed
+			return true
+		}
+	}
+	return false
+}
+
+// parquetDecimal encodes a number as the unscaled integer of a DECIMAL column,
+// rounding half away from zero to the column's scale: an INT64 for
+// precisions up to 18 and a 16-byte two's complement integer above that
+func parquetDecimal(col parquetColumn, raw interface{}) (parquet.Value, error) {
+	r := new(big.Rat)
+	ok := true
+	switch v := raw.(type) {
+	case int64:
+		r.SetInt64(v)
+	case int32:
+		r.SetInt64(int64(v))
+	case float64:
+		r.SetFloat64(v)
+	case string:
+		_, ok = r.SetString(strings.TrimSpace(v))
+	default:
+		_, ok = r.SetString(fmt.Sprint(v))
+	}
+	if !ok {
+		return parquet.Value{}, fmt.Errorf("cannot write %T value %v as Parquet DECIMAL", raw, raw)
+	}
+
+	pow := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(col.scale)), nil)
+	unscaled, _ := new(big.Int).SetString(new(big.Rat).Mul(r, new(big.Rat).SetInt(pow)).FloatString(0), 10)
+	limit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(col.precision)), nil)
+	if new(big.Int).Abs(unscaled).Cmp(limit) >= 0 {
+		return parquet.Value{}, fmt.Errorf("value %v does not fit DECIMAL(%d,%d)", raw, col.precision, col.scale)
+	}
+	if col.precision <= 18 {
+		return parquet.Int64Value(unscaled.Int64()), nil
+	}
+
+	if unscaled.Sign() < 0 {
+		unscaled.Add(unscaled, new(big.Int).Lsh(big.NewInt(1), 128))
+	}
+	return parquet.FixedLenByteArrayValue(unscaled.FillBytes(make([]byte, 16))), nil
+}
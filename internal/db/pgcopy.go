@@ -0,0 +1,206 @@
+package db
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultCopyNull is the string PostgreSQL's COPY text format reads as NULL
+const DefaultCopyNull = `\N`
+
+// copyTimeFormat formats timestamps for COPY. PostgreSQL keeps microseconds
+// and ignores the offset for columns without a time zone.
+const copyTimeFormat = "2006-01-02 15:04:05.999999Z07:00"
+
+// copyEncoder writes rows in the text format of PostgreSQL's COPY FROM STDIN:
+// one line per row with tab-separated fields. Values are escaped so that
+// backslashes, tabs and line breaks inside them survive, and a value that
+// equals the NULL string cannot be confused with NULL.
+type copyEncoder struct {
+	// null is written for NULL values
+	null string
+	// bytea marks the columns whose []byte values are binary data, written in
+	// hex; other []byte values are text
+	bytea []bool
+}
+
+// newCopyEncoder returns an encoder for rows of the given destination column
+// types. An empty null string stands for DefaultCopyNull.
+func newCopyEncoder(columnTypes []string, null string) *copyEncoder {
+	if null == "" {
+		null = DefaultCopyNull
+	}
+	bytea := make([]bool, len(columnTypes))
+	for i, colType := range columnTypes {
+		bytea[i] = strings.EqualFold(colType, "BYTEA")
+	}
+	return &copyEncoder{null: null, bytea: bytea}
+}
+
+// appendRow appends one row, terminated by a newline, to buf
+func (e *copyEncoder) appendRow(buf []byte, values []interface{}) ([]byte, error) {
+	for i, value := range values {
+		if i > 0 {
+			buf = append(buf, '\t')
+		}
+		text, isNull, err := e.formatValue(value, i < len(e.bytea) && e.bytea[i])
+		if err != nil {
+			return nil, fmt.Errorf("column %d: %w", i+1, err)
+		}
+		if isNull {
+			buf = append(buf, e.null...)
+			continue
+		}
+		start := len(buf)
+		buf = appendCopyEscaped(buf, text)
+		if string(buf[start:]) == e.null {
+			// Spell the first byte as an octal escape so the value is not
+			// read as NULL
+			buf = append(buf[:start], fmt.Sprintf(`\%03o`, text[0])...)
+			buf = appendCopyEscaped(buf, text[1:])
+		}
+	}
+	return append(buf, '\n'), nil
+}
+
+// formatValue returns the PostgreSQL input text of a value, before COPY
+// escaping, or reports a NULL
+func (e *copyEncoder) formatValue(value interface{}, bytea bool) (string, bool, error) {
+	if valuer, ok := value.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return "", false, err
+		}
+		value = v
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return "", true, nil
+	case string:
+		return v, false, nil
+	case []byte:
+		if bytea {
+			return `\x` + hex.EncodeToString(v), false, nil
+		}
+		return string(v), false, nil
+	case bool:
+		if v {
+			return "t", false, nil
+		}
+		return "f", false, nil
+	case time.Time:
+		return v.Format(copyTimeFormat), false, nil
+	case float32:
+		return formatCopyFloat(float64(v), 32), false, nil
+	case float64:
+		return formatCopyFloat(v, 64), false, nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(v), false, nil
+	}
+
+	if rv := reflect.ValueOf(value); rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		text, err := e.formatArray(rv)
+		return text, false, err
+	}
+	return fmt.Sprint(value), false, nil
+}
+
+// formatCopyFloat formats a float the way PostgreSQL reads it back exactly,
+// including the special values
+func formatCopyFloat(f float64, bits int) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	}
+	return strconv.FormatFloat(f, 'g', -1, bits)
+}
+
+// formatArray formats a slice as a PostgreSQL array literal such as
+// {1,2,NULL} or {"a b","c\"d"}. Nested slices become multi-dimensional arrays.
+func (e *copyEncoder) formatArray(rv reflect.Value) (string, error) {
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i := 0; i < rv.Len(); i++ {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		elem := rv.Index(i).Interface()
+		if nested := reflect.ValueOf(elem); elem != nil && (nested.Kind() == reflect.Slice || nested.Kind() == reflect.Array) {
+			if _, isBytes := elem.([]byte); !isBytes {
+				text, err := e.formatArray(nested)
+				if err != nil {
+					return "", err
+				}
+				sb.WriteString(text)
+				continue
+			}
+		}
+		text, isNull, err := e.formatValue(elem, false)
+		if err != nil {
+			return "", err
+		}
+		if isNull {
+			sb.WriteString("NULL")
+			continue
+		}
+		sb.WriteString(quoteArrayElement(text))
+	}
+	sb.WriteByte('}')
+	return sb.String(), nil
+}
+
+// quoteArrayElement double-quotes an array element when it would otherwise be
+// read differently: when empty, NULL, or containing delimiters, quotes,
+// backslashes or whitespace
+func quoteArrayElement(text string) string {
+	if text != "" && !strings.EqualFold(text, "NULL") && !strings.ContainsAny(text, "{},\"\\ \t\n\r\v\f") {
+		return text
+	}
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range text {
+		if r == '"' || r == '\\' {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// appendCopyEscaped appends a field value to buf with the backslash escapes of
+// the COPY text format
+func appendCopyEscaped(buf []byte, text string) []byte {
+	for i := 0; i < len(text); i++ {
+		switch ch := text[i]; ch {
+		case '\\':
+			buf = append(buf, '\\', '\\')
+		case '\n':
+			buf = append(buf, '\\', 'n')
+		case '\r':
+			buf = append(buf, '\\', 'r')
+		case '\t':
+			buf = append(buf, '\\', 't')
+		case '\b':
+			buf = append(buf, '\\', 'b')
+		case '\f':
+			buf = append(buf, '\\', 'f')
+		case '\v':
+			buf = append(buf, '\\', 'v')
+		default:
+			buf = append(buf, ch)
+		}
+	}
+	return buf
+}
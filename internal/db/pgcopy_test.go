@@ -0,0 +1,134 @@
+package db
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCopyEncoderAppendRow(t *testing.T) {
+	stamp := time.Date(2024, 2, 29, 13, 4, 5, 123456000, time.UTC)
+	tests := []struct {
+		name   string
+		types  []string
+		null   string
+		values []interface{}
+		want   string
+	}{
+		{"null", []string{"TEXT", "INTEGER"}, "", []interface{}{nil, 1}, "\\N\t1\n"},
+		{"custom null", []string{"TEXT"}, "NULL", []interface{}{nil}, "NULL\n"},
+		{"value equal to null", []string{"TEXT"}, "NULL", []interface{}{"NULL"}, "\\116ULL\n"},
+		{"value equal to default null", []string{"TEXT"}, "", []interface{}{`\N`}, "\\\\N\n"},
+		{"empty string", []string{"TEXT"}, "", []interface{}{""}, "\n"},
+		{"tab", []string{"TEXT"}, "", []interface{}{"a\tb"}, "a\\tb\n"},
+		{"newline", []string{"TEXT"}, "", []interface{}{"a\nb\r\n"}, "a\\nb\\r\\n\n"},
+		{"backslash", []string{"TEXT"}, "", []interface{}{`C:\tmp`}, "C:\\\\tmp\n"},
+		{"control characters", []string{"TEXT"}, "", []interface{}{"\b\f\v"}, "\\b\\f\\v\n"},
+		{"bool", []string{"BOOLEAN", "BOOLEAN"}, "", []interface{}{true, false}, "t\tf\n"},
+		{"bytea", []string{"BYTEA"}, "", []interface{}{[]byte{0x00, 0xde, 0xad, 0xff}}, "\\\\x00deadff\n"},
+		{"bytes as text", []string{"TEXT"}, "", []interface{}{[]byte("a\tb")}, "a\\tb\n"},
+		{"timestamp", []string{"TIMESTAMPTZ"}, "", []interface{}{stamp}, "2024-02-29 13:04:05.123456Z\n"},
+		{"timestamp with offset", []string{"TIMESTAMPTZ"}, "", []interface{}{stamp.In(time.FixedZone("", 2*60*60))}, "2024-02-29 15:04:05.123456+02:00\n"},
+		{"integers", []string{"BIGINT", "SMALLINT"}, "", []interface{}{int64(-42), uint8(7)}, "-42\t7\n"},
+		{"floats", []string{"DOUBLE PRECISION", "REAL"}, "", []interface{}{0.1, float32(2.5)}, "0.1\t2.5\n"},
+		{"special floats", []string{"DOUBLE PRECISION", "DOUBLE PRECISION", "DOUBLE PRECISION"}, "", []interface{}{math.NaN(), math.Inf(1), math.Inf(-1)}, "NaN\tInfinity\t-Infinity\n"},
+		{"array", []string{"_INT4"}, "", []interface{}{[]int{1, 2, 3}}, "{1,2,3}\n"},
+		{"array with null", []string{"_TEXT"}, "", []interface{}{[]interface{}{"a", nil}}, "{a,NULL}\n"},
+		{"array quoting", []string{"_TEXT"}, "", []interface{}{[]string{"a b", `c"d`, "", "null", `e\f`}}, "{\"a b\",\"c\\\\\"d\",\"\",\"null\",\"e\\\\\\\\f\"}\n"},
+		{"nested array", []string{"_INT4"}, "", []interface{}{[][]int{{1, 2}, {3, 4}}}, "{{1,2},{3,4}}\n"},
+		{"array with tab", []string{"_TEXT"}, "", []interface{}{[]string{"a\tb"}}, "{\"a\\tb\"}\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newCopyEncoder(tt.types, tt.null).appendRow(nil, tt.values)
+			if err != nil {
+				t.Fatalf("appendRow: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("appendRow(%#v) = %q, want %q", tt.values, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCopyEncoderRoundTrip(t *testing.T) {
+	values := []string{"plain", "tab\there", "line\nbreak", `back\slash`, "\\N", "NULL", "\\.", "mixed\t\\\r\n\b\f\v end", "ünïcødé"}
+	for _, null := range []string{"", "NULL"} {
+		e := newCopyEncoder(make([]string, len(values)+1), null)
+		row := make([]interface{}, 0, len(values)+1)
+		for _, v := range values {
+			row = append(row, v)
+		}
+		row = append(row, nil)
+
+		line, err := e.appendRow(nil, row)
+		if err != nil {
+			t.Fatalf("appendRow: %v", err)
+		}
+		fields := decodeCopyLine(t, strings.TrimSuffix(string(line), "\n"), e.null)
+		if len(fields) != len(row) {
+			t.Fatalf("null %q: decoded %d fields, want %d", null, len(fields), len(row))
+		}
+		for i, v := range values {
+			if fields[i] == nil || *fields[i] != v {
+				t.Errorf("null %q: field %d decoded as %v, want %q", null, i+1, fields[i], v)
+			}
+		}
+		if fields[len(values)] != nil {
+			t.Errorf("null %q: NULL decoded as %q", null, *fields[len(values)])
+		}
+	}
+}
+
+// decodeCopyLine reads one line of the COPY text format the way PostgreSQL
+// does, returning nil for NULL fields
+func decodeCopyLine(t *testing.T, line, null string) []*string {
+	t.Helper()
+	var fields []*string
+	for _, raw := range strings.Split(line, "\t") {
+		if raw == null {
+			fields = append(fields, nil)
+			continue
+		}
+		var sb strings.Builder
+		for i := 0; i < len(raw); i++ {
+			if raw[i] != '\\' {
+				sb.WriteByte(raw[i])
+				continue
+			}
+			i++
+			if i == len(raw) {
+				t.Fatalf("dangling backslash in %q", raw)
+			}
+			switch ch := raw[i]; {
+			case ch >= '0' && ch <= '7':
+				end := i + 1
+				for end < len(raw) && end < i+3 && raw[end] >= '0' && raw[end] <= '7' {
+					end++
+				}
+				n, _ := strconv.ParseUint(raw[i:end], 8, 8)
+				sb.WriteByte(byte(n))
+				i = end - 1
+			case ch == 'n':
+				sb.WriteByte('\n')
+			case ch == 'r':
+				sb.WriteByte('\r')
+			case ch == 't':
+				sb.WriteByte('\t')
+			case ch == 'b':
+				sb.WriteByte('\b')
+			case ch == 'f':
+				sb.WriteByte('\f')
+			case ch == 'v':
+				sb.WriteByte('\v')
+			default:
+				sb.WriteByte(ch)
+			}
+		}
+		field := sb.String()
+		fields = append(fields, &field)
+	}
+	return fields
+}
@@ -0,0 +1,53 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+// printBatch prints the line reported after each inserted batch. With
+// ProgressInterval the timed reports of startProgress replace it.
+func (c *Copier) printBatch(format string, rows int) {
+	if c.ProgressInterval > 0 {
+		return
+	}
+	c.printf(format, rows)
+}
+
+// startProgress prints the number of rows copied into the current table every
+// ProgressInterval until the returned function is called. The count is read
+// from the counter every writer adds to, so reports come at a steady pace
+// whatever the batch size. total is the number of rows to copy, or 0 when it
+// is not known up front.
+func (c *Copier) startProgress(total int) func() {
+	if c.ProgressInterval <= 0 {
+		return func() {}
+	}
+
+	start := time.Now()
+	base := c.rowsCopied.Load()
+	table := c.destTableName()
+	ticker := time.NewTicker(c.ProgressInterval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				copied := c.rowsCopied.Load() - base
+				of := ""
+				if total > 0 {
+					of = fmt.Sprintf(" of %d (%.0f%%)", total, float64(copied)*100/float64(total))
+				}
+				rate := float64(copied) / time.Since(start).Seconds()
+				c.printf("Progress: copied %d%s records into %s, %.0f rows/s\n", copied, of, table, rate)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
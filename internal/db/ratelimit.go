@@ -0,0 +1,33 @@
+package db
+
+import (
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// newRateLimiter returns a token bucket allowing RateLimit rows per second, or nil
+// when the copy is unlimited. The burst covers a full batch so that a batch can
+// always acquire its rows, and the bucket starts empty so the first batches do
+// not exceed the rate.
+func (c *Copier) newRateLimiter(batchSize int) *rate.Limiter {
+	if c.RateLimit <= 0 {
+		return nil
+	}
+	burst := c.RateLimit
+	if batchSize > burst {
+		burst = batchSize
+	}
+	limiter := rate.NewLimiter(rate.Limit(c.RateLimit), burst)
+	limiter.AllowN(time.Now(), burst)
+	return limiter
+}
+
+// throttle blocks until the limiter allows rows more rows to be written or
+// the copy is cancelled
+func (c *Copier) throttle(limiter *rate.Limiter, rows int) error {
+	if limiter == nil {
+		return nil
+	}
+	return limiter.WaitN(c.copyContext(), rows)
+}
@@ -0,0 +1,261 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// RowReader reads the rows of a source in batches. Paired with a RowWriter it
+// lets the copier move rows between any source and destination.
+type RowReader interface {
+	// Columns describes the columns of the rows that are read
+	Columns() ([]Column, error)
+	// ReadBatch returns the next batch of rows keyed by column name, or no
+	// rows once the source is exhausted
+	ReadBatch(ctx context.Context) ([]map[string]interface{}, error)
+	// Close releases the source cursor
+	Close() error
+}
+
+// sqlReader reads the current table or query of a SQL source database through
+// a cursor, with Paginate through one query per batch, or with FetchSize
+// through a server-side cursor. With SourceQueryTimeout the whole read is
+// cancelled once it runs longer than that.
+type sqlReader struct {
+	c         *Copier
+	batchSize int
+	rows      *sql.Rows
+	ctx       context.Context
+	cancel    context.CancelFunc
+
+	// Paginated reads continue after lastKey in keyColumn order, or at offset
+	// when the table has no single-column key
+	columns   []Column
+	keyColumn string
+	lastKey   interface{}
+	offset    int
+
+	// Server-side cursor reads fetch from the cursor declared in this
+	// transaction until a fetch returns fewer rows than FetchSize
+	cursor      *gorm.DB
+	ownCursorTx bool
+	fetched     int
+	cursorDone  bool
+}
+
+// newSQLReader returns a RowReader for the source table or query
+func (c *Copier) newSQLReader() *sqlReader {
+	return &sqlReader{c: c, batchSize: c.tableBatchSize()}
+}
+
+// newReader returns the RowReader for the source table or query, reading
+// batches of up to batchSize rows
+func (c *Copier) newReader(batchSize int) RowReader {
+	if c.sourceDBType == DBTypeBigQuery {
+		return &bigQueryReader{c: c, batchSize: batchSize}
+	}
+	reader := c.newSQLReader()
+	reader.batchSize = batchSize
+	return reader
+}
+
+func (r *sqlReader) Columns() ([]Column, error) {
+	return r.c.sourceColumns()
+}
+
+func (r *sqlReader) ReadBatch(ctx context.Context) ([]map[string]interface{}, error) {
+	if r.c.Paginate && r.c.Query == "" {
+		return r.readPage(ctx)
+	}
+	if r.c.usesCursor() {
+		return r.readCursor(ctx)
+	}
+	if r.rows == nil {
+		if err := r.open(ctx); err != nil {
+			return nil, r.readError(err)
+		}
+	}
+
+	return r.scanBatch()
+}
+
+// scanBatch reads up to batchSize rows from the open cursor
+func (r *sqlReader) scanBatch() ([]map[string]interface{}, error) {
+	var batch []map[string]interface{}
+	for len(batch) < r.batchSize && r.rows.Next() {
+		record := make(map[string]interface{})
+		if err := r.c.sourceConn.ScanRows(r.rows, &record); err != nil {
+			return nil, r.readError(fmt.Errorf("failed to read source row: %w", err))
+		}
+		batch = append(batch, record)
+	}
+	if err := r.rows.Err(); err != nil {
+		return nil, r.readError(fmt.Errorf("failed to read from source: %w", err))
+	}
+	return batch, nil
+}
+
+// open runs the source query and keeps its cursor
+func (r *sqlReader) open(ctx context.Context) error {
+	r.startRead(ctx)
+
+	var err error
+	if r.c.Query != "" {
+		if r.rows, err = r.c.sourceConn.WithContext(r.ctx).Raw(r.c.sourceQuery()).Rows(); err != nil {
+			return fmt.Errorf("failed to execute source query: %w", err)
+		}
+		return nil
+	}
+	query, err := r.tableQuery()
+	if err != nil {
+		return err
+	}
+	if r.rows, err = query.Rows(); err != nil {
+		return fmt.Errorf("failed to read from source table: %w", err)
+	}
+	return nil
+}
+
+// startRead sets the context of the whole read, limited by SourceQueryTimeout
+func (r *sqlReader) startRead(ctx context.Context) {
+	r.ctx = ctx
+	if r.c.SourceQueryTimeout > 0 {
+		r.ctx, r.cancel = context.WithTimeout(ctx, r.c.SourceQueryTimeout)
+	}
+}
+
+// tableQuery builds the query that reads the selected rows of the source table
+func (r *sqlReader) tableQuery() (*gorm.DB, error) {
+	if r.columns == nil {
+		columns, err := r.c.sourceColumns()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get source table schema: %w", err)
+		}
+		r.columns = columns
+	}
+	selects := r.c.sourceSelects(r.columns)
+	query := r.c.timeRange(r.c.sourceConn.WithContext(r.ctx).Table(r.c.TableName).Select(selects), r.columns)
+	query = r.c.watermarkRange(query)
+	return r.c.distinctRows(query, selects), nil
+}
+
+// readPage reads the next batch with a query of its own. Rows are ordered by
+// the table's primary key and each page starts after the last key read, which
+// stays fast however far into the table it is. Tables without a single-column
+// primary key are paged with OFFSET instead.
+func (r *sqlReader) readPage(ctx context.Context) ([]map[string]interface{}, error) {
+	if r.ctx == nil {
+		r.startRead(ctx)
+	}
+	query, err := r.tableQuery()
+	if err != nil {
+		return nil, r.readError(err)
+	}
+	if r.offset == 0 && r.lastKey == nil {
+		r.keyColumn = r.c.paginationKey(r.columns)
+	}
+
+	if r.keyColumn != "" {
+		if r.lastKey != nil {
+			query = query.Where(fmt.Sprintf("%s > ?", quoteIdent(r.keyColumn)), r.lastKey)
+		}
+		query = query.Order(quoteIdent(r.keyColumn))
+	} else {
+		// Separate queries return rows in no particular order, so OFFSET pages
+		// only follow on from each other when ordered by every selected column
+		for _, col := range r.columns {
+			query = query.Order(quoteIdent(col.Name))
+		}
+		query = query.Offset(r.offset)
+	}
+	if r.rows, err = query.Limit(r.batchSize).Rows(); err != nil {
+		return nil, r.readError(fmt.Errorf("failed to read from source table: %w", err))
+	}
+	batch, err := r.scanBatch()
+	if closeErr := r.rows.Close(); err == nil && closeErr != nil {
+		err = r.readError(closeErr)
+	}
+	r.rows = nil
+	if err != nil || len(batch) == 0 {
+		return batch, err
+	}
+
+	r.offset += len(batch)
+	if r.keyColumn != "" {
+		r.lastKey = batch[len(batch)-1][r.keyColumn]
+	}
+	return batch, nil
+}
+
+// paginationKey returns the column a paginated read of the source table is
+// keyed on: its primary key when that is a single selected column, or the
+// implicit key of a table without one. Rows collapsed by --distinct or
+// --distinct-on have no such key.
+func (c *Copier) paginationKey(columns []Column) string {
+	var keys []string
+	selected := make(map[string]bool, len(columns))
+	for _, col := range columns {
+		selected[col.Name] = true
+		if col.IsPrimary {
+			keys = append(keys, col.Name)
+		}
+	}
+	reason := "no single-column primary key"
+	if c.Distinct || len(c.DistinctOn) > 0 {
+		reason = "rows are collapsed by --distinct or --distinct-on"
+	} else if len(keys) == 1 && c.explicitKey() == "" {
+		return keys[0]
+	} else if len(keys) == 0 || c.explicitKey() != "" {
+		key, err := c.implicitKeyColumn()
+		if err != nil {
+			reason = err.Error()
+		} else if selected[key] {
+			return key
+		}
+	}
+	zap.L().Warn("Paging source table with OFFSET, ordered by every selected column so that pages neither repeat nor skip rows; this slows down as the copy progresses",
+		zap.String("table", c.TableName), zap.String("reason", reason))
+	return ""
+}
+
+// readError explains an error caused by SourceQueryTimeout
+func (r *sqlReader) readError(err error) error {
+	if r.ctx != nil && errors.Is(r.ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("source read did not finish within %s: %w", r.c.SourceQueryTimeout, err)
+	}
+	return err
+}
+
+func (r *sqlReader) Close() error {
+	var err error
+	if r.rows != nil {
+		err = r.rows.Close()
+	}
+	if cursorErr := r.closeCursor(); err == nil {
+		err = cursorErr
+	}
+	if r.cancel != nil {
+		r.cancel()
+	}
+	return err
+}
+
+// readAll reads every remaining row of a RowReader
+func readAll(ctx context.Context, reader RowReader) ([]map[string]interface{}, error) {
+	var records []map[string]interface{}
+	for {
+		batch, err := reader.ReadBatch(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if len(batch) == 0 {
+			return records, nil
+		}
+		records = append(records, batch...)
+	}
+}
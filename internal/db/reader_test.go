@@ -0,0 +1,50 @@
+package db
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPaginateWithOffset(t *testing.T) {
+	// A composite primary key gives no single column to page on
+	c := newTestCopier(t, "tags", 3,
+		"CREATE TABLE tags (name TEXT, weight INTEGER, PRIMARY KEY (name, weight))",
+		`INSERT INTO tags (name, weight) VALUES
+			('c', 3), ('a', 1), ('e', 5), ('b', 2), ('d', 4), ('g', 7), ('f', 6),
+			('b', 1), ('g', 6), ('a', 2), ('d', 3), ('f', 5), ('c', 4), ('e', 7)`,
+	)
+	c.Paginate = true
+	if err := c.Copy(); err != nil {
+		t.Fatalf("Copy: %v", err)
+	}
+
+	var copied, distinct int64
+	if err := c.destConn.Raw("SELECT COUNT(*), COUNT(DISTINCT name || weight) FROM tags").Row().Scan(&copied, &distinct); err != nil {
+		t.Fatalf("count destination rows: %v", err)
+	}
+	if copied != 14 || distinct != 14 {
+		t.Errorf("destination has %d rows, %d of them distinct, want 14 distinct rows", copied, distinct)
+	}
+}
+
+func TestPaginateDistinct(t *testing.T) {
+	c := newTestCopier(t, "tags", 2,
+		"CREATE TABLE tags (id INTEGER PRIMARY KEY, name TEXT)",
+		"INSERT INTO tags (id, name) VALUES (1, 'c'), (2, 'a'), (3, 'c'), (4, 'b'), (5, 'a'), (6, 'd'), (7, 'b')",
+	)
+	c.Paginate = true
+	c.Distinct = true
+	c.Columns = map[string][]string{"tags": {"name"}}
+	c.DestTable = "names"
+	if err := c.Copy(); err != nil {
+		t.Fatalf("Copy: %v", err)
+	}
+
+	var names []string
+	if err := c.destConn.Raw("SELECT name FROM names ORDER BY name").Scan(&names).Error; err != nil {
+		t.Fatalf("read destination: %v", err)
+	}
+	if got, want := fmt.Sprint(names), "[a b c d]"; got != want {
+		t.Errorf("destination has names %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,55 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// errDestReadOnly is returned by ConnectDest when DestReadonlyCheck finds that
+// the destination does not accept writes
+var errDestReadOnly = errors.New("destination is read-only")
+
+// checkDestWritable fails when the destination cannot be written to, such as
+// a PostgreSQL hot standby or a SQLite file without write permission, so that
+// the copy stops before any source data is read
+func (c *Copier) checkDestWritable() error {
+	switch c.destDBType {
+	case DBTypePostgres:
+		var readOnly string
+		if err := c.destConn.Raw("SHOW transaction_read_only").Scan(&readOnly).Error; err != nil {
+			return fmt.Errorf("failed to check whether the destination is read-only: %w", err)
+		}
+		if readOnly == "on" {
+			return errDestReadOnly
+		}
+	case DBTypeSQLite, DBTypeDuckDB:
+		// Create a table in a transaction that is rolled back, which leaves
+		// the database untouched
+		probe := errors.New("rollback write check")
+		err := c.destConn.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec("CREATE TABLE db_copy_write_check (id INTEGER)").Error; err != nil {
+				return err
+			}
+			return probe
+		})
+		if err != nil && !errors.Is(err, probe) {
+			if msg := strings.ToLower(err.Error()); strings.Contains(msg, "readonly") || strings.Contains(msg, "read-only") {
+				return errDestReadOnly
+			}
+			return fmt.Errorf("failed to check whether the destination is writable: %w", err)
+		}
+	case DBTypeRedis:
+		// Replicas reject writes unless replica-read-only was turned off
+		info, err := c.redisConn.Info(c.copyContext(), "replication").Result()
+		if err != nil {
+			return fmt.Errorf("failed to check whether the destination is read-only: %w", err)
+		}
+		if strings.Contains(info, "role:slave") {
+			return errDestReadOnly
+		}
+	}
+	return nil
+}
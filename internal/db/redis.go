@@ -0,0 +1,108 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// isRedisDSN reports whether a connection string names a Redis server
+func isRedisDSN(dsn string) bool {
+	return strings.HasPrefix(dsn, "redis://") || strings.HasPrefix(dsn, "rediss://")
+}
+
+// openRedis connects to the Redis server of a redis:// or rediss:// URL
+func openRedis(dsn string) (*redis.Client, error) {
+	opts, err := redis.ParseURL(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis URL: %w", err)
+	}
+	client := redis.NewClient(opts)
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+	return client, nil
+}
+
+// copyToRedis writes each row of the current table to Redis as a hash keyed
+// by the destination table name and the primary key value, e.g. users:42.
+// Redis has no schema, so nothing is created beforehand.
+func (c *Copier) copyToRedis() error {
+	if c.Query != "" {
+		return errors.New("Redis destinations need a primary key and cannot be used with a query")
+	}
+	if err := c.recordSchemaNotHeld(); err != nil {
+		return &ErrSchema{Table: c.TableName, Err: err}
+	}
+	if c.SchemaOnly {
+		c.copiedTables = append(c.copiedTables, c.destTableName())
+		return nil
+	}
+
+	primaryKeyColumn, err := c.getPrimaryKeyColumnName()
+	if err != nil {
+		return &ErrSchema{Table: c.TableName, Err: fmt.Errorf("failed to get primary key column name: %w", err)}
+	}
+	if primaryKeyColumn == "" || !c.hasColumns([]string{primaryKeyColumn}) {
+		return &ErrSchema{Table: c.TableName, Err: errors.New("Redis destinations need a primary key column to build keys from")}
+	}
+
+	reader := c.newReader(c.tableBatchSize())
+	defer reader.Close()
+	columns, err := reader.Columns()
+	if err != nil {
+		return &ErrSchema{Table: c.TableName, Err: fmt.Errorf("failed to get source table schema: %w", err)}
+	}
+	records, err := readAll(c.copyContext(), reader)
+	if err != nil {
+		return err
+	}
+	if records, err = c.filterRows(records, 1); err != nil {
+		return err
+	}
+	if err := c.applyLookups(records); err != nil {
+		return err
+	}
+	c.renameRecordColumns(records)
+	primaryKeyColumn = c.destColumn(primaryKeyColumn)
+
+	writer := c.newRedisWriter(primaryKeyColumn)
+	destColumns, err := c.destColumnNames(columns)
+	if err != nil {
+		return err
+	}
+	nowValues := c.setNowValues(destColumns)
+	defaults := c.defaultValues(destColumns)
+	limiter := c.newRateLimiter(c.tableBatchSize())
+	defer c.startProgress(len(records))()
+	for batchNumber, bounds := range batchBounds(len(records), c.tableBatchSize()) {
+		if err := c.interrupted(); err != nil {
+			return err
+		}
+
+		batch := records[bounds[0]:bounds[1]]
+		applySetNow(batch, nowValues)
+		applyDefaults(batch, defaults)
+
+		if err := c.throttle(limiter, len(batch)); err != nil {
+			return fmt.Errorf("rate limiter failed: %w", err)
+		}
+		if err := writer.WriteBatch(batch); err != nil {
+			return &ErrInsert{Table: c.destTableName(), Batch: batchNumber + 1, Err: err}
+		}
+		c.printBatch("Copied %d records\n", len(batch))
+		c.reportProgress(len(batch))
+	}
+
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	c.printf("Successfully copied %d records from %s to Redis keys %s:<%s>\n", len(records), c.TableName, c.destTableName(), primaryKeyColumn)
+	c.copiedTables = append(c.copiedTables, c.destTableName())
+	return nil
+}
@@ -0,0 +1,116 @@
+package db
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// TableResult records the outcome of copying one table
+type TableResult struct {
+	Table           string  `json:"table"`
+	Rows            int64   `json:"rows"`
+	SkippedRows     int64   `json:"skipped_rows,omitempty"`
+	DurationSeconds float64 `json:"duration_seconds"`
+	Skipped         string  `json:"skipped,omitempty"`
+	Error           string  `json:"error,omitempty"`
+}
+
+// Report is a record of a whole run: where it copied from and to, the outcome
+// of every table and the DDL run to create destination tables
+type Report struct {
+	Source       string        `json:"source"`
+	Destination  string        `json:"destination"`
+	TableResults []TableResult `json:"table_results"`
+	DDL          []string      `json:"ddl"`
+	Stats
+}
+
+// Report returns the record of everything the copier has done so far, with
+// the passwords in both connection strings masked
+func (c *Copier) Report() Report {
+	return Report{
+		Source:       RedactDSN(c.SourceDB),
+		Destination:  RedactDSN(c.DestDB),
+		TableResults: append([]TableResult{}, c.tableResults...),
+		DDL:          append([]string{}, c.ddl...),
+		Stats:        c.Stats(),
+	}
+}
+
+// RedactDSN masks the passwords of a connection string, both the one of the
+// user and those given as password or sslpassword query parameters. Paths and
+// connection strings without a password are returned unchanged.
+func RedactDSN(dsn string) string {
+	if !strings.Contains(dsn, "://") {
+		return dsn
+	}
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "(unparseable connection string)"
+	}
+	query := u.Query()
+	redacted := false
+	for _, key := range []string{"password", "sslpassword"} {
+		if query.Has(key) {
+			query.Set(key, "xxxxx")
+			redacted = true
+		}
+	}
+	if redacted {
+		u.RawQuery = query.Encode()
+	}
+	return u.Redacted()
+}
+
+// recordTable adds the outcome of a table copy to the report. Rows and
+// skipped rows are the growth of the copier's counters since the copy began.
+func (c *Copier) recordTable(start time.Time, rowsBefore, errorsBefore int64, skipped string, err error) {
+	result := TableResult{
+		Table:           c.destTableName(),
+		Rows:            c.rowsCopied.Load() - rowsBefore,
+		SkippedRows:     c.rowErrors.Load() - errorsBefore,
+		DurationSeconds: time.Since(start).Seconds(),
+		Skipped:         skipped,
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	c.tableResults = append(c.tableResults, result)
+}
+
+// recordDDL returns conn with a logger that also keeps every statement it runs
+// successfully, other than queries, for the report
+func (c *Copier) recordDDL(conn *gorm.DB) *gorm.DB {
+	return conn.Session(&gorm.Session{Logger: ddlLogger{Interface: conn.Logger, c: c}})
+}
+
+// ddlLogger passes everything on to the wrapped logger and appends the DDL
+// statements it traces to the copier's report
+type ddlLogger struct {
+	logger.Interface
+	c *Copier
+}
+
+func (l ddlLogger) LogMode(level logger.LogLevel) logger.Interface {
+	return ddlLogger{Interface: l.Interface.LogMode(level), c: l.c}
+}
+
+func (l ddlLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	l.Interface.Trace(ctx, begin, fc, err)
+	if err != nil {
+		return
+	}
+	statement, _ := fc()
+	statement = strings.TrimSpace(statement)
+	keyword, _, _ := strings.Cut(strings.ToUpper(statement), " ")
+	switch keyword {
+	case "SELECT", "PRAGMA", "WITH", "SAVEPOINT", "RELEASE", "":
+		return
+	}
+	l.c.ddl = append(l.c.ddl, statement)
+}
@@ -0,0 +1,28 @@
+package db
+
+import "fmt"
+
+// RowFilter is called with every source row, keyed by source column name,
+// before the rows are batched. It returns the row to copy, which may be the
+// same map modified or a new one, and false to skip the row. An error aborts
+// the copy.
+type RowFilter func(row map[string]interface{}) (map[string]interface{}, bool, error)
+
+// filterRows runs the RowFilter over records and returns the rows it keeps.
+// first is the number of the first record in the source, for errors.
+func (c *Copier) filterRows(records []map[string]interface{}, first int) ([]map[string]interface{}, error) {
+	if c.RowFilter == nil {
+		return records, nil
+	}
+	kept := records[:0]
+	for i, record := range records {
+		row, keep, err := c.RowFilter(record)
+		if err != nil {
+			return nil, fmt.Errorf("row filter failed on row %d of %s: %w", first+i, c.TableName, err)
+		}
+		if keep && row != nil {
+			kept = append(kept, row)
+		}
+	}
+	return kept, nil
+}
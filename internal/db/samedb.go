@@ -0,0 +1,70 @@
+package db
+
+import (
+	"net/url"
+	"os"
+	"strings"
+)
+
+// sameDatabase reports whether the source and destination connection strings
+// name the same database, such as one SQLite file given twice. Copying between
+// two tables of one database is fine; only copying a table onto itself is not.
+func (c *Copier) sameDatabase() bool {
+	if c.sourceDBType != c.destDBType {
+		return false
+	}
+	switch c.sourceDBType {
+	case DBTypeSQLite:
+		if isSQLDump(c.SourceDB) {
+			return false
+		}
+		// A plain :memory: database is private, a shared one is named by its DSN
+		if isInMemorySQLite(c.SourceDB) || isInMemorySQLite(c.DestDB) {
+			return c.SourceDB == c.DestDB && strings.Contains(c.SourceDB, "cache=shared")
+		}
+		source, err := os.Stat(databaseFilePath(c.SourceDB))
+		if err != nil {
+			return false
+		}
+		dest, err := os.Stat(databaseFilePath(c.DestDB))
+		return err == nil && os.SameFile(source, dest)
+	case DBTypePostgres:
+		source, err := url.Parse(c.SourceDB)
+		if err != nil {
+			return false
+		}
+		dest, err := url.Parse(c.DestDB)
+		if err != nil {
+			return false
+		}
+		return postgresAddress(source) == postgresAddress(dest) && source.Path == dest.Path
+	}
+	return false
+}
+
+// postgresAddress returns the host and port of a PostgreSQL URL, with the
+// default port filled in
+func postgresAddress(u *url.URL) string {
+	port := u.Port()
+	if port == "" {
+		port = "5432"
+	}
+	return strings.ToLower(u.Hostname()) + ":" + port
+}
+
+// sameTable reports whether two table names of one database name the same
+// table. An unqualified name is in the default schema.
+func (c *Copier) sameTable(a, b string) bool {
+	defaultSchema := "public"
+	if c.sourceDBType == DBTypeSQLite {
+		defaultSchema = "main"
+	}
+	qualify := func(name string) string {
+		schema, table := splitSchema(name)
+		if schema == "" {
+			schema = defaultSchema
+		}
+		return strings.ToLower(schema + "." + table)
+	}
+	return qualify(a) == qualify(b)
+}
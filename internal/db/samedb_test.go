@@ -0,0 +1,66 @@
+package db
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newSameFileCopier returns a connected copier of the items table of one
+// SQLite file, of rows rows, into the same file
+func newSameFileCopier(t *testing.T, rows int) *Copier {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "same.db")
+	execSQLite(t, path, seedItems(rows)...)
+
+	c := NewCopier(path, path, "items", 10)
+	c.Output = io.Discard
+	if err := c.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestCopyBetweenTablesOfOneSQLiteFile(t *testing.T) {
+	const rows = 35
+	for _, tt := range []struct {
+		name, dest string
+		set        func(c *Copier)
+	}{
+		{"dest table", "items_copy", func(c *Copier) { c.DestTable = "items_copy" }},
+		{"dest suffix", "items_old", func(c *Copier) { c.DestSuffix = "_old" }},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newSameFileCopier(t, rows)
+			tt.set(c)
+			if !c.sameDatabase() {
+				t.Fatal("sameDatabase() = false for one file, want true")
+			}
+			if err := c.Copy(); err != nil {
+				t.Fatalf("Copy: %v", err)
+			}
+			if got := countRows(t, c.destConn, tt.dest); got != rows {
+				t.Errorf("%s has %d rows, want %d", tt.dest, got, rows)
+			}
+			if got := countRows(t, c.destConn, "items"); got != rows {
+				t.Errorf("source table has %d rows after the copy, want %d", got, rows)
+			}
+		})
+	}
+}
+
+func TestCopyTableOntoItselfIsRefused(t *testing.T) {
+	for _, dest := range []string{"", "items", "main.items", "ITEMS"} {
+		c := newSameFileCopier(t, 5)
+		c.DestTable = dest
+		err := c.Copy()
+		if err == nil || !strings.Contains(err.Error(), "source and destination are the same table") {
+			t.Errorf("Copy with dest table %q = %v, want the same table error", dest, err)
+		}
+		if got := countRows(t, c.destConn, "items"); got != 5 {
+			t.Errorf("dest table %q: items has %d rows after the refused copy, want 5", dest, got)
+		}
+	}
+}
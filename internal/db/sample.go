@@ -1,7 +1,10 @@
 package db
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"math/rand"
 	"time"
 
 	"gorm.io/driver/sqlite"
@@ -19,35 +22,93 @@ type SampleUser struct {
 	UpdatedAt time.Time `gorm:"not null"`
 }
 
-// CreateSampleData creates a sample users table with test data
-func CreateSampleData(dbPath string, recordCount int) error {
-	db, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{})
+// sampleBaseTime is the reference time for timestamps of seeded sample data
+var sampleBaseTime = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// DefaultSampleBatchSize is the number of sample rows inserted per statement
+// when SampleOptions.BatchSize is not set
+const DefaultSampleBatchSize = 100
+
+// SampleOptions configures CreateSampleData
+type SampleOptions struct {
+	Path      string    // SQLite database file to create or add to
+	Records   int       // number of users to generate
+	Seed      int64     // non-zero for reproducible data
+	BatchSize int       // rows per insert statement
+	Output    io.Writer // progress messages; nil discards them
+}
+
+// SampleDataResult reports what CreateSampleData created
+type SampleDataResult struct {
+	Rows     int
+	Duration time.Duration
+}
+
+// CreateSampleData creates a sample users table with test data. A non-zero seed
+// makes the generated data, including timestamps, reproducible across runs.
+// Cancelling ctx stops it between batches; the rows inserted so far are kept
+// and counted in the result.
+func CreateSampleData(ctx context.Context, opts SampleOptions) (SampleDataResult, error) {
+	start := time.Now()
+	var result SampleDataResult
+	out := opts.Output
+	if out == nil {
+		out = io.Discard
+	}
+	batchSize := opts.BatchSize
+	if batchSize < 1 {
+		batchSize = DefaultSampleBatchSize
+	}
+
+	db, err := gorm.Open(sqlite.Open(opts.Path), gormConfig())
+	if err != nil {
+		return result, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	sqlDB, err := db.DB()
 	if err != nil {
-		return fmt.Errorf("failed to connect to database: %w", err)
+		return result, fmt.Errorf("failed to connect to database: %w", err)
 	}
+	defer sqlDB.Close()
+	db = db.WithContext(ctx)
 
 	// Create the table
 	err = db.AutoMigrate(&SampleUser{})
 	if err != nil {
-		return fmt.Errorf("failed to create table: %w", err)
+		return result, fmt.Errorf("failed to create table: %w", err)
+	}
+	recordCount, seed := opts.Records, opts.Seed
+
+	var rng *rand.Rand
+	if seed != 0 {
+		rng = rand.New(rand.NewSource(seed))
 	}
 
 	// Generate sample users
 	users := make([]SampleUser, recordCount)
 	for i := 0; i < recordCount; i++ {
+		createdAt, updatedAt := time.Now(), time.Now()
+		if rng != nil {
+			// Spread timestamps over the year before the base time
+			createdAt = sampleBaseTime.Add(-time.Duration(rng.Int63n(int64(365 * 24 * time.Hour))))
+			updatedAt = createdAt.Add(time.Duration(rng.Int63n(int64(30 * 24 * time.Hour))))
+		}
+
 		users[i] = SampleUser{
 			Name:      fmt.Sprintf("User %d", i+1),
 			Email:     fmt.Sprintf("user%d@example.com", i+1),
 			Age:       20 + (i % 40), // Ages between 20 and 59
 			Active:    i%2 == 0,      // Alternating active status
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
+			CreatedAt: createdAt,
+			UpdatedAt: updatedAt,
 		}
 	}
 
 	// Insert the users in batches
-	batchSize := 100
 	for i := 0; i < len(users); i += batchSize {
+		if err := ctx.Err(); err != nil {
+			result.Duration = time.Since(start)
+			return result, fmt.Errorf("sample data creation interrupted after %d records: %w", result.Rows, err)
+		}
 		end := i + batchSize
 		if end > len(users) {
 			end = len(users)
@@ -55,12 +116,15 @@ func CreateSampleData(dbPath string, recordCount int) error {
 
 		batch := users[i:end]
 		if err := db.Create(&batch).Error; err != nil {
-			return fmt.Errorf("failed to insert batch: %w", err)
+			result.Duration = time.Since(start)
+			return result, fmt.Errorf("failed to insert batch: %w", err)
 		}
+		result.Rows += len(batch)
 
-		fmt.Printf("Inserted records %d-%d\n", i+1, end)
+		fmt.Fprintf(out, "Inserted records %d-%d\n", i+1, end)
 	}
 
-	fmt.Printf("Successfully created sample table 'sample_users' with %d records\n", recordCount)
-	return nil
+	result.Duration = time.Since(start)
+	fmt.Fprintf(out, "Successfully created sample table 'sample_users' with %d records\n", recordCount)
+	return result, nil
 }
@@ -0,0 +1,343 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Index represents a secondary index on a table
+type Index struct {
+	Name    string
+	Columns []string
+	Unique  bool
+	// Predicate is the WHERE clause of a partial index, without WHERE
+	Predicate string
+}
+
+// ForeignKey represents a foreign key constraint on a table
+type ForeignKey struct {
+	Name       string
+	Columns    []string
+	RefTable   string
+	RefColumns []string
+}
+
+// deferredForeignKey is a foreign key of a destination table that is added
+// after all tables are loaded, because it references a table copied later
+type deferredForeignKey struct {
+	Table string
+	ForeignKey
+}
+
+// UniqueConstraint represents a UNIQUE constraint over one or more columns
+type UniqueConstraint struct {
+	Name    string
+	Columns []string
+}
+
+// getSourceUniqueConstraints retrieves the UNIQUE constraints of a table from the source
+// database. A constraint over several columns is returned as a single constraint.
+func (c *Copier) getSourceUniqueConstraints(table string) ([]UniqueConstraint, error) {
+	if c.schemaFile != nil {
+		return c.schemaFile.UniqueConstraints, nil
+	}
+	var rows []struct {
+		Name       string
+		ColumnName string
+	}
+
+	switch c.sourceDBType {
+	case DBTypeSQLite:
+		// SQLite backs each UNIQUE constraint with an automatic index of origin 'u'
+		if err := c.sourceConn.Raw(`
+			SELECT il.name AS name, ii.name AS column_name
+			FROM pragma_index_list(?) il
+			JOIN pragma_index_info(il.name) ii
+			WHERE il.origin = 'u'
+			ORDER BY il.name, ii.seqno
+		`, table).Scan(&rows).Error; err != nil {
+			return nil, fmt.Errorf("failed to get unique constraints: %w", err)
+		}
+	case DBTypePostgres:
+		if err := c.sourceConn.Raw(`
+			SELECT con.conname AS name, a.attname AS column_name
+			FROM pg_constraint con
+			JOIN LATERAL unnest(con.conkey) WITH ORDINALITY AS k(attnum, ord) ON true
+			JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+			WHERE con.conrelid = ?::regclass AND con.contype = 'u'
+			ORDER BY con.conname, k.ord
+		`, table).Scan(&rows).Error; err != nil {
+			return nil, fmt.Errorf("failed to get unique constraints: %w", err)
+		}
+	}
+
+	var constraints []UniqueConstraint
+	for _, row := range rows {
+		if n := len(constraints); n > 0 && constraints[n-1].Name == row.Name {
+			constraints[n-1].Columns = append(constraints[n-1].Columns, row.ColumnName)
+			continue
+		}
+		constraints = append(constraints, UniqueConstraint{
+			Name:    row.Name,
+			Columns: []string{row.ColumnName},
+		})
+	}
+
+	// SQLite's generated autoindex names are not carried over
+	for i, uc := range constraints {
+		if strings.HasPrefix(uc.Name, "sqlite_autoindex_") {
+			constraints[i].Name = ""
+		}
+	}
+
+	return constraints, nil
+}
+
+// uniqueDefinition renders a table-level UNIQUE clause with its optional constraint name
+func uniqueDefinition(uc UniqueConstraint) string {
+	def := fmt.Sprintf("UNIQUE (%s)", strings.Join(uc.Columns, ", "))
+	if uc.Name != "" {
+		def = fmt.Sprintf("CONSTRAINT %s %s", uc.Name, def)
+	}
+	return def
+}
+
+// getSourceIndexes retrieves the secondary indexes of a table from the source database.
+// Indexes backing primary keys and constraints are not included.
+func (c *Copier) getSourceIndexes(table string) ([]Index, error) {
+	if c.schemaFile != nil {
+		return c.schemaFile.Indexes, nil
+	}
+	var rows []struct {
+		Name       string
+		IsUnique   bool
+		ColumnName string
+		Predicate  string
+	}
+
+	switch c.sourceDBType {
+	case DBTypeSQLite:
+		// SQLite keeps the predicate of a partial index only in its definition
+		if err := c.sourceConn.Raw(`
+			SELECT il.name AS name, il."unique" AS is_unique, ii.name AS column_name,
+			       CASE WHEN il.partial = 1 THEN (SELECT sql FROM sqlite_master WHERE type = 'index' AND name = il.name) ELSE '' END AS predicate
+			FROM pragma_index_list(?) il
+			JOIN pragma_index_info(il.name) ii
+			WHERE il.origin = 'c'
+			ORDER BY il.name, ii.seqno
+		`, table).Scan(&rows).Error; err != nil {
+			return nil, fmt.Errorf("failed to get indexes: %w", err)
+		}
+	case DBTypePostgres:
+		if err := c.sourceConn.Raw(`
+			SELECT ic.relname AS name, ix.indisunique AS is_unique, a.attname AS column_name,
+			       COALESCE(pg_get_expr(ix.indpred, ix.indrelid), '') AS predicate
+			FROM pg_index ix
+			JOIN pg_class ic ON ic.oid = ix.indexrelid
+			JOIN LATERAL unnest(ix.indkey) WITH ORDINALITY AS k(attnum, ord) ON true
+			JOIN pg_attribute a ON a.attrelid = ix.indrelid AND a.attnum = k.attnum
+			WHERE ix.indrelid = ?::regclass AND NOT ix.indisprimary
+			  AND NOT EXISTS (SELECT 1 FROM pg_constraint con WHERE con.conindid = ix.indexrelid)
+			ORDER BY ic.relname, k.ord
+		`, table).Scan(&rows).Error; err != nil {
+			return nil, fmt.Errorf("failed to get indexes: %w", err)
+		}
+	}
+
+	var indexes []Index
+	for _, row := range rows {
+		if n := len(indexes); n > 0 && indexes[n-1].Name == row.Name {
+			indexes[n-1].Columns = append(indexes[n-1].Columns, row.ColumnName)
+			continue
+		}
+		predicate := row.Predicate
+		if c.sourceDBType == DBTypeSQLite {
+			predicate = indexPredicate(predicate)
+		}
+		indexes = append(indexes, Index{
+			Name:      row.Name,
+			Columns:   []string{row.ColumnName},
+			Unique:    row.IsUnique,
+			Predicate: predicate,
+		})
+	}
+
+	return indexes, nil
+}
+
+// getSourceForeignKeys retrieves the foreign key constraints of a table from the source database
+func (c *Copier) getSourceForeignKeys(table string) ([]ForeignKey, error) {
+	if c.schemaFile != nil {
+		return c.schemaFile.ForeignKeys, nil
+	}
+	var rows []struct {
+		Name       string
+		RefSchema  string
+		RefTable   string
+		ColumnName string
+		RefColumn  string
+	}
+
+	switch c.sourceDBType {
+	case DBTypeSQLite:
+		// SQLite foreign keys are unnamed, so the numeric id groups the columns
+		if err := c.sourceConn.Raw(`
+			SELECT CAST(id AS TEXT) AS name, "table" AS ref_table, "from" AS column_name, COALESCE("to", '') AS ref_column
+			FROM pragma_foreign_key_list(?)
+			ORDER BY id, seq
+		`, table).Scan(&rows).Error; err != nil {
+			return nil, fmt.Errorf("failed to get foreign keys: %w", err)
+		}
+	case DBTypePostgres:
+		if err := c.sourceConn.Raw(`
+			SELECT con.conname AS name, ns.nspname AS ref_schema, cl.relname AS ref_table, a.attname AS column_name, af.attname AS ref_column
+			FROM pg_constraint con
+			JOIN pg_class cl ON cl.oid = con.confrelid
+			JOIN pg_namespace ns ON ns.oid = cl.relnamespace
+			JOIN LATERAL unnest(con.conkey, con.confkey) WITH ORDINALITY AS k(attnum, refnum, ord) ON true
+			JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+			JOIN pg_attribute af ON af.attrelid = con.confrelid AND af.attnum = k.refnum
+			WHERE con.conrelid = ?::regclass AND con.contype = 'f'
+			ORDER BY con.conname, k.ord
+		`, table).Scan(&rows).Error; err != nil {
+			return nil, fmt.Errorf("failed to get foreign keys: %w", err)
+		}
+	}
+
+	var foreignKeys []ForeignKey
+	for _, row := range rows {
+		// Qualified source tables reference their parents by qualified name too
+		if c.qualifiedTables() && row.RefSchema != "" {
+			row.RefTable = row.RefSchema + "." + row.RefTable
+		}
+		if n := len(foreignKeys); n > 0 && foreignKeys[n-1].Name == row.Name {
+			foreignKeys[n-1].Columns = append(foreignKeys[n-1].Columns, row.ColumnName)
+			foreignKeys[n-1].RefColumns = append(foreignKeys[n-1].RefColumns, row.RefColumn)
+			continue
+		}
+		foreignKeys = append(foreignKeys, ForeignKey{
+			Name:       row.Name,
+			Columns:    []string{row.ColumnName},
+			RefTable:   row.RefTable,
+			RefColumns: []string{row.RefColumn},
+		})
+	}
+
+	// SQLite leaves the referenced column empty when it is the parent's primary key
+	for i, fk := range foreignKeys {
+		if strings.Join(fk.RefColumns, "") == "" {
+			foreignKeys[i].RefColumns = nil
+		}
+	}
+
+	return foreignKeys, nil
+}
+
+// foreignKeyDefinition renders a table-level FOREIGN KEY clause
+func foreignKeyDefinition(fk ForeignKey) string {
+	def := fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s", strings.Join(fk.Columns, ", "), fk.RefTable)
+	if len(fk.RefColumns) > 0 {
+		def += fmt.Sprintf(" (%s)", strings.Join(fk.RefColumns, ", "))
+	}
+	return def
+}
+
+// createIndexes creates the given indexes on a destination table using conn
+func (c *Copier) createIndexes(conn *gorm.DB, table string, indexes []Index) error {
+	for _, idx := range indexes {
+		unique := ""
+		if idx.Unique {
+			unique = "UNIQUE "
+		}
+		tablespace := ""
+		if c.DestTablespace != "" && c.destDBType == DBTypePostgres && !c.destCockroach {
+			tablespace = " TABLESPACE " + c.DestTablespace
+		}
+		createIndexSQL := fmt.Sprintf("CREATE %sINDEX %s ON %s (%s)%s%s;",
+			unique,
+			c.objectName(idx.Name),
+			table,
+			strings.Join(c.quotedDestColumns(idx.Columns), ", "),
+			tablespace,
+			c.indexWhere(idx),
+		)
+		if err := conn.Exec(createIndexSQL).Error; err != nil {
+			return fmt.Errorf("failed to create index %s: %w", idx.Name, err)
+		}
+	}
+	return nil
+}
+
+// indexWhere returns the WHERE clause of a partial index for the destination.
+// DuckDB has no partial indexes, and PostgreSQL casts do not work elsewhere,
+// so in those cases the index is created over the whole table.
+func (c *Copier) indexWhere(idx Index) string {
+	if idx.Predicate == "" {
+		return ""
+	}
+	if c.destDBType == DBTypeDuckDB || (c.destDBType != DBTypePostgres && strings.Contains(idx.Predicate, "::")) {
+		zap.L().Warn("Creating partial index as a full index",
+			zap.String("index", idx.Name),
+			zap.String("predicate", idx.Predicate),
+		)
+		c.recordUnportable(UnportableIndex, idx.Name, indexStatement(c.TableName, idx), "created as a full index without its WHERE clause")
+		return ""
+	}
+	predicate := idx.Predicate
+	if columns, err := c.getSourceSchema(c.TableName); err == nil {
+		predicate = c.renameExpressionColumns(predicate, columns)
+	}
+	return " WHERE " + predicate
+}
+
+// indexPredicate extracts the predicate of a partial index from its CREATE
+// INDEX statement: everything after the WHERE outside of parentheses and quotes
+func indexPredicate(definition string) string {
+	depth := 0
+	for i := 0; i < len(definition); i++ {
+		switch ch := definition[i]; {
+		case ch == '\'' || ch == '"':
+			end := strings.IndexByte(definition[i+1:], ch)
+			if end < 0 {
+				return ""
+			}
+			i += end + 1
+		case ch == '(':
+			depth++
+		case ch == ')':
+			depth--
+		case depth == 0 && isKeywordAt(definition, i, "WHERE"):
+			return strings.TrimSpace(definition[i+len("WHERE"):])
+		}
+	}
+	return ""
+}
+
+// getNotNullColumns returns the columns of a source table declared NOT NULL,
+// read directly from the catalog
+func (c *Copier) getNotNullColumns(table string) (map[string]bool, error) {
+	var names []string
+	switch c.sourceDBType {
+	case DBTypeSQLite:
+		if err := c.sourceConn.Raw(`SELECT name FROM pragma_table_info(?) WHERE "notnull" = 1`, table).Scan(&names).Error; err != nil {
+			return nil, fmt.Errorf("failed to get NOT NULL columns: %w", err)
+		}
+	case DBTypePostgres:
+		if err := c.sourceConn.Raw(`
+			SELECT attname
+			FROM pg_attribute
+			WHERE attrelid = ?::regclass AND attnum > 0 AND NOT attisdropped AND attnotnull
+		`, table).Scan(&names).Error; err != nil {
+			return nil, fmt.Errorf("failed to get NOT NULL columns: %w", err)
+		}
+	}
+
+	notNull := make(map[string]bool)
+	for _, name := range names {
+		notNull[name] = true
+	}
+	return notNull, nil
+}
@@ -0,0 +1,58 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCopyCompositeUniqueConstraint(t *testing.T) {
+	c := newTestCopier(t, "seats", 100,
+		"CREATE TABLE seats (id INTEGER PRIMARY KEY, hall TEXT NOT NULL, seat INTEGER NOT NULL, UNIQUE (hall, seat))",
+		"INSERT INTO seats (id, hall, seat) VALUES (1, 'A', 1), (2, 'A', 2), (3, 'B', 1)",
+	)
+	source, err := c.getSourceUniqueConstraints("seats")
+	if err != nil {
+		t.Fatalf("getSourceUniqueConstraints: %v", err)
+	}
+	want := []UniqueConstraint{{Columns: []string{"hall", "seat"}}}
+	if !reflect.DeepEqual(source, want) {
+		t.Fatalf("source unique constraints = %+v, want %+v", source, want)
+	}
+
+	if err := c.Copy(); err != nil {
+		t.Fatalf("Copy: %v", err)
+	}
+
+	// Read the destination's constraints as if it were the source
+	dest := &Copier{sourceConn: c.destConn, sourceDBType: DBTypeSQLite}
+	got, err := dest.getSourceUniqueConstraints("seats")
+	if err != nil {
+		t.Fatalf("getSourceUniqueConstraints on the destination: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("destination unique constraints = %+v, want one composite constraint %+v", got, want)
+	}
+
+	// A repeated hall or seat alone is allowed, a repeated pair is not
+	if err := c.destConn.Exec("INSERT INTO seats (id, hall, seat) VALUES (4, 'B', 2)").Error; err != nil {
+		t.Errorf("insert of a new pair failed: %v", err)
+	}
+	if err := c.destConn.Exec("INSERT INTO seats (id, hall, seat) VALUES (5, 'A', 1)").Error; err == nil {
+		t.Error("insert of a repeated pair succeeded, want a unique violation")
+	}
+}
+
+func TestNotValidCheckOnSQLiteDestination(t *testing.T) {
+	c := newTestCopier(t, "items", 100, seedItems(3)...)
+	checks := c.portableChecks([]CheckConstraint{
+		{Name: "name_set", Expression: "name <> ''"},
+		{Name: "short_name", Expression: "length(name) < 5", NotValid: true},
+	})
+	if len(checks) != 1 || checks[0].Name != "name_set" {
+		t.Errorf("portableChecks() = %+v, want only name_set", checks)
+	}
+	unportable := c.Unportable()
+	if len(unportable) != 1 || unportable[0].Name != "short_name" || unportable[0].Definition != "CONSTRAINT short_name CHECK (length(name) < 5) NOT VALID" {
+		t.Errorf("Unportable() = %+v, want short_name as NOT VALID", unportable)
+	}
+}
@@ -0,0 +1,127 @@
+package db
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"go.uber.org/zap"
+)
+
+// schemaCache holds source table schemas read in earlier runs
+type schemaCache struct {
+	Tables map[string]cachedSchema `json:"tables"`
+}
+
+// cachedSchema is the schema of one source table together with the
+// fingerprint of the source definition it was read from
+type cachedSchema struct {
+	Fingerprint string   `json:"fingerprint"`
+	Target      string   `json:"target"`
+	Columns     []Column `json:"columns"`
+}
+
+// loadSchemaCache reads the cache file, treating a missing file as an empty cache
+func (c *Copier) loadSchemaCache() error {
+	if c.schemaCache != nil {
+		return nil
+	}
+	c.schemaCache = &schemaCache{Tables: make(map[string]cachedSchema)}
+
+	data, err := os.ReadFile(c.SchemaCache)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read schema cache: %w", err)
+	}
+	if err := json.Unmarshal(data, c.schemaCache); err != nil {
+		zap.L().Warn("Ignoring unreadable schema cache", zap.String("file", c.SchemaCache), zap.Error(err))
+		c.schemaCache = &schemaCache{Tables: make(map[string]cachedSchema)}
+	}
+	if c.schemaCache.Tables == nil {
+		c.schemaCache.Tables = make(map[string]cachedSchema)
+	}
+	return nil
+}
+
+// saveSchemaCache writes the cache file
+func (c *Copier) saveSchemaCache() error {
+	data, err := json.MarshalIndent(c.schemaCache, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode schema cache: %w", err)
+	}
+	if err := os.WriteFile(c.SchemaCache, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write schema cache: %w", err)
+	}
+	return nil
+}
+
+// schemaTarget identifies the settings that the cached column types depend on
+func (c *Copier) schemaTarget() string {
+	target := fmt.Sprintf("dest=%d,wkt=%t", c.destDBType, c.GeometryAsWKT)
+	if len(c.TypeRules) > 0 {
+		target += ",typemap=" + c.typeRulesKey()
+	}
+	return target
+}
+
+// tableFingerprint hashes the source definition of a table so that a cached
+// schema is invalidated when the table changes. It is a single cheap catalog
+// query instead of the several needed to read the schema.
+func (c *Copier) tableFingerprint(table string) (string, error) {
+	var definition string
+	switch c.sourceDBType {
+	case DBTypeSQLite:
+		if err := c.sourceConn.Raw("SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&definition).Error; err != nil {
+			return "", fmt.Errorf("failed to get table definition: %w", err)
+		}
+	case DBTypePostgres:
+		if err := c.sourceConn.Raw(`
+			SELECT string_agg(a.attname || ' ' || format_type(a.atttypid, a.atttypmod) || ' ' || a.attnotnull::text, ',' ORDER BY a.attnum)
+			       || ';' || COALESCE((SELECT pg_get_constraintdef(oid) FROM pg_constraint WHERE conrelid = ?::regclass AND contype = 'p'), '')
+			FROM pg_attribute a
+			WHERE a.attrelid = ?::regclass AND a.attnum > 0 AND NOT a.attisdropped
+		`, table, table).Scan(&definition).Error; err != nil {
+			return "", fmt.Errorf("failed to get table definition: %w", err)
+		}
+	}
+	sum := sha256.Sum256([]byte(definition))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// cachedSourceSchema returns the schema of a table from the cache when its
+// fingerprint still matches, and otherwise reads it with read and caches it
+func (c *Copier) cachedSourceSchema(table string, read func() ([]Column, error)) ([]Column, error) {
+	if err := c.loadSchemaCache(); err != nil {
+		return nil, err
+	}
+	fingerprint, err := c.tableFingerprint(table)
+	if err != nil {
+		return nil, err
+	}
+
+	if cached, ok := c.schemaCache.Tables[table]; ok {
+		if cached.Fingerprint == fingerprint && cached.Target == c.schemaTarget() {
+			return append([]Column(nil), cached.Columns...), nil
+		}
+		c.printf("Schema cache for table '%s' is stale, reading it again\n", table)
+	}
+
+	columns, err := read()
+	if err != nil {
+		return nil, err
+	}
+	c.schemaCache.Tables[table] = cachedSchema{
+		Fingerprint: fingerprint,
+		Target:      c.schemaTarget(),
+		Columns:     append([]Column(nil), columns...),
+	}
+	if err := c.saveSchemaCache(); err != nil {
+		return nil, err
+	}
+	return columns, nil
+}
@@ -0,0 +1,136 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// TableSchema is the structure of one table as written by ExportSchema. Column
+// types are named as the database in Dialect names them.
+type TableSchema struct {
+	Table             string             `json:"table"`
+	Dialect           string             `json:"dialect"`
+	Columns           []Column           `json:"columns"`
+	Indexes           []Index            `json:"indexes,omitempty"`
+	UniqueConstraints []UniqueConstraint `json:"unique_constraints,omitempty"`
+	ForeignKeys       []ForeignKey       `json:"foreign_keys,omitempty"`
+	CheckConstraints  []CheckConstraint  `json:"check_constraints,omitempty"`
+}
+
+// schemaDialects names the databases a schema can be exported from
+var schemaDialects = map[DBType]string{
+	DBTypeSQLite:   "sqlite",
+	DBTypePostgres: "postgres",
+}
+
+// ExportSchema reads the columns, indexes and constraints of the source table
+func (c *Copier) ExportSchema() (*TableSchema, error) {
+	dialect, ok := schemaDialects[c.sourceDBType]
+	if !ok {
+		return nil, fmt.Errorf("schemas can only be exported from SQLite and PostgreSQL sources")
+	}
+
+	// Read the columns as a copy to the same kind of database would, which
+	// keeps their source types
+	destType, destCockroach := c.destDBType, c.destCockroach
+	c.destDBType, c.destCockroach = c.sourceDBType, false
+	defer func() { c.destDBType, c.destCockroach = destType, destCockroach }()
+
+	schema := &TableSchema{Table: c.TableName, Dialect: dialect}
+	var err error
+	if schema.Columns, err = c.readSourceSchema(c.TableName); err != nil {
+		return nil, &ErrSchema{Table: c.TableName, Err: err}
+	}
+	if len(schema.Columns) == 0 {
+		return nil, &ErrSchema{Table: c.TableName, Err: fmt.Errorf("table not found in source database")}
+	}
+	if schema.Indexes, err = c.getSourceIndexes(c.TableName); err != nil {
+		return nil, &ErrSchema{Table: c.TableName, Err: err}
+	}
+	if schema.UniqueConstraints, err = c.getSourceUniqueConstraints(c.TableName); err != nil {
+		return nil, &ErrSchema{Table: c.TableName, Err: err}
+	}
+	if schema.ForeignKeys, err = c.getSourceForeignKeys(c.TableName); err != nil {
+		return nil, &ErrSchema{Table: c.TableName, Err: err}
+	}
+	if schema.CheckConstraints, err = c.getSourceCheckConstraints(c.TableName); err != nil {
+		return nil, &ErrSchema{Table: c.TableName, Err: err}
+	}
+	return schema, nil
+}
+
+// WriteSchemaFile writes a table schema to path as indented JSON
+func WriteSchemaFile(path string, schema *TableSchema) error {
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// ReadSchemaFile reads a table schema written by WriteSchemaFile
+func ReadSchemaFile(path string) (*TableSchema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	schema := &TableSchema{}
+	if err := json.Unmarshal(data, schema); err != nil {
+		return nil, fmt.Errorf("invalid schema file %s: %w", path, err)
+	}
+	if schema.Table == "" || len(schema.Columns) == 0 {
+		return nil, fmt.Errorf("invalid schema file %s: no table or columns", path)
+	}
+	return schema, nil
+}
+
+// ApplySchema creates the destination table described by schema, converting
+// its column types from the schema's dialect. The table is named by DestTable,
+// or by the schema; an existing table is left as it is.
+func (c *Copier) ApplySchema(schema *TableSchema) error {
+	dialect, found := DBType(0), false
+	for dbType, name := range schemaDialects {
+		if name == schema.Dialect {
+			dialect, found = dbType, true
+		}
+	}
+	if !found {
+		return fmt.Errorf("unknown schema dialect %q", schema.Dialect)
+	}
+
+	c.TableName = schema.Table
+	c.sourceDBType = dialect
+	c.schemaFile = schema
+	defer func() { c.schemaFile = nil }()
+
+	if c.destConn.Migrator().HasTable(c.destTableName()) {
+		c.printf("Table '%s' already exists\n", c.destTableName())
+		return nil
+	}
+	if err := c.ensureTableExists(); err != nil {
+		return &ErrSchema{Table: c.destTableName(), Err: err}
+	}
+	return nil
+}
+
+// schemaFileColumns returns the columns of the applied schema file, typed for
+// the destination
+func (c *Copier) schemaFileColumns() []Column {
+	columns := make([]Column, len(c.schemaFile.Columns))
+	for i, col := range c.schemaFile.Columns {
+		switch {
+		case col.EnumType != "":
+			col.Type = "TEXT"
+			if c.destDBType == DBTypePostgres {
+				col.Type = col.EnumType
+			}
+		case col.IsSpatial:
+			col.Type = c.spatialType(col.Type)
+		default:
+			col.Type = c.convertDataType(col.Type, c.sourceDBType, c.destDBType)
+		}
+		columns[i] = col
+	}
+	return columns
+}
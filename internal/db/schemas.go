@@ -0,0 +1,80 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// AllSchemas as a source schema selects every user schema of a PostgreSQL source
+const AllSchemas = "all"
+
+// errSourceSchemas rejects SourceSchemas on sources without schemas
+var errSourceSchemas = errors.New("source schemas can only be selected on a PostgreSQL source")
+
+// splitSchema splits a table name into its schema, empty for an unqualified
+// name, and the table itself
+func splitSchema(name string) (string, string) {
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		return name[:i], name[i+1:]
+	}
+	return "", name
+}
+
+// qualifiedTables reports whether source tables are named schema.table, which
+// is the case when SourceSchemas selects the schemas to copy
+func (c *Copier) qualifiedTables() bool {
+	return len(c.SourceSchemas) > 0
+}
+
+// listSchemaTables returns the tables of the SourceSchemas as schema.table
+func (c *Copier) listSchemaTables() ([]string, error) {
+	if c.sourceDBType != DBTypePostgres {
+		return nil, errSourceSchemas
+	}
+
+	query := "SELECT table_schema || '.' || table_name FROM information_schema.tables WHERE table_type = 'BASE TABLE'"
+	var args []interface{}
+	if len(c.SourceSchemas) == 1 && c.SourceSchemas[0] == AllSchemas {
+		query += " AND table_schema NOT IN ('pg_catalog', 'information_schema') AND table_schema NOT LIKE 'pg\\_%'"
+	} else {
+		query += " AND table_schema IN ?"
+		args = append(args, c.SourceSchemas)
+	}
+	query += " ORDER BY 1"
+
+	var tables []string
+	if err := c.sourceConn.Raw(query, args...).Scan(&tables).Error; err != nil {
+		return nil, fmt.Errorf("failed to list source tables: %w", err)
+	}
+	return tables, nil
+}
+
+// qualifyDestName places a destination table in the schema its source table
+// came from, renamed by SchemaMap. SQLite has no schemas, so there the schema
+// becomes a prefix of the table name instead.
+func (c *Copier) qualifyDestName(schema, table string) string {
+	if mapped, ok := c.SchemaMap[schema]; ok {
+		schema = mapped
+	}
+	if c.destDBType == DBTypeSQLite {
+		c.noticeOnce("sqlite-schemas", "SQLite has no schemas; destination tables are named schema_table instead")
+		return schema + "_" + table
+	}
+	return schema + "." + table
+}
+
+// createDestSchema creates the schema of the destination table using conn
+// when it does not exist yet
+func (c *Copier) createDestSchema(conn *gorm.DB) error {
+	schema, _ := splitSchema(c.destTableName())
+	if schema == "" || c.destDBType == DBTypeSQLite {
+		return nil
+	}
+	if err := conn.Exec(fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s;", schema)).Error; err != nil {
+		return fmt.Errorf("failed to create schema %s: %w", schema, err)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package db
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Sequence represents a sequence owned by a table column, as created for
+// serial and identity columns in PostgreSQL
+type Sequence struct {
+	Name      string
+	Column    string
+	DataType  string
+	Start     int64
+	Increment int64
+	MinValue  int64
+	MaxValue  int64
+	Cache     int64
+	Cycle     bool
+}
+
+// getOwnedSequences retrieves the sequences owned by the columns of a PostgreSQL
+// table. Sequences outside the search path are named with their schema.
+func getOwnedSequences(conn *gorm.DB, table string) ([]Sequence, error) {
+	var sequences []Sequence
+	if err := conn.Raw(`
+		SELECT s.oid::regclass::text AS name, a.attname AS "column", format_type(seq.seqtypid, NULL) AS data_type,
+		       seq.seqstart AS start, seq.seqincrement AS increment, seq.seqmin AS min_value,
+		       seq.seqmax AS max_value, seq.seqcache AS cache, seq.seqcycle AS cycle
+		FROM pg_class s
+		JOIN pg_sequence seq ON seq.seqrelid = s.oid
+		JOIN pg_depend d ON d.objid = s.oid
+		  AND d.classid = 'pg_class'::regclass AND d.refclassid = 'pg_class'::regclass
+		JOIN pg_attribute a ON a.attrelid = d.refobjid AND a.attnum = d.refobjsubid
+		WHERE s.relkind = 'S' AND d.refobjid = ?::regclass
+		ORDER BY a.attnum
+	`, table).Scan(&sequences).Error; err != nil {
+		return nil, fmt.Errorf("failed to get owned sequences: %w", err)
+	}
+	return sequences, nil
+}
+
+// sequencesSupported reports whether sequence objects can be copied between the databases
+func (c *Copier) sequencesSupported() bool {
+	if c.destCockroach {
+		c.noticeOnce("sequences", "--copy-sequences is not supported for CockroachDB destinations and will be ignored")
+		return false
+	}
+	return c.sourceDBType == DBTypePostgres && c.destDBType == DBTypePostgres
+}
+
+// createSequences recreates the source table's owned sequences on the destination
+// using conn and attaches them to their columns as defaults
+func (c *Copier) createSequences(conn *gorm.DB) error {
+	if !c.sequencesSupported() || c.Query != "" {
+		return nil
+	}
+
+	sequences, err := getOwnedSequences(c.sourceConn, c.TableName)
+	if err != nil {
+		return err
+	}
+
+	for _, seq := range sequences {
+		seq.Name = c.objectName(seq.Name)
+		cycle := "NO CYCLE"
+		if seq.Cycle {
+			cycle = "CYCLE"
+		}
+		createSequenceSQL := fmt.Sprintf(
+			"CREATE SEQUENCE IF NOT EXISTS %s AS %s INCREMENT BY %d MINVALUE %d MAXVALUE %d START WITH %d CACHE %d %s OWNED BY %s.%s;",
+			seq.Name, seq.DataType, seq.Increment, seq.MinValue, seq.MaxValue, seq.Start, seq.Cache, cycle,
+			c.destTableName(), quoteIdent(c.destColumn(seq.Column)),
+		)
+		if err := conn.Exec(createSequenceSQL).Error; err != nil {
+			return fmt.Errorf("failed to create sequence %s: %w", seq.Name, err)
+		}
+
+		setDefaultSQL := fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET DEFAULT nextval('%s');",
+			c.destTableName(), quoteIdent(c.destColumn(seq.Column)), seq.Name)
+		if err := conn.Exec(setDefaultSQL).Error; err != nil {
+			return fmt.Errorf("failed to set default for column %s: %w", seq.Column, err)
+		}
+
+		c.printf("Created sequence '%s' for column '%s'\n", seq.Name, seq.Column)
+	}
+
+	return nil
+}
+
+// syncSequences advances the destination table's owned sequences past the copied values
+func (c *Copier) syncSequences() error {
+	if !c.sequencesSupported() {
+		return nil
+	}
+
+	sequences, err := getOwnedSequences(c.destConn, c.destTableName())
+	if err != nil {
+		return err
+	}
+
+	for _, seq := range sequences {
+		syncSQL := fmt.Sprintf("SELECT setval('%s', COALESCE(MAX(%s), %d), MAX(%s) IS NOT NULL) FROM %s;",
+			seq.Name, quoteIdent(seq.Column), seq.Start, quoteIdent(seq.Column), c.destTableName())
+		if err := c.destConn.Exec(syncSQL).Error; err != nil {
+			return fmt.Errorf("failed to sync sequence %s: %w", seq.Name, err)
+		}
+	}
+
+	return nil
+}
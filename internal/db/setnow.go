@@ -0,0 +1,42 @@
+package db
+
+import "time"
+
+// sqliteTimeFormat is the layout the SQLite driver stores time.Time values in,
+// so refreshed timestamps sort and parse like the copied ones
+const sqliteTimeFormat = "2006-01-02 15:04:05.999999999-07:00"
+
+// setNowValues returns the value that replaces each SetNow column of the
+// current table, all set to the same copy time. destColumns are the columns of
+// the destination table, which may include columns the source lacks. Other
+// columns are skipped, so a single list can be used for a whole-database copy.
+func (c *Copier) setNowValues(destColumns map[string]bool) map[string]interface{} {
+	if len(c.SetNow) == 0 {
+		return nil
+	}
+
+	var now interface{} = time.Now()
+	if c.destDBType == DBTypeSQLite {
+		now = time.Now().Format(sqliteTimeFormat)
+	}
+
+	values := make(map[string]interface{})
+	for _, name := range c.SetNow {
+		if destColumns[name] {
+			values[name] = now
+		}
+	}
+	return values
+}
+
+// applySetNow overwrites the SetNow columns of each record in a batch
+func applySetNow(batch []map[string]interface{}, values map[string]interface{}) {
+	if len(values) == 0 {
+		return
+	}
+	for _, record := range batch {
+		for name, value := range values {
+			record[name] = value
+		}
+	}
+}
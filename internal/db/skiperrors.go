@@ -0,0 +1,80 @@
+package db
+
+import (
+	"go.uber.org/zap"
+)
+
+// writeBatch writes a batch through w and returns the number of rows stored.
+// With SkipErrors a failed batch is retried one row at a time, and rows the
+// destination still rejects are logged and left out. Inside a transaction the
+// retries are wrapped in savepoints, so a rejected row does not abort it.
+func (c *Copier) writeBatch(w *tableWriter, batch []map[string]interface{}) (int, error) {
+	if !c.SkipErrors {
+		if c.OnUniqueViolation == UniqueViolationSkipTable {
+			return c.writeBatchOrSkipTable(w, batch)
+		}
+		return len(batch), w.WriteBatch(batch)
+	}
+
+	if err := w.savepoint("db_copy_batch"); err != nil {
+		return 0, err
+	}
+	err := w.WriteBatch(batch)
+	if err == nil {
+		return len(batch), w.release("db_copy_batch")
+	}
+	if err := w.rollbackTo("db_copy_batch"); err != nil {
+		return 0, err
+	}
+
+	written := 0
+	for i, row := range batch {
+		if err := w.savepoint("db_copy_row"); err != nil {
+			return written, err
+		}
+		rowErr := w.WriteBatch(batch[i : i+1])
+		if rowErr == nil {
+			written++
+			if err := w.release("db_copy_row"); err != nil {
+				return written, err
+			}
+			continue
+		}
+		if err := w.rollbackTo("db_copy_row"); err != nil {
+			return written, err
+		}
+
+		failed := int(c.rowErrors.Add(1))
+		zap.L().Warn("Skipping row that failed to insert",
+			zap.String("table", w.table),
+			zap.Any("row", row),
+			zap.Error(rowErr))
+		if c.MaxErrors > 0 && failed >= c.MaxErrors {
+			return written, &ErrTooManyErrors{Table: w.table, Errors: failed, Err: rowErr}
+		}
+	}
+	return written, nil
+}
+
+// savepoint, release and rollbackTo manage a savepoint when the writer inserts
+// inside a transaction, and do nothing when every statement autocommits
+func (w *tableWriter) savepoint(name string) error {
+	if !w.tx {
+		return nil
+	}
+	return w.conn.SavePoint(name).Error
+}
+
+func (w *tableWriter) release(name string) error {
+	if !w.tx {
+		return nil
+	}
+	return w.conn.Exec("RELEASE SAVEPOINT " + name).Error
+}
+
+func (w *tableWriter) rollbackTo(name string) error {
+	if !w.tx {
+		return nil
+	}
+	return w.conn.RollbackTo(name).Error
+}
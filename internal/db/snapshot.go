@@ -0,0 +1,65 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// Read consistency levels for reading several tables from one snapshot
+const (
+	ReadConsistencyRepeatableRead = "repeatable-read"
+	ReadConsistencySerializable   = "serializable"
+)
+
+// withSnapshot runs fn with the source connection bound to a single read-only
+// transaction at the ReadConsistency isolation level, so every table is read
+// from the same snapshot of the source database and writes committed during
+// the copy are not seen. Without ReadConsistency, or inside a snapshot that is
+// already open, fn runs as is.
+func (c *Copier) withSnapshot(fn func() error) error {
+	if c.ReadConsistency == "" || c.inSnapshot {
+		return fn()
+	}
+	if c.sourceDBType != DBTypePostgres {
+		return errors.New("--read-consistency is only supported for PostgreSQL sources")
+	}
+
+	var level sql.IsolationLevel
+	switch c.ReadConsistency {
+	case ReadConsistencyRepeatableRead:
+		level = sql.LevelRepeatableRead
+	case ReadConsistencySerializable:
+		level = sql.LevelSerializable
+	default:
+		return fmt.Errorf("unknown read consistency %q", c.ReadConsistency)
+	}
+
+	source := c.sourceConn
+	tx := source.Begin(&sql.TxOptions{Isolation: level, ReadOnly: true})
+	if tx.Error != nil {
+		return fmt.Errorf("failed to start source snapshot: %w", tx.Error)
+	}
+	// A deferrable serializable read waits for a snapshot that cannot cause
+	// serialization failures instead of risking one late in a long copy
+	if level == sql.LevelSerializable {
+		if err := tx.Exec("SET TRANSACTION DEFERRABLE").Error; err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to start source snapshot: %w", err)
+		}
+	}
+
+	c.sourceConn, c.inSnapshot = tx, true
+	defer func() {
+		c.sourceConn, c.inSnapshot = source, false
+	}()
+
+	if err := fn(); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return fmt.Errorf("failed to end source snapshot: %w", err)
+	}
+	return nil
+}
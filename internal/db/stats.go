@@ -0,0 +1,22 @@
+package db
+
+// Stats summarises what a copier has done so far
+type Stats struct {
+	Tables        []string       `json:"tables"`
+	CreatedTables []string       `json:"created_tables"`
+	RowsCopied    int64          `json:"rows_copied"`
+	Verified      []SampleResult `json:"verified,omitempty"`
+	RowErrors     int64          `json:"row_errors,omitempty"`
+}
+
+// Stats returns the destination tables copied and created so far and the
+// number of rows inserted into them
+func (c *Copier) Stats() Stats {
+	return Stats{
+		Tables:        append([]string{}, c.copiedTables...),
+		CreatedTables: append([]string{}, c.createdTables...),
+		RowsCopied:    c.rowsCopied.Load(),
+		Verified:      append([]SampleResult(nil), c.sampleResults...),
+		RowErrors:     c.rowErrors.Load(),
+	}
+}
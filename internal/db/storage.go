@@ -0,0 +1,17 @@
+package db
+
+import "fmt"
+
+// getSourceStorageParams returns the storage parameters of a PostgreSQL source
+// table, such as fillfactor=70 or autovacuum_vacuum_scale_factor=0.05, as they
+// appear in pg_class.reloptions. Other sources have none.
+func (c *Copier) getSourceStorageParams(tableName string) ([]string, error) {
+	if c.sourceDBType != DBTypePostgres || c.schemaFile != nil {
+		return nil, nil
+	}
+	var params []string
+	if err := c.sourceConn.Raw("SELECT unnest(reloptions) FROM pg_class WHERE oid = ?::regclass", tableName).Scan(&params).Error; err != nil {
+		return nil, fmt.Errorf("failed to get storage parameters: %w", err)
+	}
+	return params, nil
+}
@@ -0,0 +1,144 @@
+package db
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// swapSuffix is appended to the names of the staging table and its indexes
+// during an atomic swap
+const swapSuffix = "_new"
+
+// objectName returns the destination name of an index, constraint or sequence,
+// including the staging suffix while an atomic swap is loading
+func (c *Copier) objectName(name string) string {
+	return c.destName(name) + c.stagingSuffix
+}
+
+// copyWithSwap loads the current table into a fresh staging table and, once
+// the load has succeeded, replaces the live table with it in one transaction.
+// A failed load leaves the live table untouched.
+func (c *Copier) copyWithSwap() error {
+	live := c.destTableName()
+	staging := live + swapSuffix
+
+	savedDestTable, savedPolicy := c.DestTable, c.OnMissingTable
+	c.DestTable = staging
+	c.OnMissingTable = OnMissingTableCreate
+	c.stagingSuffix = swapSuffix
+	defer func() {
+		c.DestTable, c.OnMissingTable = savedDestTable, savedPolicy
+		c.stagingSuffix = ""
+	}()
+
+	// Leftovers from an earlier failed swap would otherwise receive the rows
+	if c.destConn.Migrator().HasTable(staging) {
+		c.printf("Dropping leftover staging table '%s'\n", staging)
+		if err := c.destConn.Migrator().DropTable(staging); err != nil {
+			return fmt.Errorf("failed to drop staging table %s: %w", staging, err)
+		}
+	}
+
+	if err := c.Copy(); err != nil {
+		return fmt.Errorf("%w (live table %s left unchanged, staging table %s kept for inspection)", err, live, staging)
+	}
+
+	c.stagingSuffix = ""
+	if err := c.swapTables(staging, live); err != nil {
+		return &ErrSchema{Table: live, Err: err}
+	}
+	for _, tables := range [][]string{c.createdTables, c.copiedTables} {
+		if n := len(tables); n > 0 && tables[n-1] == staging {
+			tables[n-1] = live
+		}
+	}
+	c.printf("Swapped staging table '%s' into '%s'\n", staging, live)
+	return nil
+}
+
+// swapTables replaces live with staging and gives the staging table's indexes,
+// named unique constraints and sequences their final names
+func (c *Copier) swapTables(staging, live string) error {
+	var indexes []Index
+	var uniques []UniqueConstraint
+	var sequences []Sequence
+	if c.Query == "" {
+		var err error
+		if indexes, err = c.getSourceIndexes(c.TableName); err != nil {
+			return err
+		}
+		indexes = c.selectedIndexes(indexes)
+		if uniques, err = c.getSourceUniqueConstraints(c.TableName); err != nil {
+			return err
+		}
+		if c.CopySequences && c.sequencesSupported() {
+			if sequences, err = getOwnedSequences(c.sourceConn, c.TableName); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Indexes live in the schema of their table
+	schema, _ := splitSchema(live)
+	inTableSchema := func(name string) string {
+		if s, _ := splitSchema(name); s == "" && schema != "" {
+			return schema + "." + name
+		}
+		return name
+	}
+
+	return c.destConn.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s;", quoteQualified(live))).Error; err != nil {
+			return fmt.Errorf("failed to drop live table: %w", err)
+		}
+		if err := tx.Exec(fmt.Sprintf("ALTER TABLE %s RENAME TO %s;", quoteQualified(staging), renameTarget(live))).Error; err != nil {
+			return fmt.Errorf("failed to rename staging table: %w", err)
+		}
+
+		// SQLite and DuckDB cannot rename indexes, so they are recreated
+		if c.destDBType != DBTypePostgres {
+			for _, idx := range indexes {
+				if err := tx.Exec(fmt.Sprintf("DROP INDEX %s;", quoteQualified(inTableSchema(c.destName(idx.Name)+swapSuffix)))).Error; err != nil {
+					return fmt.Errorf("failed to drop staging index %s: %w", idx.Name, err)
+				}
+			}
+			return c.createIndexes(tx, live, indexes)
+		}
+
+		var renames []string
+		for _, idx := range indexes {
+			renames = append(renames, fmt.Sprintf("ALTER INDEX %s RENAME TO %s;", quoteQualified(inTableSchema(c.destName(idx.Name)+swapSuffix)), renameTarget(c.destName(idx.Name))))
+		}
+		for _, uc := range uniques {
+			if uc.Name != "" && c.hasColumns(uc.Columns) {
+				renames = append(renames, fmt.Sprintf("ALTER TABLE %s RENAME CONSTRAINT %s TO %s;", quoteQualified(live), renameTarget(c.destName(uc.Name)+swapSuffix), renameTarget(c.destName(uc.Name))))
+			}
+		}
+		for _, seq := range sequences {
+			renames = append(renames, fmt.Sprintf("ALTER SEQUENCE %s RENAME TO %s;", quoteQualified(c.destName(seq.Name)+swapSuffix), renameTarget(c.destName(seq.Name))))
+		}
+		for _, rename := range renames {
+			if err := tx.Exec(rename).Error; err != nil {
+				return fmt.Errorf("failed to rename staging object: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// quoteQualified quotes each part of a possibly schema-qualified name
+func quoteQualified(name string) string {
+	schema, name := splitSchema(name)
+	if schema == "" {
+		return quoteIdent(name)
+	}
+	return quoteIdent(schema) + "." + quoteIdent(name)
+}
+
+// renameTarget returns the new name of a RENAME TO, which is unqualified: the
+// object stays in its schema, and PostgreSQL rejects a schema-qualified name.
+func renameTarget(name string) string {
+	_, name = splitSchema(name)
+	return quoteIdent(name)
+}
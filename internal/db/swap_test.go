@@ -0,0 +1,51 @@
+package db
+
+import (
+	"testing"
+)
+
+func TestAtomicSwap(t *testing.T) {
+	setup := append(seedItems(12), "CREATE INDEX idx_items_name ON items (name)")
+	c := newTestCopier(t, "items", 5, setup...)
+	c.AtomicSwap = true
+	if err := c.destConn.Exec("CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT)").Error; err != nil {
+		t.Fatalf("create live table: %v", err)
+	}
+	if err := c.destConn.Exec("INSERT INTO items (id, name) VALUES (100, 'old')").Error; err != nil {
+		t.Fatalf("fill live table: %v", err)
+	}
+
+	if err := c.Copy(); err != nil {
+		t.Fatalf("Copy: %v", err)
+	}
+	if got := countRows(t, c.destConn, "items"); got != 12 {
+		t.Errorf("live table has %d rows after the swap, want 12", got)
+	}
+	if c.destConn.Migrator().HasTable("items" + swapSuffix) {
+		t.Errorf("staging table items%s is left after the swap", swapSuffix)
+	}
+	var indexes []string
+	if err := c.destConn.Raw("SELECT name FROM sqlite_master WHERE type = 'index' AND name LIKE 'idx_items_name%'").Scan(&indexes).Error; err != nil {
+		t.Fatalf("read indexes: %v", err)
+	}
+	if len(indexes) != 1 || indexes[0] != "idx_items_name" {
+		t.Errorf("destination indexes = %v, want [idx_items_name]", indexes)
+	}
+}
+
+func TestRenameTarget(t *testing.T) {
+	tests := []struct{ name, qualified, target string }{
+		{"users", `"users"`, `"users"`},
+		{"sales.users", `"sales"."users"`, `"users"`},
+		{"Order", `"Order"`, `"Order"`},
+		{`we"ird`, `"we""ird"`, `"we""ird"`},
+	}
+	for _, tt := range tests {
+		if got := quoteQualified(tt.name); got != tt.qualified {
+			t.Errorf("quoteQualified(%q) = %s, want %s", tt.name, got, tt.qualified)
+		}
+		if got := renameTarget(tt.name); got != tt.target {
+			t.Errorf("renameTarget(%q) = %s, want %s", tt.name, got, tt.target)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// ListTables returns the names of all user tables in the source database
+func (c *Copier) ListTables() ([]string, error) {
+	if c.qualifiedTables() {
+		return c.listSchemaTables()
+	}
+	if c.sourceDBType == DBTypeBigQuery {
+		tables, err := c.bigQuery.tables(c.copyContext())
+		if err != nil {
+			return nil, fmt.Errorf("failed to list source tables: %w", err)
+		}
+		return tables, nil
+	}
+
+	tables, err := c.sourceConn.Migrator().GetTables()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list source tables: %w", err)
+	}
+
+	var userTables []string
+	for _, table := range tables {
+		// Skip SQLite internal tables such as sqlite_sequence
+		if c.sourceDBType == DBTypeSQLite && strings.HasPrefix(table, "sqlite_") {
+			continue
+		}
+		userTables = append(userTables, table)
+	}
+	sort.Strings(userTables)
+
+	return userTables, nil
+}
+
+// sortTablesByDependency orders tables so that tables referenced by foreign keys
+// come before the tables referencing them. When the remaining tables reference
+// each other in a cycle, the first of them in the original order is taken next
+// to break it; its foreign keys to tables copied later are added once all the
+// data has been loaded. The order is logged.
+func (c *Copier) sortTablesByDependency(tables []string) ([]string, error) {
+	inSet := make(map[string]bool)
+	for _, table := range tables {
+		inSet[table] = true
+	}
+
+	// Collect the parents of each table within the set
+	parents := make(map[string]map[string]bool)
+	for _, table := range tables {
+		foreignKeys, err := c.getSourceForeignKeys(table)
+		if err != nil {
+			return nil, err
+		}
+		parents[table] = make(map[string]bool)
+		for _, fk := range foreignKeys {
+			if inSet[fk.RefTable] && fk.RefTable != table {
+				parents[table][fk.RefTable] = true
+			}
+		}
+	}
+
+	var ordered, cycleBreaks []string
+	done := make(map[string]bool)
+	for len(ordered) < len(tables) {
+		progressed := false
+		for _, table := range tables {
+			if done[table] {
+				continue
+			}
+			ready := true
+			for parent := range parents[table] {
+				if !done[parent] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				ordered = append(ordered, table)
+				done[table] = true
+				progressed = true
+			}
+		}
+		if !progressed {
+			// Remaining tables reference each other
+			for _, table := range tables {
+				if !done[table] {
+					ordered = append(ordered, table)
+					cycleBreaks = append(cycleBreaks, table)
+					done[table] = true
+					break
+				}
+			}
+		}
+	}
+
+	c.tableOrder = make(map[string]int, len(ordered))
+	for i, table := range ordered {
+		c.tableOrder[table] = i
+	}
+	fields := []zap.Field{zap.Strings("order", ordered)}
+	if len(cycleBreaks) > 0 {
+		fields = append(fields, zap.Strings("foreign_key_cycles_broken_at", cycleBreaks))
+	}
+	zap.L().Info("Resolved table copy order from foreign keys", fields...)
+	return ordered, nil
+}
+
+// referencesLater reports whether the current table has a foreign key to a
+// table that is copied after it, which the destination may reject until that
+// table exists and holds its rows
+func (c *Copier) referencesLater(refTable string) bool {
+	pos, ok := c.tableOrder[c.TableName]
+	refPos, refOK := c.tableOrder[refTable]
+	return ok && refOK && refPos > pos
+}
+
+// addDeferredForeignKeys adds the foreign keys that were held back because
+// they reference tables copied later, now that every table holds its rows
+func (c *Copier) addDeferredForeignKeys() error {
+	for _, deferred := range c.deferredFKs {
+		if c.destDBType == DBTypeDuckDB {
+			zap.L().Warn("DuckDB cannot add a foreign key to an existing table; it was left out",
+				zap.String("table", deferred.Table),
+				zap.String("references", deferred.RefTable))
+			c.unportable = append(c.unportable, UnportableItem{
+				Table:      deferred.Table,
+				Kind:       UnportableForeignKey,
+				Name:       deferred.Name,
+				Definition: foreignKeyDefinition(deferred.ForeignKey),
+				Reason:     "DuckDB cannot add a foreign key to an existing table",
+			})
+			continue
+		}
+		alterSQL := fmt.Sprintf("ALTER TABLE %s ADD %s;", deferred.Table, foreignKeyDefinition(deferred.ForeignKey))
+		if err := c.recordDDL(c.destConn).Exec(alterSQL).Error; err != nil {
+			return fmt.Errorf("failed to add foreign key from %s to %s: %w", deferred.Table, deferred.RefTable, err)
+		}
+		c.printf("Added deferred foreign key from %s to %s\n", deferred.Table, deferred.RefTable)
+	}
+	c.deferredFKs = nil
+	return nil
+}
+
+// CopyAll copies every table in the source database in dependency order
+func (c *Copier) CopyAll() error {
+	tables, err := c.ListTables()
+	if err != nil {
+		return err
+	}
+	return c.CopyTables(tables)
+}
+
+// CopyTables copies the given source tables in dependency order
+func (c *Copier) CopyTables(tables []string) error {
+	if c.ReadConsistency != "" && !c.inSnapshot {
+		return c.withSnapshot(func() error {
+			return c.CopyTables(tables)
+		})
+	}
+	defer c.startHeartbeat()()
+
+	tables, err := c.sortTablesByDependency(tables)
+	if err != nil {
+		return fmt.Errorf("failed to resolve table dependencies: %w", err)
+	}
+	defer func() {
+		c.tableOrder = nil
+	}()
+
+	for _, table := range tables {
+		if c.SkipUnchanged {
+			unchanged, reason, err := c.tableUnchanged(table)
+			if err != nil {
+				return fmt.Errorf("failed to compare table %s: %w", table, err)
+			}
+			if unchanged {
+				c.tableResults = append(c.tableResults, TableResult{Table: c.destName(table), Skipped: "unchanged (" + reason + ")"})
+				c.printf("Skipping table '%s': unchanged (%s)\n", table, reason)
+				continue
+			}
+		}
+
+		c.TableName = table
+		if err := c.Copy(); err != nil {
+			return fmt.Errorf("failed to copy table %s: %w", table, err)
+		}
+	}
+	if err := c.addDeferredForeignKeys(); err != nil {
+		return err
+	}
+
+	c.printf("Processed %d tables, created %d tables in destination database\n", len(tables), len(c.createdTables))
+	if len(c.createdTables) > 0 {
+		c.printf("Created tables: %s\n", strings.Join(c.createdTables, ", "))
+	}
+	return nil
+}
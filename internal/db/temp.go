@@ -0,0 +1,92 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// pinnedConn is a single pooled connection used as a GORM connection pool.
+// It reports the pool it came from, so Close can still close that pool.
+type pinnedConn struct {
+	*sql.Conn
+	pool *sql.DB
+}
+
+func (p *pinnedConn) GetDBConn() (*sql.DB, error) {
+	return p.pool, nil
+}
+
+// DestConn returns the destination database connection, e.g. to query a
+// TempTable while the copier is still connected
+func (c *Copier) DestConn() *gorm.DB {
+	return c.destConn
+}
+
+// pinDestConn moves the destination onto one connection that is held until
+// Close. A temporary table only exists in the session that created it, so
+// every statement of the copy, and of the caller through DestConn, has to run
+// in that session rather than on any connection of the pool.
+func (c *Copier) pinDestConn() error {
+	if c.destDBType != DBTypePostgres && c.destDBType != DBTypeSQLite {
+		return nil
+	}
+	pool, err := c.destConn.DB()
+	if err != nil {
+		return err
+	}
+	conn, err := pool.Conn(context.Background())
+	if err != nil {
+		return err
+	}
+	pinned := &pinnedConn{Conn: conn, pool: pool}
+
+	var dialector gorm.Dialector = &sqlite.Dialector{Conn: pinned}
+	if c.destDBType == DBTypePostgres {
+		dialector = postgres.New(postgres.Config{Conn: pinned})
+	}
+	destConn, err := gorm.Open(dialector, gormConfig())
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	c.destConn = destConn
+	c.keepalive = append(c.keepalive, conn)
+	return nil
+}
+
+// checkTempTable verifies that the destination table can be created as a
+// temporary table
+func (c *Copier) checkTempTable() error {
+	if c.destDBType != DBTypePostgres && c.destDBType != DBTypeSQLite || c.destCockroach {
+		return errors.New("--dest-temp-table is only supported for PostgreSQL and SQLite destinations")
+	}
+	if c.Workers > 1 {
+		return errors.New("--dest-temp-table cannot be used with --workers, which insert through several sessions")
+	}
+	if c.AtomicSwap {
+		return errors.New("--dest-temp-table cannot be used with --atomic-swap")
+	}
+	if strings.Contains(c.destTableName(), ".") {
+		return errors.New("--dest-temp-table cannot create a table in a schema; temporary tables live in their own")
+	}
+	return nil
+}
+
+// tempTableExists reports whether the session already has the temporary
+// destination table. It hides a permanent table of the same name.
+func (c *Copier) tempTableExists() (bool, error) {
+	var exists bool
+	var err error
+	if c.destDBType == DBTypePostgres {
+		err = c.destConn.Raw("SELECT to_regclass(?) IS NOT NULL", "pg_temp."+c.destTableName()).Scan(&exists).Error
+	} else {
+		err = c.destConn.Raw("SELECT count(*) > 0 FROM sqlite_temp_master WHERE type = 'table' AND name = ?", c.destTableName()).Scan(&exists).Error
+	}
+	return exists, err
+}
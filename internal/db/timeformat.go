@@ -0,0 +1,98 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// timeFormatPresets are the named layouts accepted for TimeFormat. Fractional
+// seconds are accepted after the seconds of any layout.
+var timeFormatPresets = map[string]string{
+	"rfc3339":  time.RFC3339,
+	"iso8601":  "2006-01-02T15:04:05",
+	"datetime": "2006-01-02 15:04:05",
+	"date":     time.DateOnly,
+}
+
+// TimeLayout returns the Go time layout for a time format, which is either a
+// preset name (rfc3339, iso8601, datetime or date) or a layout such as
+// "02/01/2006 15:04"
+func TimeLayout(format string) string {
+	if layout, ok := timeFormatPresets[strings.ToLower(format)]; ok {
+		return layout
+	}
+	return format
+}
+
+// isTimeType reports whether a destination column type holds timestamps or dates
+func isTimeType(name string) bool {
+	return strings.HasPrefix(name, "TIMESTAMP") || name == "DATETIME" || name == "DATE"
+}
+
+// timeSelects returns the select list for reading an SQLite source table with
+// its timestamp columns as text, or nil when no TimeFormat is set. The driver
+// would otherwise fail on timestamps in a format it does not recognise.
+func (c *Copier) timeSelects(columns []Column) []string {
+	if c.TimeFormat == "" || c.sourceDBType != DBTypeSQLite {
+		return nil
+	}
+	var selects []string
+	converted := false
+	for _, col := range columns {
+		if isTimeType(parseType(col.Type).Name) {
+			selects = append(selects, fmt.Sprintf("CAST(%s AS TEXT) AS %s", quoteIdent(col.Name), quoteIdent(col.Name)))
+			converted = true
+			continue
+		}
+		selects = append(selects, quoteIdent(col.Name))
+	}
+	if !converted {
+		return nil
+	}
+	return selects
+}
+
+// parseTimes converts the text values of an SQLite source's timestamp columns
+// into times using TimeFormat. SQLite stores timestamps as text in whatever
+// format was written; the driver only recognises a few, and the rest would be
+// left for the destination to guess. A value that does not match is reported
+// with its row instead of being inserted.
+func (c *Copier) parseTimes(records []map[string]interface{}) error {
+	if c.TimeFormat == "" || c.sourceDBType != DBTypeSQLite || c.destConn == nil || len(records) == 0 {
+		return nil
+	}
+
+	columnTypes, err := c.destColumnTypes()
+	if err != nil {
+		return err
+	}
+	var names []string
+	for _, col := range columnTypes {
+		if isTimeType(parseType(col.DatabaseTypeName()).Name) {
+			names = append(names, col.Name())
+		}
+	}
+
+	layout := TimeLayout(c.TimeFormat)
+	for i, record := range records {
+		for _, name := range names {
+			var text string
+			switch value := record[name].(type) {
+			case string:
+				text = value
+			case []byte:
+				text = string(value)
+			default:
+				// NULLs and values the driver already parsed
+				continue
+			}
+			t, err := time.Parse(layout, strings.TrimSpace(text))
+			if err != nil {
+				return fmt.Errorf("row %d of %s, column %s: value %q does not match --time-format %q", i+1, c.destTableName(), name, text, c.TimeFormat)
+			}
+			record[name] = t
+		}
+	}
+	return nil
+}
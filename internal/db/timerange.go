@@ -0,0 +1,59 @@
+package db
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ParseLast parses the --last duration. Besides Go durations such as 12h it
+// accepts whole days and weeks, such as 30d or 2w.
+func ParseLast(value string) (time.Duration, error) {
+	var unit time.Duration
+	switch {
+	case strings.HasSuffix(value, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(value, "w"):
+		unit = 7 * 24 * time.Hour
+	}
+	if unit > 0 {
+		n, err := strconv.Atoi(value[:len(value)-1])
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid --last value %q: expected a positive whole number of days or weeks", value)
+		}
+		return time.Duration(n) * unit, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid --last value %q: expected a positive duration such as 30d, 12h or 90m", value)
+	}
+	return d, nil
+}
+
+// timeRange restricts a read of the source table to the rows whose TimeColumn
+// lies within Last of the current time, using the source's own clock. Tables
+// without that column are read in full.
+func (c *Copier) timeRange(query *gorm.DB, columns []Column) *gorm.DB {
+	if c.TimeColumn == "" {
+		return query
+	}
+	if !hasSourceColumn(columns, c.TimeColumn) {
+		c.printf("Table %s has no column %s; copying all of its rows\n", c.TableName, c.TimeColumn)
+		return query
+	}
+	return c.timeFilter(query)
+}
+
+// timeFilter adds the condition of timeRange to a query of a table that has
+// TimeColumn
+func (c *Copier) timeFilter(query *gorm.DB) *gorm.DB {
+	seconds := int64(c.Last / time.Second)
+	if c.sourceDBType == DBTypePostgres {
+		return query.Where(fmt.Sprintf("%s >= now() - interval '%d seconds'", quoteIdent(c.TimeColumn), seconds))
+	}
+	return query.Where(fmt.Sprintf("%s >= datetime('now', '-%d seconds')", quoteIdent(c.TimeColumn), seconds))
+}
@@ -0,0 +1,149 @@
+package db
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Trigger is a trigger of a source table with the statements that create it
+type Trigger struct {
+	Name       string
+	Definition string
+	// Function creates the trigger function of a PostgreSQL trigger; SQLite
+	// triggers carry their body in Definition
+	Function string
+}
+
+// getSourceTriggers retrieves the triggers of a table from the source database.
+// Internal PostgreSQL triggers, such as those enforcing foreign keys, are left out.
+func (c *Copier) getSourceTriggers(table string) ([]Trigger, error) {
+	var triggers []Trigger
+	switch c.sourceDBType {
+	case DBTypeSQLite:
+		if err := c.sourceConn.Raw(`
+			SELECT name, sql AS definition
+			FROM sqlite_master
+			WHERE type = 'trigger' AND tbl_name = ?
+			ORDER BY name
+		`, table).Scan(&triggers).Error; err != nil {
+			return nil, fmt.Errorf("failed to get triggers: %w", err)
+		}
+	case DBTypePostgres:
+		if err := c.sourceConn.Raw(`
+			SELECT t.tgname AS name, pg_get_triggerdef(t.oid) AS definition, pg_get_functiondef(t.tgfoid) AS function
+			FROM pg_trigger t
+			WHERE t.tgrelid = ?::regclass AND NOT t.tgisinternal
+			ORDER BY t.tgname
+		`, table).Scan(&triggers).Error; err != nil {
+			return nil, fmt.Errorf("failed to get triggers: %w", err)
+		}
+	}
+	return triggers, nil
+}
+
+// copyTriggers recreates the triggers of the source table on a destination
+// table created by this copy, after its rows are loaded so that they do not
+// fire for copied rows. Trigger bodies are written in the source dialect, so
+// they are only recreated between databases of the same kind and when the
+// table keeps its name. Other triggers are logged and written to DDLOut to be
+// ported by hand.
+func (c *Copier) copyTriggers() error {
+	if c.NoTriggers || c.Query != "" {
+		return nil
+	}
+	triggers, err := c.getSourceTriggers(c.TableName)
+	if err != nil {
+		return err
+	}
+
+	table := c.destTableName()
+	reason := ""
+	switch {
+	case c.sourceDBType != c.destDBType || c.destCockroach:
+		reason = "the destination is a different kind of database"
+	case c.TempTable:
+		reason = "the destination table is temporary"
+	case !c.sameTable(c.TableName, table):
+		reason = fmt.Sprintf("the table is renamed to %s", table)
+	}
+	for _, trigger := range triggers {
+		if reason != "" {
+			if err := c.skipTrigger(trigger, reason); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := c.createTrigger(trigger); err != nil {
+			if err := c.skipTrigger(trigger, err.Error()); err != nil {
+				return err
+			}
+			continue
+		}
+		c.printf("Created trigger %s on %s\n", trigger.Name, table)
+	}
+	return nil
+}
+
+// createTrigger runs the statements of a trigger on the destination
+func (c *Copier) createTrigger(trigger Trigger) error {
+	return c.recordDDL(c.destConn).Transaction(func(tx *gorm.DB) error {
+		if trigger.Function != "" {
+			if err := tx.Exec(trigger.Function).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Exec(trigger.Definition).Error
+	})
+}
+
+// skipTrigger warns about a trigger that was not recreated and adds its
+// statements to DDLOut and the manifest
+func (c *Copier) skipTrigger(trigger Trigger, reason string) error {
+	c.recordUnportable(UnportableTrigger, trigger.Name, triggerStatements(trigger), reason)
+	fields := []zap.Field{
+		zap.String("table", c.TableName),
+		zap.String("trigger", trigger.Name),
+		zap.String("reason", reason),
+	}
+	if c.DDLOut == "" {
+		zap.L().Warn("Trigger was not recreated; set --ddl-out to save its definition", fields...)
+		return nil
+	}
+	zap.L().Warn("Trigger was not recreated; its definition was written to the DDL file", append(fields, zap.String("ddl_out", c.DDLOut))...)
+
+	return c.writeDDLOut(fmt.Sprintf("-- Trigger %s on %s, not recreated: %s\n%s\n\n", trigger.Name, c.TableName, reason, triggerStatements(trigger)))
+}
+
+// triggerStatements returns the statements that create a trigger, each ended
+// with a semicolon
+func triggerStatements(trigger Trigger) string {
+	var b strings.Builder
+	if trigger.Function != "" {
+		b.WriteString(strings.TrimSpace(trigger.Function) + ";\n")
+	}
+	b.WriteString(strings.TrimSuffix(strings.TrimSpace(trigger.Definition), ";") + ";")
+	return b.String()
+}
+
+// writeDDLOut appends to the DDLOut file, replacing a file left by an earlier
+// run on the first write
+func (c *Copier) writeDDLOut(text string) error {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	if !c.ddlOutStarted {
+		flags |= os.O_TRUNC
+	}
+	f, err := os.OpenFile(c.DDLOut, flags, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to write DDL file: %w", err)
+	}
+	c.ddlOutStarted = true
+	if _, err := f.WriteString(text); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write DDL file: %w", err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,129 @@
+package db
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TypeRule maps source columns to a destination column type. Column matches a
+// column by name in every table, or as table.column in one; SourceType matches
+// the source type name of a column, case-insensitively, with * and ? as
+// wildcards, e.g. VARCHAR* or TINYINT(1). Each rule sets one of the two.
+type TypeRule struct {
+	Column     string `yaml:"column"`
+	SourceType string `yaml:"source_type"`
+	Type       string `yaml:"type"`
+}
+
+// ReadTypeMapFile reads TypeRules from a .yaml or .yml file holding a list of
+// rules with column or source_type and type keys, or from a CSV file with the
+// header row column,source_type,type
+func ReadTypeMapFile(filename string) ([]TypeRule, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []TypeRule
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &rules); err != nil {
+			return nil, fmt.Errorf("invalid type map file %s: %w", filename, err)
+		}
+	default:
+		if rules, err = readTypeMapCSV(bytes.NewReader(data)); err != nil {
+			return nil, fmt.Errorf("invalid type map file %s: %w", filename, err)
+		}
+	}
+
+	for i, rule := range rules {
+		switch {
+		case rule.Type == "":
+			return nil, fmt.Errorf("invalid type map file %s: rule %d has no type", filename, i+1)
+		case (rule.Column == "") == (rule.SourceType == ""):
+			return nil, fmt.Errorf("invalid type map file %s: rule %d must set exactly one of column and source_type", filename, i+1)
+		}
+		if _, err := path.Match(rule.SourceType, ""); err != nil {
+			return nil, fmt.Errorf("invalid type map file %s: rule %d: source_type %q: %w", filename, i+1, rule.SourceType, err)
+		}
+	}
+	return rules, nil
+}
+
+// readTypeMapCSV reads TypeRules from CSV with a column,source_type,type header
+func readTypeMapCSV(r io.Reader) ([]TypeRule, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	if strings.Join(header, ",") != "column,source_type,type" {
+		return nil, fmt.Errorf("expected the header row column,source_type,type, got %s", strings.Join(header, ","))
+	}
+
+	var rules []TypeRule
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return rules, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, TypeRule{Column: row[0], SourceType: row[1], Type: row[2]})
+	}
+}
+
+// columnTypeRule returns the type a Column rule of TypeRules gives a column of
+// the current table. A table.column rule wins over a rule for the bare name.
+func (c *Copier) columnTypeRule(column string) (string, bool) {
+	var bare string
+	found := false
+	for _, rule := range c.TypeRules {
+		switch rule.Column {
+		case c.TableName + "." + column:
+			return rule.Type, true
+		case column:
+			if !found {
+				bare, found = rule.Type, true
+			}
+		}
+	}
+	return bare, found
+}
+
+// sourceTypeRule returns the type the first matching SourceType rule of
+// TypeRules gives a source type
+func (c *Copier) sourceTypeRule(sourceType string) (string, bool) {
+	for _, rule := range c.TypeRules {
+		if rule.SourceType == "" {
+			continue
+		}
+		if ok, _ := path.Match(strings.ToUpper(rule.SourceType), strings.ToUpper(sourceType)); ok {
+			return rule.Type, true
+		}
+	}
+	return "", false
+}
+
+// typeRulesKey identifies TypeRules in the schema cache, whose column types
+// depend on them
+func (c *Copier) typeRulesKey() string {
+	h := sha256.New()
+	for _, rule := range c.TypeRules {
+		fmt.Fprintf(h, "%s\x00%s\x00%s\n", rule.Column, rule.SourceType, rule.Type)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
@@ -0,0 +1,69 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+// updatedAtColumn is the column whose maximum is compared by SkipUnchanged
+const updatedAtColumn = "updated_at"
+
+// tableUnchanged reports whether a source table appears to be identical to its
+// destination table: both exist with the same row count and, if both have an
+// updated_at column, the same latest updated_at. The reason describes the
+// comparison that was made.
+func (c *Copier) tableUnchanged(table string) (unchanged bool, reason string, err error) {
+	dest := c.destName(table)
+	if c.destConn == nil || !c.destConn.Migrator().HasTable(dest) {
+		return false, "", nil
+	}
+
+	var sourceRows, destRows int64
+	if err := c.sourceConn.Table(table).Count(&sourceRows).Error; err != nil {
+		return false, "", fmt.Errorf("failed to count rows of source table %s: %w", table, err)
+	}
+	if err := c.destConn.Table(dest).Count(&destRows).Error; err != nil {
+		return false, "", fmt.Errorf("failed to count rows of destination table %s: %w", dest, err)
+	}
+	if sourceRows != destRows {
+		return false, "", nil
+	}
+	reason = fmt.Sprintf("%d rows in both databases", sourceRows)
+
+	if !c.sourceConn.Migrator().HasColumn(table, updatedAtColumn) || !c.destConn.Migrator().HasColumn(dest, updatedAtColumn) {
+		return true, reason, nil
+	}
+	var sourceMax, destMax interface{}
+	if err := c.sourceConn.Table(table).Select("MAX(" + updatedAtColumn + ")").Row().Scan(&sourceMax); err != nil {
+		return false, "", fmt.Errorf("failed to read latest %s of source table %s: %w", updatedAtColumn, table, err)
+	}
+	if err := c.destConn.Table(dest).Select("MAX(" + updatedAtColumn + ")").Row().Scan(&destMax); err != nil {
+		return false, "", fmt.Errorf("failed to read latest %s of destination table %s: %w", updatedAtColumn, dest, err)
+	}
+	if timestampKey(sourceMax) != timestampKey(destMax) {
+		return false, "", nil
+	}
+	return true, reason + fmt.Sprintf(" and the same latest %s", updatedAtColumn), nil
+}
+
+// timestampKey normalizes a timestamp read from either database so that the
+// same instant compares equal whether it was stored as a time or as text
+func timestampKey(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return t.UTC().Format(time.RFC3339Nano)
+	case []byte:
+		return timestampKey(string(t))
+	case string:
+		for _, layout := range []string{sqliteTimeFormat, "2006-01-02 15:04:05.999999999", time.RFC3339Nano} {
+			if parsed, err := time.Parse(layout, t); err == nil {
+				return parsed.UTC().Format(time.RFC3339Nano)
+			}
+		}
+		return t
+	default:
+		return fmt.Sprint(t)
+	}
+}
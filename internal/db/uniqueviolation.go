@@ -0,0 +1,71 @@
+package db
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+)
+
+// Policies for a batch that violates a unique constraint of the destination
+const (
+	UniqueViolationError     = "error"
+	UniqueViolationSkipTable = "skip-table"
+)
+
+// ErrUniqueViolation is returned by writeBatch with UniqueViolationSkipTable
+// when a batch violated a unique constraint. The batch has been rolled back.
+type ErrUniqueViolation struct {
+	Table string
+	Err   error
+}
+
+func (e *ErrUniqueViolation) Error() string {
+	return "unique constraint violation in " + e.Table + ": " + e.Err.Error()
+}
+
+func (e *ErrUniqueViolation) Unwrap() error {
+	return e.Err
+}
+
+// isUniqueViolation reports whether an insert failed on a primary key or
+// unique constraint of the destination
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "23505"
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "unique constraint failed") || strings.Contains(msg, "duplicate key")
+}
+
+// writeBatchOrSkipTable writes a batch and, when it violates a unique
+// constraint, rolls back just that batch and returns an ErrUniqueViolation so
+// that the caller can keep the earlier batches and skip the rest of the table
+func (c *Copier) writeBatchOrSkipTable(w *tableWriter, batch []map[string]interface{}) (int, error) {
+	if err := w.savepoint("db_copy_batch"); err != nil {
+		return 0, err
+	}
+	err := w.WriteBatch(batch)
+	if err == nil {
+		return len(batch), w.release("db_copy_batch")
+	}
+	if !isUniqueViolation(err) {
+		return 0, err
+	}
+	if err := w.rollbackTo("db_copy_batch"); err != nil {
+		return 0, err
+	}
+	return 0, &ErrUniqueViolation{Table: w.table, Err: err}
+}
+
+// reportSkippedTable logs that the rest of a table was skipped after a
+// unique violation, with the number of rows left out
+func (c *Copier) reportSkippedTable(violation error, skipped int) {
+	zap.L().Warn("Skipped the rest of the table after a unique constraint violation",
+		zap.String("table", c.destTableName()),
+		zap.Int("rows_skipped", skipped),
+		zap.Error(violation))
+	c.printf("Skipped the remaining %d rows of %s after a unique constraint violation\n", skipped, c.destTableName())
+}
@@ -0,0 +1,157 @@
+package db
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// TableCount holds the number of rows of a table in both databases
+type TableCount struct {
+	Table      string `json:"table"`
+	DestTable  string `json:"dest_table"`
+	SourceRows int64  `json:"source_rows"`
+	DestRows   int64  `json:"dest_rows"`
+}
+
+// Match reports whether both databases hold the same number of rows
+func (tc TableCount) Match() bool {
+	return tc.SourceRows == tc.DestRows
+}
+
+// CountRows counts the rows of each source table and of its destination table
+func (c *Copier) CountRows(tables []string) ([]TableCount, error) {
+	if c.destConn == nil {
+		return nil, fmt.Errorf("row counts cannot be verified for this destination")
+	}
+	if c.sourceConn == nil {
+		return nil, fmt.Errorf("row counts cannot be verified for this source")
+	}
+	var counts []TableCount
+	for _, table := range tables {
+		tc := TableCount{Table: table, DestTable: c.destName(table)}
+		if err := c.sourceConn.Table(table).Count(&tc.SourceRows).Error; err != nil {
+			return nil, fmt.Errorf("failed to count rows of source table %s: %w", table, err)
+		}
+		if err := c.destConn.Table(tc.DestTable).Count(&tc.DestRows).Error; err != nil {
+			return nil, fmt.Errorf("failed to count rows of destination table %s: %w", tc.DestTable, err)
+		}
+		counts = append(counts, tc)
+	}
+	return counts, nil
+}
+
+// VerifySample checks a random sample of copied rows after each table
+const VerifySample = "sample"
+
+// defaultVerifySampleSize is the number of rows checked when VerifySampleSize is not set
+const defaultVerifySampleSize = 100
+
+// SampleResult is the outcome of checking a random sample of copied rows
+type SampleResult struct {
+	Table          string   `json:"table"`
+	SampleSize     int      `json:"sample_size"`
+	Passed         int      `json:"passed"`
+	Failed         int      `json:"failed"`
+	MismatchedKeys []string `json:"mismatched_keys,omitempty"`
+}
+
+// verifySample picks random rows of the current source table and checks that
+// each exists in the destination table with the same column values. Columns
+// filled by SetNow and Defaults are not compared.
+func (c *Copier) verifySample(columns []Column) (SampleResult, error) {
+	result := SampleResult{Table: c.destTableName()}
+
+	primaryKeyColumn, err := c.getPrimaryKeyColumnName()
+	if err != nil {
+		return result, fmt.Errorf("failed to get primary key column name: %w", err)
+	}
+	if primaryKeyColumn == "" || !c.hasColumns([]string{primaryKeyColumn}) {
+		c.noticeOnce("verify:"+c.TableName, fmt.Sprintf("Sample verification of %s needs a primary key and was skipped", c.TableName))
+		return result, nil
+	}
+
+	size := c.VerifySampleSize
+	if size <= 0 {
+		size = defaultVerifySampleSize
+	}
+	var sourceRows []map[string]interface{}
+	if err := c.sourceConn.Table(c.TableName).Select(c.sourceSelects(columns)).Order("RANDOM()").Limit(size).Find(&sourceRows).Error; err != nil {
+		return result, fmt.Errorf("failed to sample source table: %w", err)
+	}
+	if len(sourceRows) == 0 {
+		return result, nil
+	}
+
+	keys := make([]interface{}, len(sourceRows))
+	for i, row := range sourceRows {
+		keys[i] = row[primaryKeyColumn]
+	}
+	destKey := c.destColumn(primaryKeyColumn)
+	var destRows []map[string]interface{}
+	if err := c.destConn.Table(c.destTableName()).Where(quoteIdent(destKey)+" IN ?", keys).Find(&destRows).Error; err != nil {
+		return result, fmt.Errorf("failed to read sampled rows from destination table: %w", err)
+	}
+	destByKey := make(map[string]map[string]interface{}, len(destRows))
+	for _, row := range destRows {
+		destByKey[sampleValue(row[destKey])] = row
+	}
+
+	skip := make(map[string]bool)
+	for _, name := range c.SetNow {
+		skip[name] = true
+	}
+	for name := range c.Defaults {
+		skip[name] = true
+	}
+
+	result.SampleSize = len(sourceRows)
+	for _, row := range sourceRows {
+		key := sampleValue(row[primaryKeyColumn])
+		mismatch := ""
+		if dest, ok := destByKey[key]; !ok {
+			mismatch = "missing"
+		} else {
+			for _, col := range columns {
+				name := c.destColumn(col.Name)
+				if skip[name] {
+					continue
+				}
+				if sampleValue(row[col.Name]) != sampleValue(dest[name]) {
+					mismatch = col.Name + " differs"
+					break
+				}
+			}
+		}
+		if mismatch == "" {
+			result.Passed++
+			continue
+		}
+		result.Failed++
+		result.MismatchedKeys = append(result.MismatchedKeys, key)
+		c.printf("  %s=%s: %s\n", primaryKeyColumn, key, mismatch)
+	}
+	return result, nil
+}
+
+// sampleValue renders a value read from either database so that equal values
+// compare equal across drivers, e.g. a SQLite 1 and a PostgreSQL true
+func sampleValue(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if t {
+			return "1"
+		}
+		return "0"
+	case float32:
+		return strconv.FormatFloat(float64(t), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(t, 'g', -1, 64)
+	case time.Time, string, []byte:
+		return timestampKey(t)
+	default:
+		return fmt.Sprint(t)
+	}
+}
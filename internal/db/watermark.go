@@ -0,0 +1,66 @@
+package db
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// destWatermark returns the highest value of a key column in the destination
+// table, or nil when the table is empty
+func (c *Copier) destWatermark(destColumn string) (interface{}, error) {
+	var watermark interface{}
+	row := c.destConn.Table(c.destTableName()).Select(fmt.Sprintf("MAX(%s)", quoteIdent(destColumn))).Row()
+	if err := row.Scan(&watermark); err != nil {
+		return nil, fmt.Errorf("failed to get the highest key of destination table %s: %w", c.destTableName(), err)
+	}
+	if b, ok := watermark.([]byte); ok {
+		watermark = string(b)
+	}
+	return watermark, nil
+}
+
+// startIncremental sets the watermark of an Incremental copy: the highest key
+// already in the destination. Only source rows above it are read, so a copy
+// that is repeated, as with the --watch loop, reads just the rows added
+// since. It assumes keys only grow; rows inserted below the watermark or
+// updated in place are not picked up.
+func (c *Copier) startIncremental(keyColumn string) error {
+	if !c.Incremental || keyColumn == "" {
+		if c.Incremental {
+			c.noticeOnce("incremental:"+c.TableName, fmt.Sprintf("Table %s has no key to keep a watermark on; reading all of its rows", c.TableName))
+		}
+		return nil
+	}
+	watermark, err := c.destWatermark(c.destColumn(keyColumn))
+	if err != nil {
+		return err
+	}
+	c.watermarkColumn, c.watermark = keyColumn, watermark
+	if watermark != nil {
+		c.printf("Reading rows of %s with %s above %v\n", c.TableName, keyColumn, watermark)
+	}
+	return nil
+}
+
+// watermarkRange restricts a read of the source table to the rows above the
+// watermark of an Incremental copy
+func (c *Copier) watermarkRange(query *gorm.DB) *gorm.DB {
+	if c.watermark == nil {
+		return query
+	}
+	return query.Where(fmt.Sprintf("%s > ?", quoteIdent(c.watermarkColumn)), c.watermark)
+}
+
+// printWatermark reports the watermark an Incremental copy leaves behind
+func (c *Copier) printWatermark() error {
+	if c.watermarkColumn == "" {
+		return nil
+	}
+	watermark, err := c.destWatermark(c.destColumn(c.watermarkColumn))
+	if err != nil {
+		return err
+	}
+	c.printf("Watermark of %s: %s = %v\n", c.destTableName(), c.destColumn(c.watermarkColumn), watermark)
+	return nil
+}
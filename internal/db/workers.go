@@ -0,0 +1,102 @@
+package db
+
+import (
+	"errors"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// insertBatchesConcurrently inserts batches into the destination table using
+// c.Workers goroutines. Each batch is committed on its own. A non-nil limiter
+// throttles how fast batches are handed to the workers. With
+// UniqueViolationSkipTable a unique violation stops handing out batches and
+// is returned with the number of rows left out; batches already in flight
+// are still written.
+func (c *Copier) insertBatchesConcurrently(batches [][]map[string]interface{}, limiter *rate.Limiter) (int, error) {
+	type job struct {
+		number int
+		batch  []map[string]interface{}
+	}
+	jobs := make(chan job)
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		firstErr  error
+		violation error
+		skipped   int
+	)
+
+	writer := c.newTableWriter(c.destConn, false)
+	defer writer.closeStatements()
+	for w := 0; w < c.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				written, err := c.writeBatch(writer, j.batch)
+
+				mu.Lock()
+				var uniqueErr *ErrUniqueViolation
+				if errors.As(err, &uniqueErr) {
+					if violation == nil {
+						violation = err
+					}
+					skipped += len(j.batch)
+					mu.Unlock()
+					continue
+				}
+				if err != nil && firstErr == nil {
+					firstErr = &ErrInsert{Table: c.destTableName(), Batch: j.number, Err: err}
+					var tooMany *ErrTooManyErrors
+					if errors.As(err, &tooMany) {
+						firstErr = err
+					}
+				}
+				if err == nil {
+					c.printBatch("Copied %d records (new records only)\n", written)
+					c.reportProgress(written)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for i, batch := range batches {
+		mu.Lock()
+		failed := firstErr != nil
+		if violation != nil {
+			for _, rest := range batches[i:] {
+				skipped += len(rest)
+			}
+			failed = true
+		}
+		mu.Unlock()
+		if failed {
+			break
+		}
+		if err := c.interrupted(); err != nil {
+			mu.Lock()
+			firstErr = err
+			mu.Unlock()
+			break
+		}
+		if err := c.throttle(limiter, len(batch)); err != nil {
+			mu.Lock()
+			firstErr = err
+			mu.Unlock()
+			break
+		}
+		jobs <- job{number: i + 1, batch: batch}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return 0, firstErr
+	}
+	if violation != nil {
+		return skipped, violation
+	}
+	return 0, nil
+}
@@ -0,0 +1,164 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// RowWriter writes batches of rows to a destination. The copier reads, filters
+// and batches the rows; a RowWriter only decides how a batch is stored, so a
+// new kind of destination needs nothing more than a RowWriter of its own.
+type RowWriter interface {
+	// WriteBatch stores a batch of rows keyed by destination column name
+	WriteBatch(rows []map[string]interface{}) error
+	// Close finishes writing, e.g. by committing a transaction
+	Close() error
+}
+
+// tableWriter inserts rows into a table of a SQL database with one of the
+// insert methods. When conn is a transaction, Close commits it.
+type tableWriter struct {
+	conn     *gorm.DB
+	table    string
+	tx       bool
+	method   string
+	dbType   DBType
+	ctx      context.Context
+	pinned   *sql.Conn
+	copyNull string
+
+	// mu guards the prepared statements and column types, which concurrent
+	// workers share
+	mu          sync.Mutex
+	stmts       map[string]*sql.Stmt
+	columnTypes map[string]string
+}
+
+// newTableWriter returns a RowWriter for the destination table that inserts
+// through conn. inTx tells whether conn is a transaction to commit on Close.
+func (c *Copier) newTableWriter(conn *gorm.DB, inTx bool) *tableWriter {
+	return &tableWriter{
+		conn:     conn,
+		table:    c.destTableName(),
+		tx:       inTx,
+		method:   c.insertMethod(),
+		dbType:   c.destDBType,
+		ctx:      c.copyContext(),
+		pinned:   c.pinnedDestConn(),
+		copyNull: c.CopyNull,
+	}
+}
+
+// WriteBatch inserts the rows by column name. Columns are listed in the order
+// of the sorted map keys, so the column order of the destination table, which
+// may differ from the source's for an existing table, does not matter.
+// Columns a row has no key for are left out of its INSERT, so their defaults
+// apply.
+func (w *tableWriter) WriteBatch(rows []map[string]interface{}) error {
+	for _, group := range groupByColumns(rows) {
+		var err error
+		switch w.method {
+		case InsertPrepared:
+			err = w.writePrepared(group)
+		case InsertCopy:
+			err = w.writeCopy(group)
+		default:
+			err = w.conn.Table(w.table).Create(&group).Error
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// groupByColumns splits a batch into runs of consecutive rows with the same
+// columns. GORM inserts the columns of every row of a batch and sends NULL
+// where a row has none, which would override the destination's default and
+// break NOT NULL columns. Rows read from one source table all have the same
+// columns, so a batch is normally a single group.
+func groupByColumns(rows []map[string]interface{}) [][]map[string]interface{} {
+	var groups [][]map[string]interface{}
+	for i, row := range rows {
+		if i == 0 || !sameColumns(row, rows[i-1]) {
+			groups = append(groups, nil)
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], row)
+	}
+	return groups
+}
+
+// sameColumns reports whether two rows have the same column names
+func sameColumns(a, b map[string]interface{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name := range a {
+		if _, ok := b[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (w *tableWriter) Close() error {
+	if !w.tx {
+		return nil
+	}
+	if err := w.conn.Commit().Error; err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// redisWriter stores each row as a Redis hash keyed prefix:key-value, sending
+// a batch as one pipeline. A key is replaced as a whole so that fields which
+// became NULL, and are therefore left out, do not linger.
+type redisWriter struct {
+	client    *redis.Client
+	ctx       context.Context
+	prefix    string
+	keyColumn string
+	ttl       time.Duration
+}
+
+// newRedisWriter returns a RowWriter for the destination table on Redis
+func (c *Copier) newRedisWriter(keyColumn string) *redisWriter {
+	return &redisWriter{
+		client:    c.redisConn,
+		ctx:       c.copyContext(),
+		prefix:    c.destTableName(),
+		keyColumn: keyColumn,
+		ttl:       c.RedisTTL,
+	}
+}
+
+func (w *redisWriter) WriteBatch(rows []map[string]interface{}) error {
+	pipe := w.client.Pipeline()
+	for _, row := range rows {
+		key := fmt.Sprintf("%s:%v", w.prefix, row[w.keyColumn])
+		fields := make(map[string]interface{}, len(row))
+		for name, value := range row {
+			if value != nil {
+				fields[name] = value
+			}
+		}
+		pipe.Del(w.ctx, key)
+		pipe.HSet(w.ctx, key, fields)
+		if w.ttl > 0 {
+			pipe.Expire(w.ctx, key, w.ttl)
+		}
+	}
+	_, err := pipe.Exec(w.ctx)
+	return err
+}
+
+func (w *redisWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,81 @@
+package db
+
+import (
+	"testing"
+)
+
+// user is a row of the users table of the writer tests
+type user struct {
+	ID    int
+	Name  string
+	Email string
+	Age   int
+}
+
+// usersSetup creates a users table of two rows
+var usersSetup = []string{
+	"CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, email TEXT, age INTEGER)",
+	"INSERT INTO users (id, name, email, age) VALUES (1, 'Ann', 'ann@example.com', 31), (2, 'Bob', 'bob@example.com', 47)",
+}
+
+// readUsers returns the users of the destination in id order
+func readUsers(t *testing.T, c *Copier) []user {
+	t.Helper()
+	var users []user
+	if err := c.destConn.Raw("SELECT id, name, email, age FROM users ORDER BY id").Scan(&users).Error; err != nil {
+		t.Fatalf("read users: %v", err)
+	}
+	return users
+}
+
+func TestCopyIntoReorderedColumns(t *testing.T) {
+	want := []user{{1, "Ann", "ann@example.com", 31}, {2, "Bob", "bob@example.com", 47}}
+	for _, method := range []string{InsertMultiValues, InsertPrepared} {
+		t.Run(method, func(t *testing.T) {
+			c := newTestCopier(t, "users", 100, usersSetup...)
+			c.InsertMethod = method
+			if err := c.destConn.Exec("CREATE TABLE users (age INTEGER, email TEXT, id INTEGER PRIMARY KEY, name TEXT)").Error; err != nil {
+				t.Fatalf("create destination: %v", err)
+			}
+			if err := c.Copy(); err != nil {
+				t.Fatalf("Copy: %v", err)
+			}
+			got := readUsers(t, c)
+			if len(got) != len(want) {
+				t.Fatalf("destination has %d rows, want %d", len(got), len(want))
+			}
+			for i := range want {
+				if got[i] != want[i] {
+					t.Errorf("row %d = %+v, want %+v", i+1, got[i], want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCopyIntoDestinationWithDefaultedColumn(t *testing.T) {
+	for _, method := range []string{InsertMultiValues, InsertPrepared} {
+		t.Run(method, func(t *testing.T) {
+			c := newTestCopier(t, "users", 100, usersSetup...)
+			c.InsertMethod = method
+			if err := c.destConn.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, email TEXT, age INTEGER, source TEXT NOT NULL DEFAULT 'import')").Error; err != nil {
+				t.Fatalf("create destination: %v", err)
+			}
+			if err := c.Copy(); err != nil {
+				t.Fatalf("Copy: %v", err)
+			}
+			if got := countRows(t, c.destConn, "users"); got != 2 {
+				t.Fatalf("destination has %d rows, want 2", got)
+			}
+			var sources []string
+			if err := c.destConn.Raw("SELECT source FROM users ORDER BY id").Scan(&sources).Error; err != nil {
+				t.Fatalf("read source column: %v", err)
+			}
+			for i, source := range sources {
+				if source != "import" {
+					t.Errorf("row %d has source %q, want the default %q", i+1, source, "import")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,408 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"db-copy/internal/db"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxLogLines is the number of copy log lines kept on screen
+const maxLogLines = 8
+
+type screen int
+
+const (
+	screenTables screen = iota
+	screenColumns
+	screenBatchSize
+	screenCopy
+)
+
+// table is a source table and the user's selection for it
+type table struct {
+	name     string
+	selected bool
+	columns  []db.Column
+	included map[string]bool
+}
+
+// selectedColumns returns the included columns, or nil when all of them are
+func (t *table) selectedColumns() []string {
+	if t.columns == nil {
+		return nil
+	}
+	var names []string
+	for _, col := range t.columns {
+		if t.included[col.Name] {
+			names = append(names, col.Name)
+		}
+	}
+	if len(names) == len(t.columns) {
+		return nil
+	}
+	return names
+}
+
+type progressMsg struct {
+	table string
+	rows  int
+}
+
+type logMsg string
+
+type doneMsg struct {
+	err error
+}
+
+// logWriter forwards each line written by the copier to the program
+type logWriter struct {
+	send func(tea.Msg)
+}
+
+func (w logWriter) Write(p []byte) (int, error) {
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		w.send(logMsg(line))
+	}
+	return len(p), nil
+}
+
+type model struct {
+	copier     *db.Copier
+	ctx        context.Context
+	cancel     context.CancelFunc
+	screen     screen
+	tables     []*table
+	cursor     int
+	colCursor  int
+	height     int
+	batchInput string
+	message    string
+
+	copying    []string
+	progress   map[string]int
+	logLines   []string
+	cancelling bool
+	done       bool
+	copyErr    error
+}
+
+// Run lists the source tables of a connected copier and lets the user pick
+// tables, columns and the batch size before copying them with a live
+// progress view. Cancelling ctx, or pressing ctrl+c while copying, stops the
+// copy and rolls back the table being copied.
+func Run(ctx context.Context, copier *db.Copier) error {
+	names, err := copier.ListTables()
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("no tables found in source database")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	m := &model{
+		copier:   copier,
+		ctx:      ctx,
+		cancel:   cancel,
+		progress: make(map[string]int),
+		height:   20,
+	}
+	for _, name := range names {
+		m.tables = append(m.tables, &table{name: name})
+	}
+
+	p := tea.NewProgram(m, tea.WithAltScreen())
+	copier.Output = logWriter{send: p.Send}
+	copier.OnProgress = func(table string, rows int) {
+		p.Send(progressMsg{table: table, rows: rows})
+	}
+
+	final, err := p.Run()
+	copier.Output = nil
+	copier.OnProgress = nil
+	if err != nil {
+		return fmt.Errorf("interactive mode failed: %w", err)
+	}
+	result := final.(*model)
+	if result.cancelling && result.copyErr != nil {
+		return fmt.Errorf("interrupted by user: %w", result.copyErr)
+	}
+	return result.copyErr
+}
+
+func (m *model) Init() tea.Cmd {
+	return nil
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		if msg.Height > 0 {
+			m.height = msg.Height
+		}
+		return m, nil
+	case progressMsg:
+		m.progress[msg.table] += msg.rows
+		return m, nil
+	case logMsg:
+		m.logLines = append(m.logLines, string(msg))
+		if len(m.logLines) > maxLogLines {
+			m.logLines = m.logLines[len(m.logLines)-maxLogLines:]
+		}
+		return m, nil
+	case doneMsg:
+		m.done = true
+		m.copyErr = msg.err
+		if m.cancelling {
+			return m, tea.Quit
+		}
+		return m, nil
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			// A running copy is cancelled first, and the program quits once
+			// it has rolled back
+			if m.screen == screenCopy && !m.done {
+				m.cancelling = true
+				m.cancel()
+				return m, nil
+			}
+			return m, tea.Quit
+		}
+		switch m.screen {
+		case screenTables:
+			return m.updateTables(msg)
+		case screenColumns:
+			return m.updateColumns(msg)
+		case screenBatchSize:
+			return m.updateBatchSize(msg)
+		case screenCopy:
+			if m.done {
+				return m, tea.Quit
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m *model) updateTables(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.message = ""
+	switch msg.String() {
+	case "q", "esc":
+		return m, tea.Quit
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.tables)-1 {
+			m.cursor++
+		}
+	case " ", "x":
+		m.tables[m.cursor].selected = !m.tables[m.cursor].selected
+	case "a":
+		all := true
+		for _, t := range m.tables {
+			all = all && t.selected
+		}
+		for _, t := range m.tables {
+			t.selected = !all
+		}
+	case "right", "l", "c":
+		t := m.tables[m.cursor]
+		if t.columns == nil {
+			columns, err := m.copier.TableColumns(t.name)
+			if err != nil {
+				m.message = fmt.Sprintf("Failed to read columns of %s: %v", t.name, err)
+				return m, nil
+			}
+			t.columns = columns
+			t.included = make(map[string]bool)
+			for _, col := range columns {
+				t.included[col.Name] = true
+			}
+		}
+		m.colCursor = 0
+		m.screen = screenColumns
+	case "b":
+		m.batchInput = strconv.Itoa(m.copier.BatchSize)
+		m.screen = screenBatchSize
+	case "enter":
+		return m.startCopy()
+	}
+	return m, nil
+}
+
+func (m *model) updateColumns(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	t := m.tables[m.cursor]
+	switch msg.String() {
+	case "q", "esc", "left", "h", "enter":
+		m.screen = screenTables
+	case "up", "k":
+		if m.colCursor > 0 {
+			m.colCursor--
+		}
+	case "down", "j":
+		if m.colCursor < len(t.columns)-1 {
+			m.colCursor++
+		}
+	case " ", "x":
+		if len(t.columns) > 0 {
+			name := t.columns[m.colCursor].Name
+			t.included[name] = !t.included[name]
+			// Choosing columns implies copying the table
+			t.selected = true
+		}
+	}
+	return m, nil
+}
+
+func (m *model) updateBatchSize(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.screen = screenTables
+	case "enter":
+		size, err := strconv.Atoi(m.batchInput)
+		if err != nil || size <= 0 {
+			m.message = "Batch size must be a positive number"
+			return m, nil
+		}
+		m.copier.BatchSize = size
+		m.message = ""
+		m.screen = screenTables
+	case "backspace":
+		if m.batchInput != "" {
+			m.batchInput = m.batchInput[:len(m.batchInput)-1]
+		}
+	default:
+		if msg.Type == tea.KeyRunes {
+			for _, r := range msg.Runes {
+				if r >= '0' && r <= '9' {
+					m.batchInput += string(r)
+				}
+			}
+		}
+	}
+	return m, nil
+}
+
+// startCopy validates the selection and runs the copy in the background
+func (m *model) startCopy() (tea.Model, tea.Cmd) {
+	columns := make(map[string][]string)
+	var tables []string
+	for _, t := range m.tables {
+		if !t.selected {
+			continue
+		}
+		selected := t.selectedColumns()
+		if selected != nil && len(selected) == 0 {
+			m.message = fmt.Sprintf("No columns selected for %s", t.name)
+			return m, nil
+		}
+		if selected != nil {
+			columns[t.name] = selected
+		}
+		tables = append(tables, t.name)
+	}
+	if len(tables) == 0 {
+		m.message = "Select at least one table to copy"
+		return m, nil
+	}
+
+	m.copier.Columns = columns
+	m.copying = tables
+	m.screen = screenCopy
+	copier, ctx := m.copier, m.ctx
+	return m, func() tea.Msg {
+		return doneMsg{err: copier.CopyTablesContext(ctx, tables)}
+	}
+}
+
+func (m *model) View() string {
+	var b strings.Builder
+	switch m.screen {
+	case screenTables:
+		b.WriteString(fmt.Sprintf("Select tables to copy (batch size %d)\n\n", m.copier.BatchSize))
+		start, end := window(m.cursor, len(m.tables), m.height-6)
+		for i := start; i < end; i++ {
+			t := m.tables[i]
+			note := ""
+			if selected := t.selectedColumns(); selected != nil {
+				note = fmt.Sprintf("  (%d of %d columns)", len(selected), len(t.columns))
+			}
+			b.WriteString(fmt.Sprintf("%s %s %s%s\n", cursor(i == m.cursor), checkbox(t.selected), t.name, note))
+		}
+		b.WriteString("\nspace: toggle  a: toggle all  c: columns  b: batch size  enter: copy  q: quit\n")
+	case screenColumns:
+		t := m.tables[m.cursor]
+		b.WriteString(fmt.Sprintf("Columns of %s\n\n", t.name))
+		start, end := window(m.colCursor, len(t.columns), m.height-6)
+		for i := start; i < end; i++ {
+			col := t.columns[i]
+			b.WriteString(fmt.Sprintf("%s %s %s %s\n", cursor(i == m.colCursor), checkbox(t.included[col.Name]), col.Name, col.Type))
+		}
+		b.WriteString("\nspace: toggle  esc: back\n")
+	case screenBatchSize:
+		b.WriteString(fmt.Sprintf("Batch size: %s_\n\nenter: confirm  esc: cancel\n", m.batchInput))
+	case screenCopy:
+		for _, name := range m.copying {
+			b.WriteString(fmt.Sprintf("%-30s %d rows\n", name, m.progress[name]))
+		}
+		b.WriteString("\n")
+		for _, line := range m.logLines {
+			b.WriteString(line + "\n")
+		}
+		b.WriteString("\n")
+		switch {
+		case m.cancelling && !m.done:
+			b.WriteString("Cancelling...\n")
+		case !m.done:
+			b.WriteString("Copying...  ctrl+c: cancel\n")
+		case m.copyErr != nil:
+			b.WriteString(fmt.Sprintf("Copy failed: %v\n\nPress any key to exit\n", m.copyErr))
+		default:
+			b.WriteString("Copy complete\n\nPress any key to exit\n")
+		}
+	}
+	if m.message != "" {
+		b.WriteString("\n" + m.message + "\n")
+	}
+	return b.String()
+}
+
+// window returns the range of list items to show so that the cursor stays visible
+func window(cursor, count, height int) (int, int) {
+	if height < 1 {
+		height = 1
+	}
+	if count <= height {
+		return 0, count
+	}
+	start := cursor - height/2
+	if start < 0 {
+		start = 0
+	}
+	if start+height > count {
+		start = count - height
+	}
+	return start, start + height
+}
+
+func cursor(active bool) string {
+	if active {
+		return ">"
+	}
+	return " "
+}
+
+func checkbox(checked bool) string {
+	if checked {
+		return "[x]"
+	}
+	return "[ ]"
+}